@@ -11,6 +11,9 @@ import (
 // variables.
 type Config struct {
 	App struct {
+		Accounts struct {
+			ExpiryIntervalSeconds int64 `mapstructure:"EXPIRY_INTERVAL_SECONDS"`
+		}
 		CORS struct {
 			AllowCredentials bool     `mapstructure:"ALLOW_CREDENTIALS"`
 			AllowedHeaders   []string `mapstructure:"ALLOWED_HEADERS"`
@@ -19,10 +22,57 @@ type Config struct {
 			Enable           bool     `mapstructure:"ENABLE"`
 			MaxAgeSeconds    int      `mapstructure:"MAX_AGE_SECONDS"`
 		}
+		Documents struct {
+			MaxSizeBytes int64         `mapstructure:"MAX_SIZE_BYTES"`
+			Storage      StorageConfig `mapstructure:"STORAGE"`
+		}
 		Name         string `mapstructure:"NAME"`
 		Revision     string `mapstructure:"REVISION"`
 		URL          string `mapstructure:"URL"`
 		JWTAccessKey string `mapstructure:"JWT_ACCESS_KEY"`
+		JWT          struct {
+			AccessTokenExpirySeconds int64  `mapstructure:"ACCESS_TOKEN_EXPIRY_SECONDS"`
+			ActiveKeyID              string `mapstructure:"ACTIVE_KEY_ID"`
+			Audience                 string `mapstructure:"AUDIENCE"`
+			ClockSkewSeconds         int64  `mapstructure:"CLOCK_SKEW_SECONDS"`
+			Issuer                   string `mapstructure:"ISSUER"`
+			KeysDir                  string `mapstructure:"KEYS_DIR"`
+		}
+		Mail struct {
+			Dir    string `mapstructure:"DIR"`
+			Driver string `mapstructure:"DRIVER"`
+			From   string `mapstructure:"FROM"`
+			SMTP   struct {
+				Host     string `mapstructure:"HOST"`
+				Password string `mapstructure:"PASSWORD"`
+				Port     string `mapstructure:"PORT"`
+				Username string `mapstructure:"USERNAME"`
+			}
+		}
+		MFA struct {
+			ChallengeExpirySeconds int64  `mapstructure:"CHALLENGE_EXPIRY_SECONDS"`
+			Issuer                 string `mapstructure:"ISSUER"`
+			LockoutSeconds         int64  `mapstructure:"LOCKOUT_SECONDS"`
+			MaxFailedAttempts      int    `mapstructure:"MAX_FAILED_ATTEMPTS"`
+			RequireForAdmin        bool   `mapstructure:"REQUIRE_FOR_ADMIN"`
+		}
+		OAuth struct {
+			AccessTokenExpirySeconds  int64 `mapstructure:"ACCESS_TOKEN_EXPIRY_SECONDS"`
+			CleanupIntervalSeconds    int64 `mapstructure:"CLEANUP_INTERVAL_SECONDS"`
+			RefreshTokenExpirySeconds int64 `mapstructure:"REFRESH_TOKEN_EXPIRY_SECONDS"`
+		}
+		Phones struct {
+			DefaultRegion string `mapstructure:"DEFAULT_REGION"`
+			Unique        bool   `mapstructure:"UNIQUE"`
+		}
+		Photos struct {
+			MaxSizeBytes int64 `mapstructure:"MAX_SIZE_BYTES"`
+		}
+		Signup struct {
+			VerificationExpirySeconds int64  `mapstructure:"VERIFICATION_EXPIRY_SECONDS"`
+			VerificationURL           string `mapstructure:"VERIFICATION_URL"`
+		}
+		Storage StorageConfig `mapstructure:"STORAGE"`
 	}
 
 	Cache struct {
@@ -94,13 +144,72 @@ type Config struct {
 	}
 
 	Server struct {
-		Env      string `mapstructure:"ENV"`
-		LogLevel string `mapstructure:"LOG_LEVEL"`
-		Port     string `mapstructure:"PORT"`
+		Env       string `mapstructure:"ENV"`
+		LogFormat string `mapstructure:"LOG_FORMAT"`
+		LogLevel  string `mapstructure:"LOG_LEVEL"`
+		Port      string `mapstructure:"PORT"`
+		Health    struct {
+			CacheMillis   int64 `mapstructure:"CACHE_MILLIS"`
+			TimeoutMillis int64 `mapstructure:"TIMEOUT_MILLIS"`
+		}
+		Metrics struct {
+			Enable bool   `mapstructure:"ENABLE"`
+			Path   string `mapstructure:"PATH"`
+		}
+		Tracing struct {
+			Enable      bool    `mapstructure:"ENABLE"`
+			Endpoint    string  `mapstructure:"ENDPOINT"`
+			Exporter    string  `mapstructure:"EXPORTER"`
+			Insecure    bool    `mapstructure:"INSECURE"`
+			SampleRatio float64 `mapstructure:"SAMPLE_RATIO"`
+		}
+		RateLimit struct {
+			Backend    string          `mapstructure:"BACKEND"`
+			Default    RateLimitPolicy `mapstructure:"DEFAULT"`
+			Enable     bool            `mapstructure:"ENABLE"`
+			Login      RateLimitPolicy `mapstructure:"LOGIN"`
+			Register   RateLimitPolicy `mapstructure:"REGISTER"`
+			TrustProxy bool            `mapstructure:"TRUST_PROXY"`
+		} `mapstructure:"RATE_LIMIT"`
 		Shutdown struct {
 			CleanupPeriodSeconds int64 `mapstructure:"CLEANUP_PERIOD_SECONDS"`
 			GracePeriodSeconds   int64 `mapstructure:"GRACE_PERIOD_SECONDS"`
 		}
+		Timeout struct {
+			IdleSeconds       int64 `mapstructure:"IDLE_SECONDS"`
+			ReadHeaderSeconds int64 `mapstructure:"READ_HEADER_SECONDS"`
+			ReadSeconds       int64 `mapstructure:"READ_SECONDS"`
+			WriteSeconds      int64 `mapstructure:"WRITE_SECONDS"`
+		}
+	}
+}
+
+// RateLimitPolicy configures the rate limit applied to a group of routes.
+// KeyBy selects what requests are counted by: "ip", "user" (falling back to
+// the IP for anonymous requests) or "route" (shared by all clients).
+type RateLimitPolicy struct {
+	Burst         int    `mapstructure:"BURST"`
+	KeyBy         string `mapstructure:"KEY_BY"`
+	PeriodSeconds int64  `mapstructure:"PERIOD_SECONDS"`
+	Requests      int    `mapstructure:"REQUESTS"`
+}
+
+// StorageConfig configures an object storage. Driver selects "local", which
+// keeps objects in Local.Dir, or "s3", which keeps them in an S3 compatible
+// bucket.
+type StorageConfig struct {
+	Driver string `mapstructure:"DRIVER"`
+	Local  struct {
+		BaseURL string `mapstructure:"BASE_URL"`
+		Dir     string `mapstructure:"DIR"`
+	}
+	S3 struct {
+		AccessKeyID     string `mapstructure:"ACCESS_KEY_ID"`
+		Bucket          string `mapstructure:"BUCKET"`
+		Endpoint        string `mapstructure:"ENDPOINT"`
+		PublicURL       string `mapstructure:"PUBLIC_URL"`
+		Region          string `mapstructure:"REGION"`
+		SecretAccessKey string `mapstructure:"SECRET_ACCESS_KEY"`
 	}
 }
 
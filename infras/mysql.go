@@ -7,11 +7,14 @@ import (
 
 	"github.com/evermos/boilerplate-go/shared/failure"
 
+	"github.com/XSAM/otelsql"
 	"github.com/evermos/boilerplate-go/configs"
 	// use MySQL driver
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
 const (
@@ -62,7 +65,8 @@ func CreateMySQLReadConn(config configs.Config) *sqlx.DB {
 
 }
 
-// CreateDBConnection creates a database connection.
+// CreateDBConnection creates a database connection. Every statement executed
+// on the connection is traced as a span of the caller's context.
 func CreateDBConnection(name, username, password, host, port, dbName, timeZone string) *sqlx.DB {
 	descriptor := fmt.Sprintf(
 		"%s:%s@tcp(%s:%s)/%s?charset=utf8&loc=%s&parseTime=true",
@@ -72,7 +76,7 @@ func CreateDBConnection(name, username, password, host, port, dbName, timeZone s
 		port,
 		dbName,
 		url.QueryEscape(timeZone))
-	db, err := sqlx.Connect("mysql", descriptor)
+	db, err := connectTraced(name, dbName, descriptor)
 	if err != nil {
 		log.
 			Fatal().
@@ -97,6 +101,31 @@ func CreateDBConnection(name, username, password, host, port, dbName, timeZone s
 	return db
 }
 
+func connectTraced(name, dbName, descriptor string) (*sqlx.DB, error) {
+	driverName, err := otelsql.Register(
+		"mysql",
+		semconv.DBSystemMySQL.Value.AsString(),
+		otelsql.WithAttributes(
+			semconv.DBNameKey.String(dbName),
+			attribute.String("db.connection", name),
+		))
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open(driverName, descriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlx.NewDb(conn, "mysql")
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
 // OpenMock opens a database connection for mocking purposes.
 func OpenMock(db *sql.DB) *MySQLConn {
 	conn := sqlx.NewDb(db, "mysql")
@@ -106,6 +135,19 @@ func OpenMock(db *sql.DB) *MySQLConn {
 	}
 }
 
+// Close closes the write and read connections.
+func (m *MySQLConn) Close() error {
+	writeErr := m.Write.Close()
+	if m.Read == m.Write {
+		return writeErr
+	}
+	readErr := m.Read.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+	return readErr
+}
+
 // WithTransaction performs queries with transaction
 func (m *MySQLConn) WithTransaction(block Block) (err error) {
 	e := make(chan error)
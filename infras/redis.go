@@ -1,12 +1,28 @@
 package infras
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/tracing"
 	"github.com/go-redis/redis"
+	"github.com/rs/zerolog/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ProvideRedisClient is the provider for the primary Redis client. It returns
+// nil when no Redis host is configured.
+func ProvideRedisClient(config *configs.Config) *redis.Client {
+	if config.Cache.Redis.Primary.Host == "" {
+		log.Info().Msg("Redis is not configured.")
+		return nil
+	}
+	return RedisNewClient(*config)
+}
+
 //RedisNewClient create new instance of redis
 func RedisNewClient(config configs.Config) *redis.Client {
 	client := redis.NewClient(&redis.Options{
@@ -22,3 +38,49 @@ func RedisNewClient(config configs.Config) *redis.Client {
 
 	return client
 }
+
+// RedisWithTracing returns a copy of client bound to ctx whose commands are
+// traced as child spans of the span in ctx.
+func RedisWithTracing(ctx context.Context, client *redis.Client) *redis.Client {
+	traced := client.WithContext(ctx)
+	traced.WrapProcess(func(process func(cmd redis.Cmder) error) func(cmd redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			_, span := tracing.StartSpan(ctx, "redis "+cmd.Name(),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemRedis,
+					semconv.DBOperationKey.String(cmd.Name()),
+				))
+			defer span.End()
+
+			err := process(cmd)
+			if err != redis.Nil {
+				tracing.RecordError(span, err)
+			}
+			return err
+		}
+	})
+	traced.WrapProcessPipeline(func(process func(cmds []redis.Cmder) error) func(cmds []redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			names := make([]string, 0, len(cmds))
+			for _, cmd := range cmds {
+				names = append(names, cmd.Name())
+			}
+
+			_, span := tracing.StartSpan(ctx, "redis pipeline",
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemRedis,
+					semconv.DBOperationKey.String(strings.Join(names, " ")),
+				))
+			defer span.End()
+
+			err := process(cmds)
+			if err != redis.Nil {
+				tracing.RecordError(span, err)
+			}
+			return err
+		}
+	})
+	return traced
+}
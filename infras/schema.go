@@ -0,0 +1,39 @@
+package infras
+
+import (
+	"context"
+)
+
+// SchemaVersion is the database schema version this build expects. Bump it
+// together with the migration that brings the schema to the new version.
+const SchemaVersion = 20
+
+const querySelectSchemaVersions = "SELECT version FROM ums_schema_migrations WHERE version BETWEEN 1 AND ?"
+
+// MissingSchemaVersions returns the schema versions up to version that no
+// migration has recorded yet. Some versions are recorded by migrations run
+// by hand, so a later version being recorded does not mean they were applied.
+func (m *MySQLConn) MissingSchemaVersions(ctx context.Context, version int) ([]int, error) {
+	var recorded []int
+	err := m.Read.SelectContext(ctx, &recorded, querySelectSchemaVersions, version)
+	if err != nil {
+		return nil, err
+	}
+	return missingVersions(recorded, version), nil
+}
+
+// missingVersions returns the versions from 1 to version not in recorded.
+func missingVersions(recorded []int, version int) []int {
+	seen := make(map[int]bool, len(recorded))
+	for _, v := range recorded {
+		seen[v] = true
+	}
+
+	missing := []int{}
+	for v := 1; v <= version; v++ {
+		if !seen[v] {
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,13 @@
+package infras
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingVersions(t *testing.T) {
+	assert.Empty(t, missingVersions([]int{1, 2, 3}, 3))
+	assert.Equal(t, []int{2, 4}, missingVersions([]int{1, 3, 5}, 5))
+	assert.Equal(t, []int{1, 2}, missingVersions(nil, 2))
+}
@@ -3,30 +3,167 @@ package auth
 import (
 	"errors"
 	"time"
+
+	"github.com/evermos/boilerplate-go/shared/civil"
 )
 
 var (
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrNotFound     = errors.New("not found")
 	ErrUserExist    = errors.New("username exist")
+	ErrEmailExist   = errors.New("email exist")
+
+	ErrAccountInactive          = errors.New("account is not active")
+	ErrAccountStatusConflict    = errors.New("account status does not allow this change")
+	ErrValidUntilPassed         = errors.New("valid until date has passed")
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	ErrNotPendingApproval       = errors.New("user is not pending approval")
+
+	ErrMFAInvalidCode      = errors.New("invalid mfa code")
+	ErrMFANotEnrolled      = errors.New("mfa not enrolled")
+	ErrMFAAlreadyEnabled   = errors.New("mfa already enabled")
+	ErrMFAInvalidChallenge = errors.New("invalid mfa challenge")
+	ErrMFALocked           = errors.New("mfa locked after too many failed attempts")
+
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// Account statuses of users. Only active users can log in.
+const (
+	AccountStatusPendingVerification = "pending_verification"
+	AccountStatusPendingApproval     = "pending_approval"
+	AccountStatusActive              = "active"
+	AccountStatusRejected            = "rejected"
+	AccountStatusSuspended           = "suspended"
+	AccountStatusExpired             = "expired"
 )
 
 type Access struct {
-	ID       string `db:"id" json:"id"`
-	Username string `db:"username" json:"username"`
-	Password string `db:"password" json:"password"`
-	Role     string `db:"role" json:"role"`
+	ID            string  `db:"id" json:"id"`
+	Username      string  `db:"username" json:"username"`
+	Email         *string `db:"email" json:"email"`
+	Password      string  `db:"password" json:"password"`
+	Role          string  `db:"role" json:"role"`
+	AccountStatus string  `db:"account_status" json:"accountStatus"`
+	ValidUntil    *string `db:"valid_until" json:"validUntil"`
+}
+
+// IsActive reports whether the account can log in at now: it is active and
+// its valid until date, if any, has not passed yet.
+func (a *Access) IsActive(now time.Time) bool {
+	if a.AccountStatus != AccountStatusActive {
+		return false
+	}
+	return a.ValidUntil == nil || *a.ValidUntil >= now.Format(civil.Layout)
 }
 
 type User struct {
+	ID            string    `db:"id" json:"id"`
+	ProfileID     string    `db:"profile_id"`
+	StatusID      string    `db:"status_id"`
+	Username      string    `db:"username" json:"username"`
+	Email         *string   `db:"email" json:"email"`
+	Password      string    `db:"password" json:"password"`
+	Role          string    `db:"role" json:"role"`
+	AccountStatus string    `db:"account_status" json:"accountStatus"`
+	ValidUntil    *string   `db:"valid_until" json:"validUntil"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	CreatedBy     string    `db:"created_by" json:"created_by"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	UpdatedBy     string    `db:"updated_by" json:"updated_by"`
+}
+
+// Signup is a request to create an account through the public sign-up.
+type Signup struct {
+	Username string `json:"username" validate:"required,max=255"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+}
+
+// EmailVerification is a token sent to a user to prove they own an email
+// address. Only the SHA-256 digest of the token is stored.
+type EmailVerification struct {
+	TokenHash string    `db:"token_hash"`
+	UserID    string    `db:"user_id"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// PendingUser is a signed-up user awaiting the approval of an admin.
+type PendingUser struct {
 	ID        string    `db:"id" json:"id"`
-	ProfileID string    `db:"profile_id"`
-	StatusID  string    `db:"status_id"`
 	Username  string    `db:"username" json:"username"`
-	Password  string    `db:"password" json:"password"`
-	Role      string    `db:"role" json:"role"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	CreatedBy string    `db:"created_by" json:"created_by"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-	UpdatedBy string    `db:"updated_by" json:"updated_by"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// MFA is the TOTP enrollment of a user. The secret is only usable once the
+// enrollment has been confirmed with a code, which enables it.
+type MFA struct {
+	UserID       string `db:"user_id"`
+	Secret       string `db:"secret"`
+	Enabled      bool   `db:"enabled"`
+	LastUsedStep int64  `db:"last_used_step"`
+	// FailedAttempts counts the wrong codes since the last successful
+	// verification or lock.
+	FailedAttempts int        `db:"failed_attempts"`
+	LockedUntil    *time.Time `db:"locked_until"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+// IsLocked reports whether verifying codes is locked at now after too many
+// failed attempts.
+func (m *MFA) IsLocked(now time.Time) bool {
+	return m.LockedUntil != nil && now.Before(*m.LockedUntil)
+}
+
+// MFAEnrollment is the secret to add to an authenticator app, as a secret
+// to type in and as an otpauth URI to show as a QR code.
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// LoginResult is the outcome of a password login. Users with MFA enabled get
+// a ChallengeToken to exchange for a Token with a code. Admins who must
+// enroll in MFA get a Token that only allows enrolling.
+type LoginResult struct {
+	Token                 string `json:"token,omitempty"`
+	MFARequired           bool   `json:"mfaRequired,omitempty"`
+	ChallengeToken        string `json:"challengeToken,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfaEnrollmentRequired,omitempty"`
+}
+
+// Device is the client a user logs in from, recorded with the session.
+type Device struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Session is a login of a user on a device. Tokens carry the ID of their
+// session and are rejected once it is revoked.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"userId"`
+	IPAddress  string     `db:"ip_address" json:"ipAddress"`
+	UserAgent  string     `db:"user_agent" json:"userAgent"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"lastSeenAt"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expiresAt"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"-"`
+	// Current is whether the session is the one of the request listing it.
+	Current bool `db:"-" json:"current"`
+}
+
+// AccountStatusChange is a recorded change of the account status of a user.
+type AccountStatusChange struct {
+	ID         string    `db:"id" json:"id"`
+	UserID     string    `db:"user_id" json:"userId"`
+	FromStatus string    `db:"from_status" json:"fromStatus"`
+	ToStatus   string    `db:"to_status" json:"toStatus"`
+	Reason     string    `db:"reason" json:"reason"`
+	CreatedAt  time.Time `db:"created_at" json:"createdAt"`
+	CreatedBy  string    `db:"created_by" json:"createdBy"`
 }
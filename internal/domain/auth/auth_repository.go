@@ -1,22 +1,58 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"strings"
 	"time"
 
 	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/tracing"
 	"github.com/google/uuid"
-	"github.com/rs/zerolog/log"
+	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthRepository interface {
-	Register(user *User) error
-	GetUserByUsername(username string) (*Access, error)
-	IsExist(username string) (bool, error)
+	Register(ctx context.Context, user *User) error
+	GetUserByUsername(ctx context.Context, username string) (*Access, error)
+	IsExist(ctx context.Context, username string) (bool, error)
+	IsEmailExist(ctx context.Context, email string) (bool, error)
+	GetUserByID(ctx context.Context, id string) (*Access, error)
+	GetUserByEmail(ctx context.Context, email string) (*Access, error)
+	ChangeAccountStatus(ctx context.Context, change *AccountStatusChange, from ...string) error
+	ExpireAccounts(ctx context.Context, today string, batchSize int) (int64, error)
+	SetValidUntil(ctx context.Context, userID string, validUntil *string, updatedBy string) error
+	ListPendingUsers(ctx context.Context) ([]PendingUser, error)
+	CreateEmailVerification(ctx context.Context, verification *EmailVerification) error
+	UseEmailVerification(ctx context.Context, tokenHash string) (*EmailVerification, error)
+	GetMFA(ctx context.Context, userID string) (*MFA, error)
+	SaveMFASecret(ctx context.Context, userID, secret string) error
+	EnableMFA(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error
+	UseMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	RecordMFAFailure(ctx context.Context, userID string, maxAttempts int, lockedUntil time.Time) error
+	ResetMFAFailures(ctx context.Context, userID string) error
+	UseMFAChallenge(ctx context.Context, challengeID, userID string, expiresAt time.Time) (bool, error)
+	CreateSession(ctx context.Context, session *Session) error
+	ListSessions(ctx context.Context, userID string) ([]Session, error)
+	TouchSession(ctx context.Context, userID, sessionID string) (bool, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) (bool, error)
+	RevokeSessions(ctx context.Context, userID string) (int64, error)
 }
 
+// accessColumns are the columns of ums_users selected into an Access.
+const accessColumns = "id, username, email, password, role, account_status, DATE_FORMAT(valid_until, '%Y-%m-%d') AS valid_until"
+
+// mfaChallengeRetention is how long used MFA challenges are kept after they
+// expire, covering the leeway of the expiry check.
+const mfaChallengeRetention = time.Hour
+
+// sessionTouchInterval is how often the last use of a session is recorded.
+const sessionTouchInterval = time.Minute
+
 type AuthRepositoryMySQL struct {
 	DB *infras.MySQLConn
 }
@@ -27,39 +63,52 @@ func ProvideAuthRepositoryMySQL(db *infras.MySQLConn) *AuthRepositoryMySQL {
 	}
 }
 
-func (r *AuthRepositoryMySQL) GetUserByUsername(username string) (*Access, error) {
-	query := "SELECT id, username, password, role FROM ums_users WHERE username = ? LIMIT 1"
+func (r *AuthRepositoryMySQL) GetUserByUsername(ctx context.Context, username string) (*Access, error) {
+	query := "SELECT " + accessColumns + " FROM ums_users WHERE username = ? LIMIT 1"
 
 	var access Access
-	err := r.DB.Read.Get(&access, query, username)
+	err := r.DB.Read.GetContext(ctx, &access, query, username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			log.Error().Err(err).Msg("No user found")
+			logger.FromContext(ctx).Error().Err(err).Msg("No user found")
 			return nil, err
 		}
-		log.Error().Err(err).Msg("Failed to get user by username")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get user by username")
 		return nil, err
 	}
 	return &access, nil
 }
 
-func (r *AuthRepositoryMySQL) IsExist(username string) (bool, error) {
+func (r *AuthRepositoryMySQL) IsExist(ctx context.Context, username string) (bool, error) {
 	query := "SELECT EXISTS(SELECT username FROM ums_users WHERE username = ? LIMIT 1)"
 
 	var exists bool
-	err := r.DB.Read.Get(&exists, query, username)
+	err := r.DB.Read.GetContext(ctx, &exists, query, username)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check user existence")
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *AuthRepositoryMySQL) IsEmailExist(ctx context.Context, email string) (bool, error) {
+	query := "SELECT EXISTS(SELECT email FROM ums_users WHERE email = ? LIMIT 1)"
+
+	var exists bool
+	err := r.DB.Read.GetContext(ctx, &exists, query, email)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to check user existence")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check email existence")
 		return false, err
 	}
 
 	return exists, nil
 }
 
-func (r *AuthRepositoryMySQL) Register(user *User) error {
-	tx, err := r.DB.Write.Begin()
+func (r *AuthRepositoryMySQL) Register(ctx context.Context, user *User) error {
+	tx, err := r.DB.Write.BeginTx(ctx, nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to start transaction")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
 		return err
 	}
 	defer tx.Rollback()
@@ -67,18 +116,24 @@ func (r *AuthRepositoryMySQL) Register(user *User) error {
 	user.ID = uuid.New().String()
 	user.ProfileID = uuid.New().String()
 	user.StatusID = uuid.New().String()
+	_, hashSpan := tracing.StartSpan(ctx, "bcrypt.GenerateFromPassword")
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashSpan.End()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to encrypt password")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to encrypt password")
 		return err
 	}
 	user.Password = string(hashedPassword)
 	user.Role = strings.ToLower(user.Role)
+	if user.AccountStatus == "" {
+		user.AccountStatus = AccountStatusActive
+	}
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
 	profileQuery := "INSERT INTO ums_profiles (id, created_at, created_by, updated_at, updated_by) VALUES (?,?,?,?,?)"
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(
+		ctx,
 		profileQuery,
 		user.ProfileID,
 		user.CreatedAt,
@@ -87,12 +142,20 @@ func (r *AuthRepositoryMySQL) Register(user *User) error {
 		user.UpdatedBy,
 	)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to insert profile into db")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert profile into db")
+		return err
+	}
+
+	profileVersionQuery := "INSERT INTO ums_profile_versions (profile_id, version, created_at, created_by) VALUES (?, 1, ?, ?)"
+	_, err = tx.ExecContext(ctx, profileVersionQuery, user.ProfileID, user.CreatedAt, user.CreatedBy)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert profile version into db")
 		return err
 	}
 
 	statusQuery := "INSERT INTO ums_status (id, created_at, created_by, updated_at, updated_by) VALUES (?,?,?,?,?)"
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(
+		ctx,
 		statusQuery,
 		user.StatusID,
 		user.CreatedAt,
@@ -101,39 +164,601 @@ func (r *AuthRepositoryMySQL) Register(user *User) error {
 		user.UpdatedBy,
 	)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to insert status into db")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert status into db")
 		return err
 	}
 
 	userQuery :=
 		`
-	INSERT INTO ums_users (id, profile_id, status_id, username, password, role, created_at, created_by, updated_at, updated_by) 
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO ums_users (id, profile_id, status_id, username, email, password, role, account_status, valid_until, created_at, created_by, updated_at, updated_by) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(
+		ctx,
 		userQuery,
 		user.ID,
 		user.ProfileID,
 		user.StatusID,
 		user.Username,
+		user.Email,
 		user.Password,
 		user.Role,
+		user.AccountStatus,
+		user.ValidUntil,
 		user.CreatedAt,
 		user.CreatedBy,
 		user.UpdatedAt,
 		user.UpdatedBy,
 	)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to insert user into db")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert user into db")
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryMySQL) GetUserByID(ctx context.Context, id string) (*Access, error) {
+	query := "SELECT " + accessColumns + " FROM ums_users WHERE id = ? LIMIT 1"
+
+	var access Access
+	err := r.DB.Read.GetContext(ctx, &access, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get user by id")
+		return nil, err
+	}
+	return &access, nil
+}
+
+func (r *AuthRepositoryMySQL) GetUserByEmail(ctx context.Context, email string) (*Access, error) {
+	query := "SELECT " + accessColumns + " FROM ums_users WHERE email = ? LIMIT 1"
+
+	var access Access
+	err := r.DB.Read.GetContext(ctx, &access, query, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get user by email")
+		return nil, err
+	}
+	return &access, nil
+}
+
+// ChangeAccountStatus moves the account of a user to the ToStatus of change
+// and records change, setting its FromStatus. The account must be in one of
+// the from statuses, otherwise ErrAccountStatusConflict is returned. Users
+// leaving the active status are logged out: their sessions are revoked and
+// their OAuth tokens deleted.
+func (r *AuthRepositoryMySQL) ChangeAccountStatus(ctx context.Context, change *AccountStatusChange, from ...string) error {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, &change.FromStatus, "SELECT account_status FROM ums_users WHERE id = ? FOR UPDATE", change.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get account status")
+		return err
+	}
+
+	allowed := false
+	for _, status := range from {
+		if change.FromStatus == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrAccountStatusConflict
+	}
+
+	err = changeAccountStatus(ctx, tx, change)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
 		return err
 	}
+	return nil
+}
+
+// ExpireAccounts expires up to batchSize active accounts whose valid until
+// date is before today, and returns how many were expired.
+func (r *AuthRepositoryMySQL) ExpireAccounts(ctx context.Context, today string, batchSize int) (int64, error) {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var userIDs []string
+	err = tx.SelectContext(ctx, &userIDs,
+		"SELECT id FROM ums_users WHERE account_status = ? AND valid_until < ? LIMIT ? FOR UPDATE",
+		AccountStatusActive, today, batchSize)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to find accounts to expire")
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		err = changeAccountStatus(ctx, tx, &AccountStatusChange{
+			ID:         uuid.New().String(),
+			UserID:     userID,
+			FromStatus: AccountStatusActive,
+			ToStatus:   AccountStatusExpired,
+			Reason:     "Valid until date passed",
+			CreatedAt:  now,
+			CreatedBy:  "system",
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return 0, err
+	}
+	return int64(len(userIDs)), nil
+}
+
+func changeAccountStatus(ctx context.Context, tx *sqlx.Tx, change *AccountStatusChange) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE ums_users SET account_status = ?, updated_at = ?, updated_by = ? WHERE id = ?",
+		change.ToStatus, change.CreatedAt, change.CreatedBy, change.UserID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to update account status")
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO ums_account_status_history (id, user_id, from_status, to_status, reason, created_at, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		change.ID, change.UserID, change.FromStatus, change.ToStatus, change.Reason, change.CreatedAt, change.CreatedBy)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert account status history")
+		return err
+	}
+
+	if change.ToStatus == AccountStatusActive {
+		return nil
+	}
+
+	_, err = revokeSessions(ctx, tx, change.UserID, change.CreatedAt)
+	return err
+}
+
+// SetValidUntil sets the date after which the account of a user expires, or
+// clears it if validUntil is nil.
+func (r *AuthRepositoryMySQL) SetValidUntil(ctx context.Context, userID string, validUntil *string, updatedBy string) error {
+	_, err := r.DB.Write.ExecContext(ctx,
+		"UPDATE ums_users SET valid_until = ?, updated_at = ?, updated_by = ? WHERE id = ?",
+		validUntil, time.Now(), updatedBy, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to set valid until")
+		return err
+	}
+	return nil
+}
+
+// ListPendingUsers returns the users awaiting approval, oldest first.
+func (r *AuthRepositoryMySQL) ListPendingUsers(ctx context.Context) ([]PendingUser, error) {
+	query := `
+	SELECT id, username, email, created_at
+	FROM ums_users
+	WHERE account_status = ? AND deleted_at IS NULL
+	ORDER BY created_at
+	`
+
+	users := []PendingUser{}
+	err := r.DB.Read.SelectContext(ctx, &users, query, AccountStatusPendingApproval)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list pending users")
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *AuthRepositoryMySQL) CreateEmailVerification(ctx context.Context, verification *EmailVerification) error {
+	query := "INSERT INTO ums_email_verifications (token_hash, user_id, email, created_at, expires_at) VALUES (?, ?, ?, ?, ?)"
+
+	_, err := r.DB.Write.ExecContext(ctx, query,
+		verification.TokenHash,
+		verification.UserID,
+		verification.Email,
+		verification.CreatedAt,
+		verification.ExpiresAt,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to create email verification")
+		return err
+	}
+	return nil
+}
+
+// UseEmailVerification marks an unused and unexpired verification token as
+// used and moves its user from pending verification to pending approval. It
+// returns ErrInvalidVerificationToken if there is no such token.
+func (r *AuthRepositoryMySQL) UseEmailVerification(ctx context.Context, tokenHash string) (*EmailVerification, error) {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var verification EmailVerification
+	err = tx.GetContext(ctx, &verification,
+		`SELECT token_hash, user_id, email, created_at, expires_at FROM ums_email_verifications
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? FOR UPDATE`,
+		tokenHash, now)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidVerificationToken
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get email verification")
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE ums_email_verifications SET used_at = ? WHERE token_hash = ?", now, tokenHash)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to use email verification")
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE ums_users SET account_status = ?, updated_at = ? WHERE id = ? AND email = ? AND account_status = ?",
+		AccountStatusPendingApproval, now, verification.UserID, verification.Email, AccountStatusPendingVerification)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to update account status")
+		return nil, err
+	}
 
 	err = tx.Commit()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to commit transaction")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// GetMFA returns the MFA enrollment of a user, or nil if the user never
+// enrolled.
+func (r *AuthRepositoryMySQL) GetMFA(ctx context.Context, userID string) (*MFA, error) {
+	query := "SELECT user_id, secret, enabled, last_used_step, failed_attempts, locked_until, created_at, updated_at FROM ums_user_mfa WHERE user_id = ?"
+
+	var mfa MFA
+	err := r.DB.Read.GetContext(ctx, &mfa, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get mfa")
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// SaveMFASecret starts or restarts an enrollment with a new secret. Enabled
+// enrollments are left untouched.
+func (r *AuthRepositoryMySQL) SaveMFASecret(ctx context.Context, userID, secret string) error {
+	query := `
+	INSERT INTO ums_user_mfa (user_id, secret, enabled, last_used_step, created_at, updated_at)
+	VALUES (?, ?, FALSE, 0, ?, ?)
+	ON DUPLICATE KEY UPDATE
+		secret = IF(enabled, secret, VALUES(secret)),
+		updated_at = IF(enabled, updated_at, VALUES(updated_at))
+	`
+
+	now := time.Now()
+	_, err := r.DB.Write.ExecContext(ctx, query, userID, secret, now, now)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to save mfa secret")
+		return err
+	}
+	return nil
+}
+
+// EnableMFA enables the enrollment of a user, recording the step of the
+// confirmation code, and replaces the recovery codes of the user.
+func (r *AuthRepositoryMySQL) EnableMFA(ctx context.Context, userID string, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.DB.Write.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE ums_user_mfa SET enabled = TRUE, last_used_step = ?, updated_at = ? WHERE user_id = ? AND enabled = FALSE",
+		step, time.Now(), userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to enable mfa")
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM ums_user_recovery_codes WHERE user_id = ?", userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to delete recovery codes")
 		return err
 	}
 
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, "INSERT INTO ums_user_recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, codeHash)
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert recovery code")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseMFAStep records that the code of a time step was used, reporting false
+// if a code of this step or a later one was already used.
+func (r *AuthRepositoryMySQL) UseMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	result, err := r.DB.Write.ExecContext(ctx,
+		"UPDATE ums_user_mfa SET last_used_step = ? WHERE user_id = ? AND enabled = TRUE AND last_used_step < ?",
+		step, userID, step)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to record mfa step")
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// UseRecoveryCode marks a recovery code as used, reporting false if the code
+// does not exist or was already used.
+func (r *AuthRepositoryMySQL) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	result, err := r.DB.Write.ExecContext(ctx,
+		"UPDATE ums_user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		time.Now(), userID, codeHash)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to use recovery code")
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RecordMFAFailure counts a wrong code of a user. The maxAttempts-th failure
+// locks verification until lockedUntil and starts counting again.
+func (r *AuthRepositoryMySQL) RecordMFAFailure(ctx context.Context, userID string, maxAttempts int, lockedUntil time.Time) error {
+	// MySQL assigns from left to right, so locked_until is set from the count
+	// before it is reset.
+	_, err := r.DB.Write.ExecContext(ctx, `
+		UPDATE ums_user_mfa
+		SET
+			locked_until = IF(failed_attempts + 1 >= ?, ?, locked_until),
+			failed_attempts = IF(failed_attempts + 1 >= ?, 0, failed_attempts + 1)
+		WHERE user_id = ?`,
+		maxAttempts, lockedUntil, maxAttempts, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to record mfa failure")
+		return err
+	}
 	return nil
 }
+
+// ResetMFAFailures clears the failed attempts of a user after a successful
+// verification.
+func (r *AuthRepositoryMySQL) ResetMFAFailures(ctx context.Context, userID string) error {
+	_, err := r.DB.Write.ExecContext(ctx,
+		"UPDATE ums_user_mfa SET failed_attempts = 0, locked_until = NULL WHERE user_id = ? AND (failed_attempts > 0 OR locked_until IS NOT NULL)",
+		userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to reset mfa failures")
+		return err
+	}
+	return nil
+}
+
+// UseMFAChallenge records that an MFA challenge was verified, reporting false
+// if it already was. Challenges are kept until they expire, after which they
+// are purged as they can no longer be used.
+func (r *AuthRepositoryMySQL) UseMFAChallenge(ctx context.Context, challengeID, userID string, expiresAt time.Time) (bool, error) {
+	_, err := r.DB.Write.ExecContext(ctx,
+		"DELETE FROM ums_used_mfa_challenges WHERE expires_at < ? LIMIT 100",
+		time.Now().Add(-mfaChallengeRetention))
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to purge used mfa challenges")
+		return false, err
+	}
+
+	result, err := r.DB.Write.ExecContext(ctx,
+		"INSERT IGNORE INTO ums_used_mfa_challenges (id, user_id, expires_at, used_at) VALUES (?, ?, ?, ?)",
+		challengeID, userID, expiresAt, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to use mfa challenge")
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *AuthRepositoryMySQL) CreateSession(ctx context.Context, session *Session) error {
+	query := `
+	INSERT INTO ums_user_sessions (id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.DB.Write.ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		session.IPAddress,
+		session.UserAgent,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to create session")
+		return err
+	}
+	return nil
+}
+
+// ListSessions returns the sessions of a user which are neither revoked nor
+// expired, most recently used first.
+func (r *AuthRepositoryMySQL) ListSessions(ctx context.Context, userID string) ([]Session, error) {
+	query := `
+	SELECT id, user_id, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at
+	FROM ums_user_sessions
+	WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+	ORDER BY last_seen_at DESC
+	`
+
+	sessions := []Session{}
+	err := r.DB.Read.SelectContext(ctx, &sessions, query, userID, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list sessions")
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// TouchSession reports whether a session of a user and the account of the
+// user are active and, if so, records that the session was just used. The
+// last use is only updated once per sessionTouchInterval to spare a write on
+// every request.
+func (r *AuthRepositoryMySQL) TouchSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	now := time.Now()
+
+	// Sessions of accounts which stopped being active are revoked, but the
+	// account is checked too in case the expiry job did not run yet
+	query := `
+	SELECT EXISTS(
+		SELECT s.id
+		FROM ums_user_sessions AS s
+		INNER JOIN ums_users AS u ON u.id = s.user_id
+		WHERE s.id = ? AND s.user_id = ? AND s.revoked_at IS NULL AND s.expires_at > ?
+			AND u.account_status = ? AND (u.valid_until IS NULL OR u.valid_until >= ?)
+	)
+	`
+
+	var active bool
+	err := r.DB.Read.GetContext(ctx, &active, query,
+		sessionID, userID, now, AccountStatusActive, now.Format(civil.Layout))
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check session")
+		return false, err
+	}
+	if !active {
+		return false, nil
+	}
+
+	_, err = r.DB.Write.ExecContext(ctx,
+		"UPDATE ums_user_sessions SET last_seen_at = ? WHERE id = ? AND last_seen_at < ?",
+		now, sessionID, now.Add(-sessionTouchInterval))
+	if err != nil {
+		logger.FromContext(ctx).Warn().Err(err).Msg("Failed to update session last seen")
+	}
+	return true, nil
+}
+
+func (r *AuthRepositoryMySQL) RevokeSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	result, err := r.DB.Write.ExecContext(ctx,
+		"UPDATE ums_user_sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL",
+		time.Now(), sessionID, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to revoke session")
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// RevokeSessions revokes the sessions of a user and deletes their OAuth
+// tokens, returning the number of sessions revoked.
+func (r *AuthRepositoryMySQL) RevokeSessions(ctx context.Context, userID string) (int64, error) {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	revoked, err := revokeSessions(ctx, tx, userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// revokeSessions revokes the sessions of a user and deletes their OAuth
+// access and refresh tokens in tx, so that no token issued to them keeps
+// working. It returns the number of sessions revoked.
+func revokeSessions(ctx context.Context, tx *sqlx.Tx, userID string, now time.Time) (int64, error) {
+	result, err := tx.ExecContext(ctx,
+		"UPDATE ums_user_sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL",
+		now, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to revoke sessions")
+		return 0, err
+	}
+
+	for _, query := range []string{
+		"DELETE FROM oauth_access_tokens WHERE user_id = ?",
+		"DELETE FROM oauth_refresh_tokens WHERE user_id = ?",
+	} {
+		_, err = tx.ExecContext(ctx, query, userID)
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to delete oauth tokens")
+			return 0, err
+		}
+	}
+	return result.RowsAffected()
+}
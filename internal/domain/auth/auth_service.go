@@ -1,87 +1,963 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	"github.com/evermos/boilerplate-go/shared/jwks"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/mailer"
+	"github.com/evermos/boilerplate-go/shared/metrics"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/evermos/boilerplate-go/shared/totp"
+	"github.com/evermos/boilerplate-go/shared/tracing"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
-	Register(user *User) error
-	Login(username, password string) (string, error)
+	Register(ctx context.Context, user *User) error
+	Signup(ctx context.Context, signup Signup) error
+	ResendVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ListPendingSignups(ctx context.Context) ([]PendingUser, error)
+	ApproveSignup(ctx context.Context, userID, approvedBy string) error
+	RejectSignup(ctx context.Context, userID, rejectedBy, reason string) error
+	SuspendAccount(ctx context.Context, userID, suspendedBy, reason string) error
+	ReactivateAccount(ctx context.Context, userID, reactivatedBy, reason string, validUntil *string) error
+	SetValidUntil(ctx context.Context, userID string, validUntil *string, updatedBy string) error
+	ExpireAccounts(ctx context.Context) (int64, error)
+	Login(ctx context.Context, username, password string, device Device) (*LoginResult, error)
+	VerifyMFA(ctx context.Context, challengeToken, code string, device Device) (string, error)
+	EnrollMFA(ctx context.Context, userID, username string) (*MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID, code string) ([]string, error)
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) (int64, error)
 }
 
+const (
+	// defaultAccessTokenExpiry is the lifetime of JWTs when none is
+	// configured.
+	defaultAccessTokenExpiry = time.Hour
+	// defaultChallengeExpiry is the lifetime of MFA challenge tokens when
+	// none is configured.
+	defaultChallengeExpiry = 5 * time.Minute
+	// defaultMFAMaxFailedAttempts is the number of wrong MFA codes locking
+	// verification, and defaultMFALockout how long, when none is configured.
+	defaultMFAMaxFailedAttempts = 5
+	defaultMFALockout           = 15 * time.Minute
+	// mfaSkewSteps is the number of TOTP steps accepted before and after the
+	// current one, to tolerate clock drift of authenticator apps.
+	mfaSkewSteps = 1
+	// recoveryCodeCount is the number of recovery codes generated on MFA
+	// enrollment.
+	recoveryCodeCount = 10
+	// maxIPAddressLength and maxUserAgentLength are the sizes of the
+	// session columns.
+	maxIPAddressLength = 45
+	maxUserAgentLength = 255
+	// defaultVerificationExpiry is the lifetime of email verification tokens
+	// when none is configured.
+	defaultVerificationExpiry = 24 * time.Hour
+	// verificationTokenBytes is the number of random bytes of an email
+	// verification token.
+	verificationTokenBytes = 32
+	// expiryBatchSize is the maximum number of accounts expired per
+	// transaction.
+	expiryBatchSize = 100
+)
+
 type AuthServiceImpl struct {
 	AuthRepository AuthRepository
+	KeySet         *jwks.KeySet
+	Mailer         mailer.Mailer
+	Config         *configs.Config
 }
 
-func ProvideAuthServiceImpl(authRepository AuthRepository) *AuthServiceImpl {
+func ProvideAuthServiceImpl(authRepository AuthRepository, keySet *jwks.KeySet, mailer mailer.Mailer, config *configs.Config) *AuthServiceImpl {
 	return &AuthServiceImpl{
 		AuthRepository: authRepository,
+		KeySet:         keySet,
+		Mailer:         mailer,
+		Config:         config,
 	}
 }
 
-func (s *AuthServiceImpl) Register(user *User) error {
-	existingUser, err := s.AuthRepository.IsExist(user.Username)
+func (s *AuthServiceImpl) Register(ctx context.Context, user *User) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.Register")
+	defer span.End()
+
+	if user.ValidUntil != nil && *user.ValidUntil < time.Now().Format(civil.Layout) {
+		return ErrValidUntilPassed
+	}
+
+	existingUser, err := s.AuthRepository.IsExist(ctx, user.Username)
 	if err != nil {
-		log.Error().Err(err).Msg("Something went wrong")
+		logger.FromContext(ctx).Error().Err(err).Msg("Something went wrong")
+		tracing.RecordError(span, err)
 		return err
 	}
 	if existingUser {
-		log.Error().Msg("Username already exists")
+		logger.FromContext(ctx).Error().Msg("Username already exists")
 		return ErrUserExist
 	}
-	return s.AuthRepository.Register(user)
+
+	if user.Email != nil {
+		existingEmail, err := s.AuthRepository.IsEmailExist(ctx, *user.Email)
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Something went wrong")
+			tracing.RecordError(span, err)
+			return err
+		}
+		if existingEmail {
+			logger.FromContext(ctx).Error().Msg("Email already exists")
+			return ErrEmailExist
+		}
+	}
+
+	err = s.AuthRepository.Register(ctx, user)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// Signup creates a trainee account pending email verification and sends the
+// verification email. The account can only log in once its email is verified
+// and an admin approved it.
+func (s *AuthServiceImpl) Signup(ctx context.Context, signup Signup) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.Signup")
+	defer span.End()
+
+	email := normalizeEmail(signup.Email)
+	user := &User{
+		Username:      signup.Username,
+		Email:         &email,
+		Password:      signup.Password,
+		Role:          "trainee",
+		AccountStatus: AccountStatusPendingVerification,
+		CreatedBy:     signup.Username,
+		UpdatedBy:     signup.Username,
+	}
+
+	err := s.Register(ctx, user)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	// The account exists even if the email cannot be sent, so the user can
+	// ask for it again instead of signing up again.
+	err = s.sendVerification(ctx, user.ID, user.Username, email)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to send verification email")
+		tracing.RecordError(span, err)
+	}
+	return nil
+}
+
+// ResendVerification sends a new verification email to an account pending
+// verification. It does nothing for other addresses, so that it does not tell
+// which addresses have an account.
+func (s *AuthServiceImpl) ResendVerification(ctx context.Context, email string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ResendVerification")
+	defer span.End()
+
+	user, err := s.AuthRepository.GetUserByEmail(ctx, normalizeEmail(email))
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if user.AccountStatus != AccountStatusPendingVerification {
+		return nil
+	}
+
+	err = s.sendVerification(ctx, user.ID, user.Username, *user.Email)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// VerifyEmail verifies the email of the account a verification token was
+// sent to, which then awaits the approval of an admin.
+func (s *AuthServiceImpl) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.VerifyEmail")
+	defer span.End()
+
+	verification, err := s.AuthRepository.UseEmailVerification(ctx, oauth.HashToken(token))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("target_user_id", verification.UserID).Msg("Email verified")
+	return nil
+}
+
+// ListPendingSignups returns the approval queue: signed-up users who verified
+// their email, oldest first.
+func (s *AuthServiceImpl) ListPendingSignups(ctx context.Context) ([]PendingUser, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ListPendingSignups")
+	defer span.End()
+
+	users, err := s.AuthRepository.ListPendingUsers(ctx)
+	tracing.RecordError(span, err)
+	return users, err
+}
+
+// ApproveSignup activates a user pending approval and notifies them.
+func (s *AuthServiceImpl) ApproveSignup(ctx context.Context, userID, approvedBy string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ApproveSignup")
+	defer span.End()
+
+	err := s.reviewSignup(ctx, userID, AccountStatusActive, approvedBy)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	s.notify(ctx, userID, "Your account has been approved",
+		"Your account has been approved. You can now log in.")
+	return nil
+}
+
+// RejectSignup rejects a user pending approval and notifies them with the
+// reason.
+func (s *AuthServiceImpl) RejectSignup(ctx context.Context, userID, rejectedBy, reason string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.RejectSignup")
+	defer span.End()
+
+	err := s.reviewSignup(ctx, userID, AccountStatusRejected, rejectedBy)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	body := "Your account request has been rejected."
+	if reason != "" {
+		body += "\n\nReason: " + reason
+	}
+	s.notify(ctx, userID, "Your account request has been rejected", body)
+	return nil
+}
+
+func (s *AuthServiceImpl) reviewSignup(ctx context.Context, userID, status, reviewedBy string) error {
+	err := s.AuthRepository.ChangeAccountStatus(ctx, &AccountStatusChange{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ToStatus:  status,
+		CreatedAt: time.Now(),
+		CreatedBy: reviewedBy,
+	}, AccountStatusPendingApproval)
+	if err == ErrAccountStatusConflict || err == ErrNotFound {
+		return ErrNotPendingApproval
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("target_user_id", userID).Str("account_status", status).Msg("Signup reviewed")
+	return nil
 }
 
-func (s *AuthServiceImpl) UserCheck(username, password string) (*Access, error) {
-	user, err := s.AuthRepository.GetUserByUsername(username)
+// SuspendAccount blocks an active user from logging in and logs them out
+// everywhere.
+func (s *AuthServiceImpl) SuspendAccount(ctx context.Context, userID, suspendedBy, reason string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.SuspendAccount")
+	defer span.End()
+
+	err := s.AuthRepository.ChangeAccountStatus(ctx, &AccountStatusChange{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ToStatus:  AccountStatusSuspended,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+		CreatedBy: suspendedBy,
+	}, AccountStatusActive)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("target_user_id", userID).Msg("Account suspended")
+	return nil
+}
+
+// ReactivateAccount reactivates a suspended or expired user, first setting
+// the valid until date if one is given. Accounts whose valid until date has
+// passed cannot be reactivated without a new one.
+func (s *AuthServiceImpl) ReactivateAccount(ctx context.Context, userID, reactivatedBy, reason string, validUntil *string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ReactivateAccount")
+	defer span.End()
+
+	user, err := s.AuthRepository.GetUserByID(ctx, userID)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get user by username")
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if user.AccountStatus != AccountStatusSuspended && user.AccountStatus != AccountStatusExpired {
+		return ErrAccountStatusConflict
+	}
+
+	now := time.Now()
+	if validUntil != nil {
+		err = s.SetValidUntil(ctx, userID, validUntil, reactivatedBy)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return err
+		}
+	} else if user.ValidUntil != nil && *user.ValidUntil < now.Format(civil.Layout) {
+		return ErrValidUntilPassed
+	}
+
+	err = s.AuthRepository.ChangeAccountStatus(ctx, &AccountStatusChange{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ToStatus:  AccountStatusActive,
+		Reason:    reason,
+		CreatedAt: now,
+		CreatedBy: reactivatedBy,
+	}, AccountStatusSuspended, AccountStatusExpired)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("target_user_id", userID).Msg("Account reactivated")
+	return nil
+}
+
+// SetValidUntil sets the date after which the account of a user expires, or
+// clears it if validUntil is nil. The date must not be in the past.
+func (s *AuthServiceImpl) SetValidUntil(ctx context.Context, userID string, validUntil *string, updatedBy string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.SetValidUntil")
+	defer span.End()
+
+	if validUntil != nil && *validUntil < time.Now().Format(civil.Layout) {
+		return ErrValidUntilPassed
+	}
+
+	_, err := s.AuthRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	err = s.AuthRepository.SetValidUntil(ctx, userID, validUntil, updatedBy)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// ExpireAccounts expires the active accounts whose valid until date has
+// passed, logging their users out, and returns how many were expired.
+func (s *AuthServiceImpl) ExpireAccounts(ctx context.Context) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ExpireAccounts")
+	defer span.End()
+
+	today := time.Now().Format(civil.Layout)
+
+	var total int64
+	for {
+		expired, err := s.AuthRepository.ExpireAccounts(ctx, today, expiryBatchSize)
+		total += expired
+		if err != nil {
+			tracing.RecordError(span, err)
+			return total, err
+		}
+		if expired < expiryBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// sendVerification creates a verification token for email and mails a link
+// containing it.
+func (s *AuthServiceImpl) sendVerification(ctx context.Context, userID, username, email string) error {
+	token, err := generateVerificationToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiry := time.Duration(s.Config.App.Signup.VerificationExpirySeconds) * time.Second
+	if expiry <= 0 {
+		expiry = defaultVerificationExpiry
+	}
+
+	err = s.AuthRepository.CreateEmailVerification(ctx, &EmailVerification{
+		TokenHash: oauth.HashToken(token),
+		UserID:    userID,
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(expiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := verificationLink(s.Config.App.Signup.VerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      []string{email},
+		Subject: "Verify your email",
+		Body: "Hi " + username + ",\n\n" +
+			"Open the link below to verify your email. Your account will be activated once an admin approves it.\n\n" +
+			link + "\n\n" +
+			"The link expires on " + now.Add(expiry).UTC().Format(time.RFC1123) + ".",
+	})
+}
+
+// notify emails a user, if they have an email. Failures are only logged, as
+// the change they notify of already happened.
+func (s *AuthServiceImpl) notify(ctx context.Context, userID, subject, body string) {
+	user, err := s.AuthRepository.GetUserByID(ctx, userID)
+	if err != nil || user.Email == nil {
+		return
+	}
+
+	err = s.Mailer.Send(ctx, mailer.Message{
+		To:      []string{*user.Email},
+		Subject: subject,
+		Body:    "Hi " + user.Username + ",\n\n" + body,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to send notification email")
+	}
+}
+
+func (s *AuthServiceImpl) UserCheck(ctx context.Context, username, password string) (*Access, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.UserCheck")
+	defer span.End()
+
+	user, err := s.AuthRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get user by username")
 		return nil, ErrNotFound
 	}
 
-	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
-		log.Error().Err(err).Msg("User is not exist or password incorrect")
+	if user == nil || !comparePassword(ctx, user.Password, password) {
+		logger.FromContext(ctx).Error().Err(err).Msg("User is not exist or password incorrect")
 		return nil, ErrUnauthorized
 	}
 
+	// Only checked once the password is known to be right, so that the
+	// status of accounts is not disclosed
+	if !user.IsActive(time.Now()) {
+		logger.FromContext(ctx).Info().Str("account_status", user.AccountStatus).Msg("User account is not active")
+		return nil, ErrAccountInactive
+	}
+
 	return user, nil
 }
 
-func (s *AuthServiceImpl) Login(username, password string) (string, error) {
+// comparePassword checks a password against its bcrypt hash in its own span,
+// as the comparison is deliberately slow.
+func comparePassword(ctx context.Context, hashedPassword, password string) bool {
+	_, span := tracing.StartSpan(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
+}
+
+// Login checks the password of a user and starts a session on device. Users
+// with MFA enabled get a challenge to complete with VerifyMFA instead of a
+// token. When MFA is required for admins, admins who have not enrolled get a
+// token only allowing enrollment.
+func (s *AuthServiceImpl) Login(ctx context.Context, username, password string, device Device) (*LoginResult, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.Login")
+	defer span.End()
+
+	user, err := s.UserCheck(ctx, username, password)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check user")
+		metrics.ObserveLogin(metrics.LoginFailure, loginFailureReason(err))
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	mfa, err := s.AuthRepository.GetMFA(ctx, user.ID)
+	if err != nil {
+		metrics.ObserveLogin(metrics.LoginFailure, "error")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	result := &LoginResult{}
+	options := jwtOptions{
+		scopes:      oauth.ScopesForRole(user.Role),
+		authMethods: []string{jwks.AuthMethodPassword},
+	}
+	switch {
+	case mfa != nil && mfa.Enabled:
+		result.MFARequired = true
+		options = jwtOptions{
+			tokenUse:    jwks.TokenUseMFAChallenge,
+			authMethods: []string{jwks.AuthMethodPassword},
+			expiry:      s.challengeExpiry(),
+		}
+	case s.Config.App.MFA.RequireForAdmin && strings.EqualFold(user.Role, "admin"):
+		result.MFAEnrollmentRequired = true
+		options.scopes = []string{oauth.ScopeMFAEnroll}
+	}
+
+	var token string
+	if result.MFARequired {
+		token, err = generateJWT(user, options, s.KeySet, s.Config)
+	} else {
+		token, err = s.startSession(ctx, user, options, device)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to generate jwt")
+		metrics.ObserveLogin(metrics.LoginFailure, "token_error")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	if result.MFARequired {
+		result.ChallengeToken = token
+		metrics.ObserveLogin(metrics.LoginMFAChallenge, "")
+		return result, nil
+	}
+
+	result.Token = token
+	metrics.ObserveLogin(metrics.LoginSuccess, "")
+	return result, nil
+}
+
+// VerifyMFA exchanges an MFA challenge token and a TOTP or recovery code for
+// a token, starting a session on device.
+func (s *AuthServiceImpl) VerifyMFA(ctx context.Context, challengeToken, code string, device Device) (string, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.VerifyMFA")
+	defer span.End()
+
+	validation := jwks.NewValidation(s.Config)
+	validation.TokenUse = jwks.TokenUseMFAChallenge
+	claims, err := s.KeySet.ParseClaims(challengeToken, validation)
+	if err != nil {
+		logger.FromContext(ctx).Info().Err(err).Msg("Rejected MFA challenge")
+		metrics.ObserveLogin(metrics.LoginFailure, "invalid_challenge")
+		return "", ErrMFAInvalidChallenge
+	}
+
+	user, err := s.AuthRepository.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", err
+	}
+	if !user.IsActive(time.Now()) {
+		metrics.ObserveLogin(metrics.LoginFailure, loginFailureReason(ErrAccountInactive))
+		return "", ErrAccountInactive
+	}
+
+	mfa, err := s.AuthRepository.GetMFA(ctx, user.ID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return "", ErrMFANotEnrolled
+	}
+	if mfa.IsLocked(time.Now()) {
+		metrics.ObserveLogin(metrics.LoginFailure, loginFailureReason(ErrMFALocked))
+		return "", ErrMFALocked
+	}
+
+	method, err := s.useMFACode(ctx, mfa, code)
+	if err == ErrMFAInvalidCode {
+		// Failures are counted per user rather than per challenge, so that
+		// new challenges do not give more attempts.
+		failureErr := s.AuthRepository.RecordMFAFailure(ctx, user.ID, s.mfaMaxFailedAttempts(), time.Now().Add(s.mfaLockout()))
+		if failureErr != nil {
+			tracing.RecordError(span, failureErr)
+			return "", failureErr
+		}
+	}
+	if err != nil {
+		metrics.ObserveLogin(metrics.LoginFailure, loginFailureReason(err))
+		tracing.RecordError(span, err)
+		return "", err
+	}
 
-	user, err := s.UserCheck(username, password)
+	// A challenge is burnt once verified, so that it cannot be used again
+	// with a later code.
+	fresh, err := s.AuthRepository.UseMFAChallenge(ctx, claims.ID, user.ID, claims.ExpiresAt.Time)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to check user")
+		tracing.RecordError(span, err)
+		return "", err
+	}
+	if !fresh {
+		logger.FromContext(ctx).Warn().Msg("Rejected reused MFA challenge")
+		metrics.ObserveLogin(metrics.LoginFailure, "invalid_challenge")
+		return "", ErrMFAInvalidChallenge
+	}
+
+	err = s.AuthRepository.ResetMFAFailures(ctx, user.ID)
+	if err != nil {
+		tracing.RecordError(span, err)
 		return "", err
 	}
 
-	token, err := GenerateJWT(user)
+	token, err := s.startSession(ctx, user, jwtOptions{
+		scopes:      oauth.ScopesForRole(user.Role),
+		authMethods: []string{jwks.AuthMethodPassword, method},
+	}, device)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to generate jwt")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to generate jwt")
+		metrics.ObserveLogin(metrics.LoginFailure, "token_error")
+		tracing.RecordError(span, err)
 		return "", err
 	}
 
+	metrics.ObserveLogin(metrics.LoginSuccess, "")
 	return token, nil
 }
 
-func GenerateJWT(access *Access) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id":  access.ID,
-		"username": access.Username,
-		"role":     access.Role,
-		"exp":      time.Now().Add(time.Hour * 1).Unix(),
+// useMFACode consumes a TOTP code or, failing that, a recovery code, and
+// returns the matching authentication method reference.
+func (s *AuthServiceImpl) useMFACode(ctx context.Context, mfa *MFA, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(mfa.Secret, code, time.Now(), mfaSkewSteps); ok {
+		used, err := s.AuthRepository.UseMFAStep(ctx, mfa.UserID, step)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			logger.FromContext(ctx).Warn().Msg("Rejected reused MFA code")
+			return "", ErrMFAInvalidCode
+		}
+		return jwks.AuthMethodOTP, nil
+	}
+
+	used, err := s.AuthRepository.UseRecoveryCode(ctx, mfa.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return "", err
+	}
+	if !used {
+		return "", ErrMFAInvalidCode
+	}
+	logger.FromContext(ctx).Info().Msg("Recovery code used")
+	return jwks.AuthMethodMFA, nil
+}
+
+// EnrollMFA generates a new TOTP secret for a user. It only takes effect once
+// confirmed with ConfirmMFA.
+func (s *AuthServiceImpl) EnrollMFA(ctx context.Context, userID, username string) (*MFAEnrollment, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.EnrollMFA")
+	defer span.End()
+
+	mfa, err := s.AuthRepository.GetMFA(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if mfa != nil && mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	err = s.AuthRepository.SaveMFASecret(ctx, userID, secret)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	issuer := s.Config.App.MFA.Issuer
+	if issuer == "" {
+		issuer = s.Config.App.Name
+	}
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(issuer, username, secret),
+	}, nil
+}
+
+// ConfirmMFA enables the pending enrollment of a user with a code from the
+// authenticator app, and returns newly generated recovery codes. The codes
+// are only stored hashed, so this is the only time they can be shown.
+func (s *AuthServiceImpl) ConfirmMFA(ctx context.Context, userID, code string) ([]string, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ConfirmMFA")
+	defer span.End()
+
+	mfa, err := s.AuthRepository.GetMFA(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	step, ok := totp.Validate(mfa.Secret, strings.TrimSpace(code), time.Now(), mfaSkewSteps)
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
 
-	config := configs.Get()
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	err = s.AuthRepository.EnableMFA(ctx, userID, step, hashes)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info().Msg("MFA enabled")
+	return codes, nil
+}
+
+// startSession records a session of user on device and issues a token bound
+// to it, which stops working when the session is revoked. The session lasts
+// as long as the token.
+func (s *AuthServiceImpl) startSession(ctx context.Context, user *Access, options jwtOptions, device Device) (string, error) {
+	now := time.Now()
+	options.sessionID = uuid.New().String()
+	options.expiry = accessTokenExpiry(s.Config)
+
+	err := s.AuthRepository.CreateSession(ctx, &Session{
+		ID:         options.sessionID,
+		UserID:     user.ID,
+		IPAddress:  truncate(device.IPAddress, maxIPAddressLength),
+		UserAgent:  truncate(device.UserAgent, maxUserAgentLength),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(options.expiry),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return generateJWT(user, options, s.KeySet, s.Config)
+}
+
+// ListSessions returns the active sessions of a user, most recently used
+// first, marking the session with currentSessionID as current.
+func (s *AuthServiceImpl) ListSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.ListSessions")
+	defer span.End()
+
+	sessions, err := s.AuthRepository.ListSessions(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of a user, or returns ErrSessionNotFound if
+// the user has no such active session.
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.RevokeSession")
+	defer span.End()
+
+	revoked, err := s.AuthRepository.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+
+	logger.FromContext(ctx).Info().Str("session_id", sessionID).Msg("Session revoked")
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of a user and deletes their
+// OAuth tokens, logging the user out everywhere, and returns the number of
+// sessions revoked.
+func (s *AuthServiceImpl) RevokeAllSessions(ctx context.Context, userID string) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuthService.RevokeAllSessions")
+	defer span.End()
+
+	revoked, err := s.AuthRepository.RevokeSessions(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Info().Str("target_user_id", userID).Int64("sessions", revoked).Msg("All sessions revoked")
+	return revoked, nil
+}
+
+func (s *AuthServiceImpl) challengeExpiry() time.Duration {
+	expiry := time.Duration(s.Config.App.MFA.ChallengeExpirySeconds) * time.Second
+	if expiry <= 0 {
+		return defaultChallengeExpiry
+	}
+	return expiry
+}
+
+func (s *AuthServiceImpl) mfaMaxFailedAttempts() int {
+	if s.Config.App.MFA.MaxFailedAttempts <= 0 {
+		return defaultMFAMaxFailedAttempts
+	}
+	return s.Config.App.MFA.MaxFailedAttempts
+}
+
+func (s *AuthServiceImpl) mfaLockout() time.Duration {
+	lockout := time.Duration(s.Config.App.MFA.LockoutSeconds) * time.Second
+	if lockout <= 0 {
+		return defaultMFALockout
+	}
+	return lockout
+}
+
+// generateRecoveryCode returns a random recovery code such as 4f7k-2hq9.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[:4] + "-" + code[4:], nil
+}
+
+// generateVerificationToken returns a random URL-safe token.
+func generateVerificationToken() (string, error) {
+	b := make([]byte, verificationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// verificationLink adds token to the configured verification URL.
+func verificationLink(verificationURL, token string) (string, error) {
+	link, err := url.Parse(verificationURL)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// truncate shortens s to at most n bytes, to fit its column.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// hashRecoveryCode returns the digest a recovery code is stored as. Codes are
+// compared case-insensitively and without the separator.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.Replace(code, "-", "", -1))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func loginFailureReason(err error) string {
+	switch err {
+	case ErrNotFound:
+		return "user_not_found"
+	case ErrUnauthorized:
+		return "invalid_credentials"
+	case ErrMFAInvalidCode:
+		return "invalid_mfa_code"
+	case ErrMFALocked:
+		return "mfa_locked"
+	case ErrAccountInactive:
+		return "account_inactive"
+	default:
+		return "error"
+	}
+}
+
+// jwtOptions are the claims that differ between the tokens issued by login.
+// Tokens expire after the configured access token expiry unless expiry is
+// set.
+type jwtOptions struct {
+	scopes      []string
+	authMethods []string
+	tokenUse    string
+	sessionID   string
+	expiry      time.Duration
+}
+
+// accessTokenExpiry returns the configured lifetime of access tokens.
+func accessTokenExpiry(config *configs.Config) time.Duration {
+	expiry := time.Duration(config.App.JWT.AccessTokenExpirySeconds) * time.Second
+	if expiry <= 0 {
+		return defaultAccessTokenExpiry
+	}
+	return expiry
+}
+
+// generateJWT issues a JWT for access, signed with the active key of keySet.
+func generateJWT(access *Access, options jwtOptions, keySet *jwks.KeySet, config *configs.Config) (string, error) {
+	jwtConfig := config.App.JWT
+	expiry := options.expiry
+	if expiry <= 0 {
+		expiry = accessTokenExpiry(config)
+	}
+
+	now := time.Now()
+	claims := jwks.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    jwtConfig.Issuer,
+			Subject:   access.ID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+		UserID:      access.ID,
+		Username:    access.Username,
+		Role:        access.Role,
+		Scope:       oauth.FormatScope(options.scopes),
+		AuthMethods: options.authMethods,
+		TokenUse:    options.tokenUse,
+		SessionID:   options.sessionID,
+	}
+	if jwtConfig.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{jwtConfig.Audience}
+	}
 
-	tokenString, err := token.SignedString([]byte(config.App.JWTAccessKey))
+	tokenString, err := keySet.Sign(claims)
 	if err != nil {
 		log.Error().Err(err).Msg("Service: Failed to generate jwt")
 		return "", err
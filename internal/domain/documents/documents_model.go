@@ -0,0 +1,62 @@
+package documents
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("document not found")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrInvalidType      = errors.New("invalid document type")
+	ErrChecksumMismatch = errors.New("document checksum mismatch")
+	ErrCorrupted        = errors.New("stored document does not match its checksum")
+)
+
+// Document types.
+const (
+	TypeContract    = "contract"
+	TypeCertificate = "certificate"
+	TypeIdentity    = "identity"
+	TypeOther       = "other"
+)
+
+// IsValidType reports whether documentType is a known document type.
+func IsValidType(documentType string) bool {
+	switch documentType {
+	case TypeContract, TypeCertificate, TypeIdentity, TypeOther:
+		return true
+	}
+	return false
+}
+
+// Document describes a document of a user. Its content is downloaded
+// separately. Expired is set when ExpiresOn has passed.
+type Document struct {
+	ID          string    `db:"id" json:"id"`
+	UserID      string    `db:"user_id" json:"userId"`
+	Type        string    `db:"type" json:"type"`
+	FileName    string    `db:"file_name" json:"fileName"`
+	ContentType string    `db:"content_type" json:"contentType"`
+	Size        int64     `db:"size" json:"size"`
+	Checksum    string    `db:"checksum" json:"checksum"`
+	StorageKey  string    `db:"storage_key" json:"-"`
+	ExpiresOn   *string   `db:"expires_on" json:"expiresOn"`
+	Expired     bool      `db:"-" json:"expired"`
+	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+	CreatedBy   string    `db:"created_by" json:"createdBy"`
+}
+
+// Upload is a document to add to a user. Checksum, if set, is the SHA-256 of
+// Content in hex the client computed, and the upload is refused if the
+// content received does not match it.
+type Upload struct {
+	UserID      string
+	Type        string
+	FileName    string
+	ContentType string
+	ExpiresOn   *string
+	Checksum    string
+	Content     []byte
+	CreatedBy   string
+}
@@ -0,0 +1,118 @@
+package documents
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+)
+
+type DocumentRepository interface {
+	CreateDocument(ctx context.Context, document *Document) error
+	ListDocuments(ctx context.Context, userID string) ([]Document, error)
+	GetDocument(ctx context.Context, userID, id string) (*Document, error)
+	DeleteDocument(ctx context.Context, userID, id string) error
+}
+
+type DocumentRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+func ProvideDocumentRepositoryMySQL(db *infras.MySQLConn) *DocumentRepositoryMySQL {
+	return &DocumentRepositoryMySQL{
+		DB: db,
+	}
+}
+
+// documentColumns are the columns of ums_user_documents selected into a
+// Document.
+const documentColumns = `
+	id, user_id, type, file_name, content_type, size, checksum, storage_key,
+	DATE_FORMAT(expires_on, '%Y-%m-%d') AS expires_on, created_at, created_by`
+
+// CreateDocument records a document. It returns ErrUserNotFound if the user
+// does not exist.
+func (r *DocumentRepositoryMySQL) CreateDocument(ctx context.Context, document *Document) error {
+	query := `
+	INSERT INTO ums_user_documents
+		(id, user_id, type, file_name, content_type, size, checksum, storage_key, expires_on, created_at, created_by)
+	SELECT ?, id, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	FROM ums_users
+	WHERE id = ?
+	`
+
+	result, err := r.DB.Write.ExecContext(ctx, query,
+		document.ID, document.Type, document.FileName, document.ContentType, document.Size,
+		document.Checksum, document.StorageKey, document.ExpiresOn, document.CreatedAt, document.CreatedBy,
+		document.UserID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to create document")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ListDocuments returns the documents of a user, latest first.
+func (r *DocumentRepositoryMySQL) ListDocuments(ctx context.Context, userID string) ([]Document, error) {
+	query := `SELECT ` + documentColumns + `
+	FROM ums_user_documents
+	WHERE user_id = ?
+	ORDER BY created_at DESC
+	`
+
+	documents := []Document{}
+	err := r.DB.Read.SelectContext(ctx, &documents, query, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list documents")
+		return nil, err
+	}
+	return documents, nil
+}
+
+// GetDocument returns a document of a user, or ErrNotFound if the user has no
+// such document.
+func (r *DocumentRepositoryMySQL) GetDocument(ctx context.Context, userID, id string) (*Document, error) {
+	query := `SELECT ` + documentColumns + `
+	FROM ums_user_documents
+	WHERE user_id = ? AND id = ?
+	`
+
+	var document Document
+	err := r.DB.Read.GetContext(ctx, &document, query, userID, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get document")
+		return nil, err
+	}
+	return &document, nil
+}
+
+// DeleteDocument deletes the record of a document of a user, or returns
+// ErrNotFound if the user has no such document.
+func (r *DocumentRepositoryMySQL) DeleteDocument(ctx context.Context, userID, id string) error {
+	result, err := r.DB.Write.ExecContext(ctx, `DELETE FROM ums_user_documents WHERE user_id = ? AND id = ?`, userID, id)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to delete document")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package documents
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/storage"
+	"github.com/evermos/boilerplate-go/shared/tracing"
+	"github.com/google/uuid"
+)
+
+// defaultDir is where the local document storage keeps documents when no
+// directory is configured.
+const defaultDir = "documents"
+
+// Storage keeps the content of documents. Documents are private, so unlike
+// the storage of photos it is never served directly: they are downloaded
+// through the API, which checks who may read them.
+type Storage interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ProvideDocumentStorage returns the document storage selected by the driver
+// configured in env var.
+func ProvideDocumentStorage(config *configs.Config) Storage {
+	storageConfig := config.App.Documents.Storage
+	if storageConfig.Local.Dir == "" {
+		storageConfig.Local.Dir = defaultDir
+	}
+	return storage.New(storageConfig)
+}
+
+type DocumentService interface {
+	UploadDocument(ctx context.Context, upload Upload) (*Document, error)
+	ListDocuments(ctx context.Context, userID string) ([]Document, error)
+	DownloadDocument(ctx context.Context, userID, id string) (*Document, []byte, error)
+	DeleteDocument(ctx context.Context, userID, id string) error
+}
+
+type DocumentServiceImpl struct {
+	DocumentRepository DocumentRepository
+	Storage            Storage
+}
+
+func ProvideDocumentServiceImpl(documentRepository DocumentRepository, storage Storage) *DocumentServiceImpl {
+	return &DocumentServiceImpl{
+		DocumentRepository: documentRepository,
+		Storage:            storage,
+	}
+}
+
+// UploadDocument stores a document of a user, refusing it if it does not
+// match the checksum the client sent.
+func (s *DocumentServiceImpl) UploadDocument(ctx context.Context, upload Upload) (*Document, error) {
+	ctx, span := tracing.StartSpan(ctx, "DocumentService.UploadDocument")
+	defer span.End()
+
+	if !IsValidType(upload.Type) {
+		return nil, ErrInvalidType
+	}
+
+	checksum := sha256Hex(upload.Content)
+	if upload.Checksum != "" && !strings.EqualFold(upload.Checksum, checksum) {
+		return nil, ErrChecksumMismatch
+	}
+
+	document := &Document{
+		ID:          uuid.New().String(),
+		UserID:      upload.UserID,
+		Type:        upload.Type,
+		FileName:    upload.FileName,
+		ContentType: upload.ContentType,
+		Size:        int64(len(upload.Content)),
+		Checksum:    checksum,
+		ExpiresOn:   upload.ExpiresOn,
+		CreatedAt:   time.Now(),
+		CreatedBy:   upload.CreatedBy,
+	}
+	document.StorageKey = fmt.Sprintf("documents/%s/%s", document.UserID, document.ID)
+	document.Expired = isExpired(document.ExpiresOn, document.CreatedAt)
+
+	err := s.Storage.Put(ctx, document.StorageKey, upload.Content, document.ContentType)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to store document")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	err = s.DocumentRepository.CreateDocument(ctx, document)
+	if err != nil {
+		tracing.RecordError(span, err)
+		s.deleteContent(ctx, document.StorageKey)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("target_user_id", document.UserID).
+		Str("document_id", document.ID).
+		Str("document_type", document.Type).
+		Msg("Document uploaded")
+	return document, nil
+}
+
+func (s *DocumentServiceImpl) ListDocuments(ctx context.Context, userID string) ([]Document, error) {
+	ctx, span := tracing.StartSpan(ctx, "DocumentService.ListDocuments")
+	defer span.End()
+
+	documents, err := s.DocumentRepository.ListDocuments(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range documents {
+		documents[i].Expired = isExpired(documents[i].ExpiresOn, now)
+	}
+	return documents, nil
+}
+
+// DownloadDocument returns a document of a user with its content. The content
+// is verified against the checksum recorded on upload, and ErrCorrupted is
+// returned rather than a document that changed in the storage.
+func (s *DocumentServiceImpl) DownloadDocument(ctx context.Context, userID, id string) (*Document, []byte, error) {
+	ctx, span := tracing.StartSpan(ctx, "DocumentService.DownloadDocument")
+	defer span.End()
+
+	document, err := s.DocumentRepository.GetDocument(ctx, userID, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+
+	content, err := s.Storage.Get(ctx, document.StorageKey)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Str("document_id", id).Msg("Failed to read document")
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+
+	if sha256Hex(content) != document.Checksum {
+		logger.FromContext(ctx).Error().Str("document_id", id).Msg("Stored document does not match its checksum")
+		tracing.RecordError(span, ErrCorrupted)
+		return nil, nil, ErrCorrupted
+	}
+
+	document.Expired = isExpired(document.ExpiresOn, time.Now())
+	return document, content, nil
+}
+
+func (s *DocumentServiceImpl) DeleteDocument(ctx context.Context, userID, id string) error {
+	ctx, span := tracing.StartSpan(ctx, "DocumentService.DeleteDocument")
+	defer span.End()
+
+	document, err := s.DocumentRepository.GetDocument(ctx, userID, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	err = s.DocumentRepository.DeleteDocument(ctx, userID, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	s.deleteContent(ctx, document.StorageKey)
+
+	logger.FromContext(ctx).Info().
+		Str("target_user_id", userID).
+		Str("document_id", id).
+		Msg("Document deleted")
+	return nil
+}
+
+// deleteContent deletes the content of a document. Failures are only logged:
+// the record is what makes a document exist.
+func (s *DocumentServiceImpl) deleteContent(ctx context.Context, key string) {
+	if err := s.Storage.Delete(ctx, key); err != nil {
+		logger.FromContext(ctx).Warn().Err(err).Str("key", key).Msg("Failed to delete document content")
+	}
+}
+
+// isExpired reports whether a document expiring on expiresOn has expired at
+// now. Documents expire at the end of their expiry date.
+func isExpired(expiresOn *string, now time.Time) bool {
+	if expiresOn == nil {
+		return false
+	}
+	return *expiresOn < now.Format(civil.Layout)
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
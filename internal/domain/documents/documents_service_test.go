@@ -0,0 +1,140 @@
+package documents
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRepository is a DocumentRepository keeping documents in memory.
+type memoryRepository struct {
+	documents map[string]Document
+}
+
+func (r *memoryRepository) CreateDocument(ctx context.Context, document *Document) error {
+	r.documents[document.ID] = *document
+	return nil
+}
+
+func (r *memoryRepository) ListDocuments(ctx context.Context, userID string) ([]Document, error) {
+	documents := []Document{}
+	for _, document := range r.documents {
+		if document.UserID == userID {
+			documents = append(documents, document)
+		}
+	}
+	return documents, nil
+}
+
+func (r *memoryRepository) GetDocument(ctx context.Context, userID, id string) (*Document, error) {
+	document, ok := r.documents[id]
+	if !ok || document.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return &document, nil
+}
+
+func (r *memoryRepository) DeleteDocument(ctx context.Context, userID, id string) error {
+	if _, err := r.GetDocument(ctx, userID, id); err != nil {
+		return err
+	}
+	delete(r.documents, id)
+	return nil
+}
+
+func newTestService(t *testing.T) (*DocumentServiceImpl, string) {
+	dir, err := ioutil.TempDir("", "documents")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	repository := &memoryRepository{documents: map[string]Document{}}
+	return ProvideDocumentServiceImpl(repository, storage.NewLocalStorage(dir, "")), dir
+}
+
+func TestUploadAndDownloadDocument(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+
+	document, err := service.UploadDocument(ctx, Upload{
+		UserID:      "user",
+		Type:        TypeContract,
+		FileName:    "contract.pdf",
+		ContentType: "application/pdf",
+		// Not the SHA-256 of "contract".
+		Checksum:  "F2E3F1F6B4ED6B3D20C1A1E1E1CBE4A5E6DFEB6F8D7B8F3AD0A8D4A1C56E3E8F",
+		Content:   []byte("contract"),
+		CreatedBy: "admin",
+	})
+	assert.Equal(t, ErrChecksumMismatch, err)
+	assert.Nil(t, document)
+
+	document, err = service.UploadDocument(ctx, Upload{
+		UserID:      "user",
+		Type:        TypeContract,
+		FileName:    "contract.pdf",
+		ContentType: "application/pdf",
+		Checksum:    sha256Hex([]byte("contract")),
+		Content:     []byte("contract"),
+		CreatedBy:   "admin",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), document.Size)
+
+	downloaded, content, err := service.DownloadDocument(ctx, "user", document.ID)
+	require.NoError(t, err)
+	assert.Equal(t, document.ID, downloaded.ID)
+	assert.Equal(t, "contract", string(content))
+
+	_, _, err = service.DownloadDocument(ctx, "someone-else", document.ID)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = service.UploadDocument(ctx, Upload{UserID: "user", Type: "payslip", Content: []byte("payslip")})
+	assert.Equal(t, ErrInvalidType, err)
+}
+
+func TestDownloadCorruptedDocument(t *testing.T) {
+	service, dir := newTestService(t)
+	ctx := context.Background()
+
+	document, err := service.UploadDocument(ctx, Upload{
+		UserID:  "user",
+		Type:    TypeCertificate,
+		Content: []byte("certificate"),
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, filepath.FromSlash(document.StorageKey))
+	require.NoError(t, ioutil.WriteFile(path, []byte("tampered"), 0o600))
+
+	_, _, err = service.DownloadDocument(ctx, "user", document.ID)
+	assert.Equal(t, ErrCorrupted, err)
+}
+
+func TestDeleteDocument(t *testing.T) {
+	service, dir := newTestService(t)
+	ctx := context.Background()
+
+	document, err := service.UploadDocument(ctx, Upload{UserID: "user", Type: TypeOther, Content: []byte("other")})
+	require.NoError(t, err)
+
+	require.NoError(t, service.DeleteDocument(ctx, "user", document.ID))
+	_, err = os.Stat(filepath.Join(dir, filepath.FromSlash(document.StorageKey)))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, ErrNotFound, service.DeleteDocument(ctx, "user", document.ID))
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	yesterday, today := "2026-03-14", "2026-03-15"
+
+	assert.False(t, isExpired(nil, now))
+	assert.False(t, isExpired(&today, now))
+	assert.True(t, isExpired(&yesterday, now))
+}
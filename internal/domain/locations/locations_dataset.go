@@ -0,0 +1,669 @@
+package locations
+
+// The bundled reference dataset: the provinces of Indonesia and their
+// regencies (kabupaten) and cities (kota), as of the 2022 division of Papua.
+// Province codes are the BPS/Kemendagri codes. City IDs are the province code
+// followed by a number, 01 onwards for regencies and 71 onwards for cities,
+// assigned by this dataset in alphabetical order; they are stable, so new
+// entries must get new IDs rather than renumber existing ones.
+
+// Provinces are the provinces of the dataset.
+var Provinces = []Province{
+	{Code: "11", Name: "Aceh"},
+	{Code: "12", Name: "Sumatera Utara"},
+	{Code: "13", Name: "Sumatera Barat"},
+	{Code: "14", Name: "Riau"},
+	{Code: "15", Name: "Jambi"},
+	{Code: "16", Name: "Sumatera Selatan"},
+	{Code: "17", Name: "Bengkulu"},
+	{Code: "18", Name: "Lampung"},
+	{Code: "19", Name: "Kepulauan Bangka Belitung"},
+	{Code: "21", Name: "Kepulauan Riau"},
+	{Code: "31", Name: "DKI Jakarta"},
+	{Code: "32", Name: "Jawa Barat"},
+	{Code: "33", Name: "Jawa Tengah"},
+	{Code: "34", Name: "DI Yogyakarta"},
+	{Code: "35", Name: "Jawa Timur"},
+	{Code: "36", Name: "Banten"},
+	{Code: "51", Name: "Bali"},
+	{Code: "52", Name: "Nusa Tenggara Barat"},
+	{Code: "53", Name: "Nusa Tenggara Timur"},
+	{Code: "61", Name: "Kalimantan Barat"},
+	{Code: "62", Name: "Kalimantan Tengah"},
+	{Code: "63", Name: "Kalimantan Selatan"},
+	{Code: "64", Name: "Kalimantan Timur"},
+	{Code: "65", Name: "Kalimantan Utara"},
+	{Code: "71", Name: "Sulawesi Utara"},
+	{Code: "72", Name: "Sulawesi Tengah"},
+	{Code: "73", Name: "Sulawesi Selatan"},
+	{Code: "74", Name: "Sulawesi Tenggara"},
+	{Code: "75", Name: "Gorontalo"},
+	{Code: "76", Name: "Sulawesi Barat"},
+	{Code: "81", Name: "Maluku"},
+	{Code: "82", Name: "Maluku Utara"},
+	{Code: "91", Name: "Papua"},
+	{Code: "92", Name: "Papua Barat"},
+	{Code: "93", Name: "Papua Selatan"},
+	{Code: "94", Name: "Papua Tengah"},
+	{Code: "95", Name: "Papua Pegunungan"},
+	{Code: "96", Name: "Papua Barat Daya"},
+}
+
+// Cities are the regencies and cities of the dataset.
+var Cities = []City{
+	// Aceh
+	{ID: 1101, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Barat"},
+	{ID: 1102, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Barat Daya"},
+	{ID: 1103, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Besar"},
+	{ID: 1104, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Jaya"},
+	{ID: 1105, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Selatan"},
+	{ID: 1106, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Singkil"},
+	{ID: 1107, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Tamiang"},
+	{ID: 1108, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Tengah"},
+	{ID: 1109, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Tenggara"},
+	{ID: 1110, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Timur"},
+	{ID: 1111, ProvinceCode: "11", Type: TypeRegency, Name: "Aceh Utara"},
+	{ID: 1112, ProvinceCode: "11", Type: TypeRegency, Name: "Bener Meriah"},
+	{ID: 1113, ProvinceCode: "11", Type: TypeRegency, Name: "Bireuen"},
+	{ID: 1114, ProvinceCode: "11", Type: TypeRegency, Name: "Gayo Lues"},
+	{ID: 1115, ProvinceCode: "11", Type: TypeRegency, Name: "Nagan Raya"},
+	{ID: 1116, ProvinceCode: "11", Type: TypeRegency, Name: "Pidie"},
+	{ID: 1117, ProvinceCode: "11", Type: TypeRegency, Name: "Pidie Jaya"},
+	{ID: 1118, ProvinceCode: "11", Type: TypeRegency, Name: "Simeulue"},
+	{ID: 1171, ProvinceCode: "11", Type: TypeCity, Name: "Banda Aceh"},
+	{ID: 1172, ProvinceCode: "11", Type: TypeCity, Name: "Langsa"},
+	{ID: 1173, ProvinceCode: "11", Type: TypeCity, Name: "Lhokseumawe"},
+	{ID: 1174, ProvinceCode: "11", Type: TypeCity, Name: "Sabang"},
+	{ID: 1175, ProvinceCode: "11", Type: TypeCity, Name: "Subulussalam"},
+	// Sumatera Utara
+	{ID: 1201, ProvinceCode: "12", Type: TypeRegency, Name: "Asahan"},
+	{ID: 1202, ProvinceCode: "12", Type: TypeRegency, Name: "Batu Bara"},
+	{ID: 1203, ProvinceCode: "12", Type: TypeRegency, Name: "Dairi"},
+	{ID: 1204, ProvinceCode: "12", Type: TypeRegency, Name: "Deli Serdang"},
+	{ID: 1205, ProvinceCode: "12", Type: TypeRegency, Name: "Humbang Hasundutan"},
+	{ID: 1206, ProvinceCode: "12", Type: TypeRegency, Name: "Karo"},
+	{ID: 1207, ProvinceCode: "12", Type: TypeRegency, Name: "Labuhanbatu"},
+	{ID: 1208, ProvinceCode: "12", Type: TypeRegency, Name: "Labuhanbatu Selatan"},
+	{ID: 1209, ProvinceCode: "12", Type: TypeRegency, Name: "Labuhanbatu Utara"},
+	{ID: 1210, ProvinceCode: "12", Type: TypeRegency, Name: "Langkat"},
+	{ID: 1211, ProvinceCode: "12", Type: TypeRegency, Name: "Mandailing Natal"},
+	{ID: 1212, ProvinceCode: "12", Type: TypeRegency, Name: "Nias"},
+	{ID: 1213, ProvinceCode: "12", Type: TypeRegency, Name: "Nias Barat"},
+	{ID: 1214, ProvinceCode: "12", Type: TypeRegency, Name: "Nias Selatan"},
+	{ID: 1215, ProvinceCode: "12", Type: TypeRegency, Name: "Nias Utara"},
+	{ID: 1216, ProvinceCode: "12", Type: TypeRegency, Name: "Padang Lawas"},
+	{ID: 1217, ProvinceCode: "12", Type: TypeRegency, Name: "Padang Lawas Utara"},
+	{ID: 1218, ProvinceCode: "12", Type: TypeRegency, Name: "Pakpak Bharat"},
+	{ID: 1219, ProvinceCode: "12", Type: TypeRegency, Name: "Samosir"},
+	{ID: 1220, ProvinceCode: "12", Type: TypeRegency, Name: "Serdang Bedagai"},
+	{ID: 1221, ProvinceCode: "12", Type: TypeRegency, Name: "Simalungun"},
+	{ID: 1222, ProvinceCode: "12", Type: TypeRegency, Name: "Tapanuli Selatan"},
+	{ID: 1223, ProvinceCode: "12", Type: TypeRegency, Name: "Tapanuli Tengah"},
+	{ID: 1224, ProvinceCode: "12", Type: TypeRegency, Name: "Tapanuli Utara"},
+	{ID: 1225, ProvinceCode: "12", Type: TypeRegency, Name: "Toba"},
+	{ID: 1271, ProvinceCode: "12", Type: TypeCity, Name: "Binjai"},
+	{ID: 1272, ProvinceCode: "12", Type: TypeCity, Name: "Gunungsitoli"},
+	{ID: 1273, ProvinceCode: "12", Type: TypeCity, Name: "Medan"},
+	{ID: 1274, ProvinceCode: "12", Type: TypeCity, Name: "Padangsidimpuan"},
+	{ID: 1275, ProvinceCode: "12", Type: TypeCity, Name: "Pematangsiantar"},
+	{ID: 1276, ProvinceCode: "12", Type: TypeCity, Name: "Sibolga"},
+	{ID: 1277, ProvinceCode: "12", Type: TypeCity, Name: "Tanjungbalai"},
+	{ID: 1278, ProvinceCode: "12", Type: TypeCity, Name: "Tebing Tinggi"},
+	// Sumatera Barat
+	{ID: 1301, ProvinceCode: "13", Type: TypeRegency, Name: "Agam"},
+	{ID: 1302, ProvinceCode: "13", Type: TypeRegency, Name: "Dharmasraya"},
+	{ID: 1303, ProvinceCode: "13", Type: TypeRegency, Name: "Kepulauan Mentawai"},
+	{ID: 1304, ProvinceCode: "13", Type: TypeRegency, Name: "Lima Puluh Kota"},
+	{ID: 1305, ProvinceCode: "13", Type: TypeRegency, Name: "Padang Pariaman"},
+	{ID: 1306, ProvinceCode: "13", Type: TypeRegency, Name: "Pasaman"},
+	{ID: 1307, ProvinceCode: "13", Type: TypeRegency, Name: "Pasaman Barat"},
+	{ID: 1308, ProvinceCode: "13", Type: TypeRegency, Name: "Pesisir Selatan"},
+	{ID: 1309, ProvinceCode: "13", Type: TypeRegency, Name: "Sijunjung"},
+	{ID: 1310, ProvinceCode: "13", Type: TypeRegency, Name: "Solok"},
+	{ID: 1311, ProvinceCode: "13", Type: TypeRegency, Name: "Solok Selatan"},
+	{ID: 1312, ProvinceCode: "13", Type: TypeRegency, Name: "Tanah Datar"},
+	{ID: 1371, ProvinceCode: "13", Type: TypeCity, Name: "Bukittinggi"},
+	{ID: 1372, ProvinceCode: "13", Type: TypeCity, Name: "Padang"},
+	{ID: 1373, ProvinceCode: "13", Type: TypeCity, Name: "Padang Panjang"},
+	{ID: 1374, ProvinceCode: "13", Type: TypeCity, Name: "Pariaman"},
+	{ID: 1375, ProvinceCode: "13", Type: TypeCity, Name: "Payakumbuh"},
+	{ID: 1376, ProvinceCode: "13", Type: TypeCity, Name: "Sawahlunto"},
+	{ID: 1377, ProvinceCode: "13", Type: TypeCity, Name: "Solok"},
+	// Riau
+	{ID: 1401, ProvinceCode: "14", Type: TypeRegency, Name: "Bengkalis"},
+	{ID: 1402, ProvinceCode: "14", Type: TypeRegency, Name: "Indragiri Hilir"},
+	{ID: 1403, ProvinceCode: "14", Type: TypeRegency, Name: "Indragiri Hulu"},
+	{ID: 1404, ProvinceCode: "14", Type: TypeRegency, Name: "Kampar"},
+	{ID: 1405, ProvinceCode: "14", Type: TypeRegency, Name: "Kepulauan Meranti"},
+	{ID: 1406, ProvinceCode: "14", Type: TypeRegency, Name: "Kuantan Singingi"},
+	{ID: 1407, ProvinceCode: "14", Type: TypeRegency, Name: "Pelalawan"},
+	{ID: 1408, ProvinceCode: "14", Type: TypeRegency, Name: "Rokan Hilir"},
+	{ID: 1409, ProvinceCode: "14", Type: TypeRegency, Name: "Rokan Hulu"},
+	{ID: 1410, ProvinceCode: "14", Type: TypeRegency, Name: "Siak"},
+	{ID: 1471, ProvinceCode: "14", Type: TypeCity, Name: "Dumai"},
+	{ID: 1472, ProvinceCode: "14", Type: TypeCity, Name: "Pekanbaru"},
+	// Jambi
+	{ID: 1501, ProvinceCode: "15", Type: TypeRegency, Name: "Batanghari"},
+	{ID: 1502, ProvinceCode: "15", Type: TypeRegency, Name: "Bungo"},
+	{ID: 1503, ProvinceCode: "15", Type: TypeRegency, Name: "Kerinci"},
+	{ID: 1504, ProvinceCode: "15", Type: TypeRegency, Name: "Merangin"},
+	{ID: 1505, ProvinceCode: "15", Type: TypeRegency, Name: "Muaro Jambi"},
+	{ID: 1506, ProvinceCode: "15", Type: TypeRegency, Name: "Sarolangun"},
+	{ID: 1507, ProvinceCode: "15", Type: TypeRegency, Name: "Tanjung Jabung Barat"},
+	{ID: 1508, ProvinceCode: "15", Type: TypeRegency, Name: "Tanjung Jabung Timur"},
+	{ID: 1509, ProvinceCode: "15", Type: TypeRegency, Name: "Tebo"},
+	{ID: 1571, ProvinceCode: "15", Type: TypeCity, Name: "Jambi"},
+	{ID: 1572, ProvinceCode: "15", Type: TypeCity, Name: "Sungai Penuh"},
+	// Sumatera Selatan
+	{ID: 1601, ProvinceCode: "16", Type: TypeRegency, Name: "Banyuasin"},
+	{ID: 1602, ProvinceCode: "16", Type: TypeRegency, Name: "Empat Lawang"},
+	{ID: 1603, ProvinceCode: "16", Type: TypeRegency, Name: "Lahat"},
+	{ID: 1604, ProvinceCode: "16", Type: TypeRegency, Name: "Muara Enim"},
+	{ID: 1605, ProvinceCode: "16", Type: TypeRegency, Name: "Musi Banyuasin"},
+	{ID: 1606, ProvinceCode: "16", Type: TypeRegency, Name: "Musi Rawas"},
+	{ID: 1607, ProvinceCode: "16", Type: TypeRegency, Name: "Musi Rawas Utara"},
+	{ID: 1608, ProvinceCode: "16", Type: TypeRegency, Name: "Ogan Ilir"},
+	{ID: 1609, ProvinceCode: "16", Type: TypeRegency, Name: "Ogan Komering Ilir"},
+	{ID: 1610, ProvinceCode: "16", Type: TypeRegency, Name: "Ogan Komering Ulu"},
+	{ID: 1611, ProvinceCode: "16", Type: TypeRegency, Name: "Ogan Komering Ulu Selatan"},
+	{ID: 1612, ProvinceCode: "16", Type: TypeRegency, Name: "Ogan Komering Ulu Timur"},
+	{ID: 1613, ProvinceCode: "16", Type: TypeRegency, Name: "Penukal Abab Lematang Ilir"},
+	{ID: 1671, ProvinceCode: "16", Type: TypeCity, Name: "Lubuklinggau"},
+	{ID: 1672, ProvinceCode: "16", Type: TypeCity, Name: "Pagar Alam"},
+	{ID: 1673, ProvinceCode: "16", Type: TypeCity, Name: "Palembang"},
+	{ID: 1674, ProvinceCode: "16", Type: TypeCity, Name: "Prabumulih"},
+	// Bengkulu
+	{ID: 1701, ProvinceCode: "17", Type: TypeRegency, Name: "Bengkulu Selatan"},
+	{ID: 1702, ProvinceCode: "17", Type: TypeRegency, Name: "Bengkulu Tengah"},
+	{ID: 1703, ProvinceCode: "17", Type: TypeRegency, Name: "Bengkulu Utara"},
+	{ID: 1704, ProvinceCode: "17", Type: TypeRegency, Name: "Kaur"},
+	{ID: 1705, ProvinceCode: "17", Type: TypeRegency, Name: "Kepahiang"},
+	{ID: 1706, ProvinceCode: "17", Type: TypeRegency, Name: "Lebong"},
+	{ID: 1707, ProvinceCode: "17", Type: TypeRegency, Name: "Mukomuko"},
+	{ID: 1708, ProvinceCode: "17", Type: TypeRegency, Name: "Rejang Lebong"},
+	{ID: 1709, ProvinceCode: "17", Type: TypeRegency, Name: "Seluma"},
+	{ID: 1771, ProvinceCode: "17", Type: TypeCity, Name: "Bengkulu"},
+	// Lampung
+	{ID: 1801, ProvinceCode: "18", Type: TypeRegency, Name: "Lampung Barat"},
+	{ID: 1802, ProvinceCode: "18", Type: TypeRegency, Name: "Lampung Selatan"},
+	{ID: 1803, ProvinceCode: "18", Type: TypeRegency, Name: "Lampung Tengah"},
+	{ID: 1804, ProvinceCode: "18", Type: TypeRegency, Name: "Lampung Timur"},
+	{ID: 1805, ProvinceCode: "18", Type: TypeRegency, Name: "Lampung Utara"},
+	{ID: 1806, ProvinceCode: "18", Type: TypeRegency, Name: "Mesuji"},
+	{ID: 1807, ProvinceCode: "18", Type: TypeRegency, Name: "Pesawaran"},
+	{ID: 1808, ProvinceCode: "18", Type: TypeRegency, Name: "Pesisir Barat"},
+	{ID: 1809, ProvinceCode: "18", Type: TypeRegency, Name: "Pringsewu"},
+	{ID: 1810, ProvinceCode: "18", Type: TypeRegency, Name: "Tanggamus"},
+	{ID: 1811, ProvinceCode: "18", Type: TypeRegency, Name: "Tulang Bawang"},
+	{ID: 1812, ProvinceCode: "18", Type: TypeRegency, Name: "Tulang Bawang Barat"},
+	{ID: 1813, ProvinceCode: "18", Type: TypeRegency, Name: "Way Kanan"},
+	{ID: 1871, ProvinceCode: "18", Type: TypeCity, Name: "Bandar Lampung"},
+	{ID: 1872, ProvinceCode: "18", Type: TypeCity, Name: "Metro"},
+	// Kepulauan Bangka Belitung
+	{ID: 1901, ProvinceCode: "19", Type: TypeRegency, Name: "Bangka"},
+	{ID: 1902, ProvinceCode: "19", Type: TypeRegency, Name: "Bangka Barat"},
+	{ID: 1903, ProvinceCode: "19", Type: TypeRegency, Name: "Bangka Selatan"},
+	{ID: 1904, ProvinceCode: "19", Type: TypeRegency, Name: "Bangka Tengah"},
+	{ID: 1905, ProvinceCode: "19", Type: TypeRegency, Name: "Belitung"},
+	{ID: 1906, ProvinceCode: "19", Type: TypeRegency, Name: "Belitung Timur"},
+	{ID: 1971, ProvinceCode: "19", Type: TypeCity, Name: "Pangkalpinang"},
+	// Kepulauan Riau
+	{ID: 2101, ProvinceCode: "21", Type: TypeRegency, Name: "Bintan"},
+	{ID: 2102, ProvinceCode: "21", Type: TypeRegency, Name: "Karimun"},
+	{ID: 2103, ProvinceCode: "21", Type: TypeRegency, Name: "Kepulauan Anambas"},
+	{ID: 2104, ProvinceCode: "21", Type: TypeRegency, Name: "Lingga"},
+	{ID: 2105, ProvinceCode: "21", Type: TypeRegency, Name: "Natuna"},
+	{ID: 2171, ProvinceCode: "21", Type: TypeCity, Name: "Batam"},
+	{ID: 2172, ProvinceCode: "21", Type: TypeCity, Name: "Tanjungpinang"},
+	// DKI Jakarta
+	{ID: 3101, ProvinceCode: "31", Type: TypeRegency, Name: "Kepulauan Seribu"},
+	{ID: 3171, ProvinceCode: "31", Type: TypeCity, Name: "Jakarta Barat"},
+	{ID: 3172, ProvinceCode: "31", Type: TypeCity, Name: "Jakarta Pusat"},
+	{ID: 3173, ProvinceCode: "31", Type: TypeCity, Name: "Jakarta Selatan"},
+	{ID: 3174, ProvinceCode: "31", Type: TypeCity, Name: "Jakarta Timur"},
+	{ID: 3175, ProvinceCode: "31", Type: TypeCity, Name: "Jakarta Utara"},
+	// Jawa Barat
+	{ID: 3201, ProvinceCode: "32", Type: TypeRegency, Name: "Bandung"},
+	{ID: 3202, ProvinceCode: "32", Type: TypeRegency, Name: "Bandung Barat"},
+	{ID: 3203, ProvinceCode: "32", Type: TypeRegency, Name: "Bekasi"},
+	{ID: 3204, ProvinceCode: "32", Type: TypeRegency, Name: "Bogor"},
+	{ID: 3205, ProvinceCode: "32", Type: TypeRegency, Name: "Ciamis"},
+	{ID: 3206, ProvinceCode: "32", Type: TypeRegency, Name: "Cianjur"},
+	{ID: 3207, ProvinceCode: "32", Type: TypeRegency, Name: "Cirebon"},
+	{ID: 3208, ProvinceCode: "32", Type: TypeRegency, Name: "Garut"},
+	{ID: 3209, ProvinceCode: "32", Type: TypeRegency, Name: "Indramayu"},
+	{ID: 3210, ProvinceCode: "32", Type: TypeRegency, Name: "Karawang"},
+	{ID: 3211, ProvinceCode: "32", Type: TypeRegency, Name: "Kuningan"},
+	{ID: 3212, ProvinceCode: "32", Type: TypeRegency, Name: "Majalengka"},
+	{ID: 3213, ProvinceCode: "32", Type: TypeRegency, Name: "Pangandaran"},
+	{ID: 3214, ProvinceCode: "32", Type: TypeRegency, Name: "Purwakarta"},
+	{ID: 3215, ProvinceCode: "32", Type: TypeRegency, Name: "Subang"},
+	{ID: 3216, ProvinceCode: "32", Type: TypeRegency, Name: "Sukabumi"},
+	{ID: 3217, ProvinceCode: "32", Type: TypeRegency, Name: "Sumedang"},
+	{ID: 3218, ProvinceCode: "32", Type: TypeRegency, Name: "Tasikmalaya"},
+	{ID: 3271, ProvinceCode: "32", Type: TypeCity, Name: "Bandung"},
+	{ID: 3272, ProvinceCode: "32", Type: TypeCity, Name: "Banjar"},
+	{ID: 3273, ProvinceCode: "32", Type: TypeCity, Name: "Bekasi"},
+	{ID: 3274, ProvinceCode: "32", Type: TypeCity, Name: "Bogor"},
+	{ID: 3275, ProvinceCode: "32", Type: TypeCity, Name: "Cimahi"},
+	{ID: 3276, ProvinceCode: "32", Type: TypeCity, Name: "Cirebon"},
+	{ID: 3277, ProvinceCode: "32", Type: TypeCity, Name: "Depok"},
+	{ID: 3278, ProvinceCode: "32", Type: TypeCity, Name: "Sukabumi"},
+	{ID: 3279, ProvinceCode: "32", Type: TypeCity, Name: "Tasikmalaya"},
+	// Jawa Tengah
+	{ID: 3301, ProvinceCode: "33", Type: TypeRegency, Name: "Banjarnegara"},
+	{ID: 3302, ProvinceCode: "33", Type: TypeRegency, Name: "Banyumas"},
+	{ID: 3303, ProvinceCode: "33", Type: TypeRegency, Name: "Batang"},
+	{ID: 3304, ProvinceCode: "33", Type: TypeRegency, Name: "Blora"},
+	{ID: 3305, ProvinceCode: "33", Type: TypeRegency, Name: "Boyolali"},
+	{ID: 3306, ProvinceCode: "33", Type: TypeRegency, Name: "Brebes"},
+	{ID: 3307, ProvinceCode: "33", Type: TypeRegency, Name: "Cilacap"},
+	{ID: 3308, ProvinceCode: "33", Type: TypeRegency, Name: "Demak"},
+	{ID: 3309, ProvinceCode: "33", Type: TypeRegency, Name: "Grobogan"},
+	{ID: 3310, ProvinceCode: "33", Type: TypeRegency, Name: "Jepara"},
+	{ID: 3311, ProvinceCode: "33", Type: TypeRegency, Name: "Karanganyar"},
+	{ID: 3312, ProvinceCode: "33", Type: TypeRegency, Name: "Kebumen"},
+	{ID: 3313, ProvinceCode: "33", Type: TypeRegency, Name: "Kendal"},
+	{ID: 3314, ProvinceCode: "33", Type: TypeRegency, Name: "Klaten"},
+	{ID: 3315, ProvinceCode: "33", Type: TypeRegency, Name: "Kudus"},
+	{ID: 3316, ProvinceCode: "33", Type: TypeRegency, Name: "Magelang"},
+	{ID: 3317, ProvinceCode: "33", Type: TypeRegency, Name: "Pati"},
+	{ID: 3318, ProvinceCode: "33", Type: TypeRegency, Name: "Pekalongan"},
+	{ID: 3319, ProvinceCode: "33", Type: TypeRegency, Name: "Pemalang"},
+	{ID: 3320, ProvinceCode: "33", Type: TypeRegency, Name: "Purbalingga"},
+	{ID: 3321, ProvinceCode: "33", Type: TypeRegency, Name: "Purworejo"},
+	{ID: 3322, ProvinceCode: "33", Type: TypeRegency, Name: "Rembang"},
+	{ID: 3323, ProvinceCode: "33", Type: TypeRegency, Name: "Semarang"},
+	{ID: 3324, ProvinceCode: "33", Type: TypeRegency, Name: "Sragen"},
+	{ID: 3325, ProvinceCode: "33", Type: TypeRegency, Name: "Sukoharjo"},
+	{ID: 3326, ProvinceCode: "33", Type: TypeRegency, Name: "Tegal"},
+	{ID: 3327, ProvinceCode: "33", Type: TypeRegency, Name: "Temanggung"},
+	{ID: 3328, ProvinceCode: "33", Type: TypeRegency, Name: "Wonogiri"},
+	{ID: 3329, ProvinceCode: "33", Type: TypeRegency, Name: "Wonosobo"},
+	{ID: 3371, ProvinceCode: "33", Type: TypeCity, Name: "Magelang"},
+	{ID: 3372, ProvinceCode: "33", Type: TypeCity, Name: "Pekalongan"},
+	{ID: 3373, ProvinceCode: "33", Type: TypeCity, Name: "Salatiga"},
+	{ID: 3374, ProvinceCode: "33", Type: TypeCity, Name: "Semarang"},
+	{ID: 3375, ProvinceCode: "33", Type: TypeCity, Name: "Surakarta"},
+	{ID: 3376, ProvinceCode: "33", Type: TypeCity, Name: "Tegal"},
+	// DI Yogyakarta
+	{ID: 3401, ProvinceCode: "34", Type: TypeRegency, Name: "Bantul"},
+	{ID: 3402, ProvinceCode: "34", Type: TypeRegency, Name: "Gunungkidul"},
+	{ID: 3403, ProvinceCode: "34", Type: TypeRegency, Name: "Kulon Progo"},
+	{ID: 3404, ProvinceCode: "34", Type: TypeRegency, Name: "Sleman"},
+	{ID: 3471, ProvinceCode: "34", Type: TypeCity, Name: "Yogyakarta"},
+	// Jawa Timur
+	{ID: 3501, ProvinceCode: "35", Type: TypeRegency, Name: "Bangkalan"},
+	{ID: 3502, ProvinceCode: "35", Type: TypeRegency, Name: "Banyuwangi"},
+	{ID: 3503, ProvinceCode: "35", Type: TypeRegency, Name: "Blitar"},
+	{ID: 3504, ProvinceCode: "35", Type: TypeRegency, Name: "Bojonegoro"},
+	{ID: 3505, ProvinceCode: "35", Type: TypeRegency, Name: "Bondowoso"},
+	{ID: 3506, ProvinceCode: "35", Type: TypeRegency, Name: "Gresik"},
+	{ID: 3507, ProvinceCode: "35", Type: TypeRegency, Name: "Jember"},
+	{ID: 3508, ProvinceCode: "35", Type: TypeRegency, Name: "Jombang"},
+	{ID: 3509, ProvinceCode: "35", Type: TypeRegency, Name: "Kediri"},
+	{ID: 3510, ProvinceCode: "35", Type: TypeRegency, Name: "Lamongan"},
+	{ID: 3511, ProvinceCode: "35", Type: TypeRegency, Name: "Lumajang"},
+	{ID: 3512, ProvinceCode: "35", Type: TypeRegency, Name: "Madiun"},
+	{ID: 3513, ProvinceCode: "35", Type: TypeRegency, Name: "Magetan"},
+	{ID: 3514, ProvinceCode: "35", Type: TypeRegency, Name: "Malang"},
+	{ID: 3515, ProvinceCode: "35", Type: TypeRegency, Name: "Mojokerto"},
+	{ID: 3516, ProvinceCode: "35", Type: TypeRegency, Name: "Nganjuk"},
+	{ID: 3517, ProvinceCode: "35", Type: TypeRegency, Name: "Ngawi"},
+	{ID: 3518, ProvinceCode: "35", Type: TypeRegency, Name: "Pacitan"},
+	{ID: 3519, ProvinceCode: "35", Type: TypeRegency, Name: "Pamekasan"},
+	{ID: 3520, ProvinceCode: "35", Type: TypeRegency, Name: "Pasuruan"},
+	{ID: 3521, ProvinceCode: "35", Type: TypeRegency, Name: "Ponorogo"},
+	{ID: 3522, ProvinceCode: "35", Type: TypeRegency, Name: "Probolinggo"},
+	{ID: 3523, ProvinceCode: "35", Type: TypeRegency, Name: "Sampang"},
+	{ID: 3524, ProvinceCode: "35", Type: TypeRegency, Name: "Sidoarjo"},
+	{ID: 3525, ProvinceCode: "35", Type: TypeRegency, Name: "Situbondo"},
+	{ID: 3526, ProvinceCode: "35", Type: TypeRegency, Name: "Sumenep"},
+	{ID: 3527, ProvinceCode: "35", Type: TypeRegency, Name: "Trenggalek"},
+	{ID: 3528, ProvinceCode: "35", Type: TypeRegency, Name: "Tuban"},
+	{ID: 3529, ProvinceCode: "35", Type: TypeRegency, Name: "Tulungagung"},
+	{ID: 3571, ProvinceCode: "35", Type: TypeCity, Name: "Batu"},
+	{ID: 3572, ProvinceCode: "35", Type: TypeCity, Name: "Blitar"},
+	{ID: 3573, ProvinceCode: "35", Type: TypeCity, Name: "Kediri"},
+	{ID: 3574, ProvinceCode: "35", Type: TypeCity, Name: "Madiun"},
+	{ID: 3575, ProvinceCode: "35", Type: TypeCity, Name: "Malang"},
+	{ID: 3576, ProvinceCode: "35", Type: TypeCity, Name: "Mojokerto"},
+	{ID: 3577, ProvinceCode: "35", Type: TypeCity, Name: "Pasuruan"},
+	{ID: 3578, ProvinceCode: "35", Type: TypeCity, Name: "Probolinggo"},
+	{ID: 3579, ProvinceCode: "35", Type: TypeCity, Name: "Surabaya"},
+	// Banten
+	{ID: 3601, ProvinceCode: "36", Type: TypeRegency, Name: "Lebak"},
+	{ID: 3602, ProvinceCode: "36", Type: TypeRegency, Name: "Pandeglang"},
+	{ID: 3603, ProvinceCode: "36", Type: TypeRegency, Name: "Serang"},
+	{ID: 3604, ProvinceCode: "36", Type: TypeRegency, Name: "Tangerang"},
+	{ID: 3671, ProvinceCode: "36", Type: TypeCity, Name: "Cilegon"},
+	{ID: 3672, ProvinceCode: "36", Type: TypeCity, Name: "Serang"},
+	{ID: 3673, ProvinceCode: "36", Type: TypeCity, Name: "Tangerang"},
+	{ID: 3674, ProvinceCode: "36", Type: TypeCity, Name: "Tangerang Selatan"},
+	// Bali
+	{ID: 5101, ProvinceCode: "51", Type: TypeRegency, Name: "Badung"},
+	{ID: 5102, ProvinceCode: "51", Type: TypeRegency, Name: "Bangli"},
+	{ID: 5103, ProvinceCode: "51", Type: TypeRegency, Name: "Buleleng"},
+	{ID: 5104, ProvinceCode: "51", Type: TypeRegency, Name: "Gianyar"},
+	{ID: 5105, ProvinceCode: "51", Type: TypeRegency, Name: "Jembrana"},
+	{ID: 5106, ProvinceCode: "51", Type: TypeRegency, Name: "Karangasem"},
+	{ID: 5107, ProvinceCode: "51", Type: TypeRegency, Name: "Klungkung"},
+	{ID: 5108, ProvinceCode: "51", Type: TypeRegency, Name: "Tabanan"},
+	{ID: 5171, ProvinceCode: "51", Type: TypeCity, Name: "Denpasar"},
+	// Nusa Tenggara Barat
+	{ID: 5201, ProvinceCode: "52", Type: TypeRegency, Name: "Bima"},
+	{ID: 5202, ProvinceCode: "52", Type: TypeRegency, Name: "Dompu"},
+	{ID: 5203, ProvinceCode: "52", Type: TypeRegency, Name: "Lombok Barat"},
+	{ID: 5204, ProvinceCode: "52", Type: TypeRegency, Name: "Lombok Tengah"},
+	{ID: 5205, ProvinceCode: "52", Type: TypeRegency, Name: "Lombok Timur"},
+	{ID: 5206, ProvinceCode: "52", Type: TypeRegency, Name: "Lombok Utara"},
+	{ID: 5207, ProvinceCode: "52", Type: TypeRegency, Name: "Sumbawa"},
+	{ID: 5208, ProvinceCode: "52", Type: TypeRegency, Name: "Sumbawa Barat"},
+	{ID: 5271, ProvinceCode: "52", Type: TypeCity, Name: "Bima"},
+	{ID: 5272, ProvinceCode: "52", Type: TypeCity, Name: "Mataram"},
+	// Nusa Tenggara Timur
+	{ID: 5301, ProvinceCode: "53", Type: TypeRegency, Name: "Alor"},
+	{ID: 5302, ProvinceCode: "53", Type: TypeRegency, Name: "Belu"},
+	{ID: 5303, ProvinceCode: "53", Type: TypeRegency, Name: "Ende"},
+	{ID: 5304, ProvinceCode: "53", Type: TypeRegency, Name: "Flores Timur"},
+	{ID: 5305, ProvinceCode: "53", Type: TypeRegency, Name: "Kupang"},
+	{ID: 5306, ProvinceCode: "53", Type: TypeRegency, Name: "Lembata"},
+	{ID: 5307, ProvinceCode: "53", Type: TypeRegency, Name: "Malaka"},
+	{ID: 5308, ProvinceCode: "53", Type: TypeRegency, Name: "Manggarai"},
+	{ID: 5309, ProvinceCode: "53", Type: TypeRegency, Name: "Manggarai Barat"},
+	{ID: 5310, ProvinceCode: "53", Type: TypeRegency, Name: "Manggarai Timur"},
+	{ID: 5311, ProvinceCode: "53", Type: TypeRegency, Name: "Nagekeo"},
+	{ID: 5312, ProvinceCode: "53", Type: TypeRegency, Name: "Ngada"},
+	{ID: 5313, ProvinceCode: "53", Type: TypeRegency, Name: "Rote Ndao"},
+	{ID: 5314, ProvinceCode: "53", Type: TypeRegency, Name: "Sabu Raijua"},
+	{ID: 5315, ProvinceCode: "53", Type: TypeRegency, Name: "Sikka"},
+	{ID: 5316, ProvinceCode: "53", Type: TypeRegency, Name: "Sumba Barat"},
+	{ID: 5317, ProvinceCode: "53", Type: TypeRegency, Name: "Sumba Barat Daya"},
+	{ID: 5318, ProvinceCode: "53", Type: TypeRegency, Name: "Sumba Tengah"},
+	{ID: 5319, ProvinceCode: "53", Type: TypeRegency, Name: "Sumba Timur"},
+	{ID: 5320, ProvinceCode: "53", Type: TypeRegency, Name: "Timor Tengah Selatan"},
+	{ID: 5321, ProvinceCode: "53", Type: TypeRegency, Name: "Timor Tengah Utara"},
+	{ID: 5371, ProvinceCode: "53", Type: TypeCity, Name: "Kupang"},
+	// Kalimantan Barat
+	{ID: 6101, ProvinceCode: "61", Type: TypeRegency, Name: "Bengkayang"},
+	{ID: 6102, ProvinceCode: "61", Type: TypeRegency, Name: "Kapuas Hulu"},
+	{ID: 6103, ProvinceCode: "61", Type: TypeRegency, Name: "Kayong Utara"},
+	{ID: 6104, ProvinceCode: "61", Type: TypeRegency, Name: "Ketapang"},
+	{ID: 6105, ProvinceCode: "61", Type: TypeRegency, Name: "Kubu Raya"},
+	{ID: 6106, ProvinceCode: "61", Type: TypeRegency, Name: "Landak"},
+	{ID: 6107, ProvinceCode: "61", Type: TypeRegency, Name: "Melawi"},
+	{ID: 6108, ProvinceCode: "61", Type: TypeRegency, Name: "Mempawah"},
+	{ID: 6109, ProvinceCode: "61", Type: TypeRegency, Name: "Sambas"},
+	{ID: 6110, ProvinceCode: "61", Type: TypeRegency, Name: "Sanggau"},
+	{ID: 6111, ProvinceCode: "61", Type: TypeRegency, Name: "Sekadau"},
+	{ID: 6112, ProvinceCode: "61", Type: TypeRegency, Name: "Sintang"},
+	{ID: 6171, ProvinceCode: "61", Type: TypeCity, Name: "Pontianak"},
+	{ID: 6172, ProvinceCode: "61", Type: TypeCity, Name: "Singkawang"},
+	// Kalimantan Tengah
+	{ID: 6201, ProvinceCode: "62", Type: TypeRegency, Name: "Barito Selatan"},
+	{ID: 6202, ProvinceCode: "62", Type: TypeRegency, Name: "Barito Timur"},
+	{ID: 6203, ProvinceCode: "62", Type: TypeRegency, Name: "Barito Utara"},
+	{ID: 6204, ProvinceCode: "62", Type: TypeRegency, Name: "Gunung Mas"},
+	{ID: 6205, ProvinceCode: "62", Type: TypeRegency, Name: "Kapuas"},
+	{ID: 6206, ProvinceCode: "62", Type: TypeRegency, Name: "Katingan"},
+	{ID: 6207, ProvinceCode: "62", Type: TypeRegency, Name: "Kotawaringin Barat"},
+	{ID: 6208, ProvinceCode: "62", Type: TypeRegency, Name: "Kotawaringin Timur"},
+	{ID: 6209, ProvinceCode: "62", Type: TypeRegency, Name: "Lamandau"},
+	{ID: 6210, ProvinceCode: "62", Type: TypeRegency, Name: "Murung Raya"},
+	{ID: 6211, ProvinceCode: "62", Type: TypeRegency, Name: "Pulang Pisau"},
+	{ID: 6212, ProvinceCode: "62", Type: TypeRegency, Name: "Seruyan"},
+	{ID: 6213, ProvinceCode: "62", Type: TypeRegency, Name: "Sukamara"},
+	{ID: 6271, ProvinceCode: "62", Type: TypeCity, Name: "Palangka Raya"},
+	// Kalimantan Selatan
+	{ID: 6301, ProvinceCode: "63", Type: TypeRegency, Name: "Balangan"},
+	{ID: 6302, ProvinceCode: "63", Type: TypeRegency, Name: "Banjar"},
+	{ID: 6303, ProvinceCode: "63", Type: TypeRegency, Name: "Barito Kuala"},
+	{ID: 6304, ProvinceCode: "63", Type: TypeRegency, Name: "Hulu Sungai Selatan"},
+	{ID: 6305, ProvinceCode: "63", Type: TypeRegency, Name: "Hulu Sungai Tengah"},
+	{ID: 6306, ProvinceCode: "63", Type: TypeRegency, Name: "Hulu Sungai Utara"},
+	{ID: 6307, ProvinceCode: "63", Type: TypeRegency, Name: "Kotabaru"},
+	{ID: 6308, ProvinceCode: "63", Type: TypeRegency, Name: "Tabalong"},
+	{ID: 6309, ProvinceCode: "63", Type: TypeRegency, Name: "Tanah Bumbu"},
+	{ID: 6310, ProvinceCode: "63", Type: TypeRegency, Name: "Tanah Laut"},
+	{ID: 6311, ProvinceCode: "63", Type: TypeRegency, Name: "Tapin"},
+	{ID: 6371, ProvinceCode: "63", Type: TypeCity, Name: "Banjarbaru"},
+	{ID: 6372, ProvinceCode: "63", Type: TypeCity, Name: "Banjarmasin"},
+	// Kalimantan Timur
+	{ID: 6401, ProvinceCode: "64", Type: TypeRegency, Name: "Berau"},
+	{ID: 6402, ProvinceCode: "64", Type: TypeRegency, Name: "Kutai Barat"},
+	{ID: 6403, ProvinceCode: "64", Type: TypeRegency, Name: "Kutai Kartanegara"},
+	{ID: 6404, ProvinceCode: "64", Type: TypeRegency, Name: "Kutai Timur"},
+	{ID: 6405, ProvinceCode: "64", Type: TypeRegency, Name: "Mahakam Ulu"},
+	{ID: 6406, ProvinceCode: "64", Type: TypeRegency, Name: "Paser"},
+	{ID: 6407, ProvinceCode: "64", Type: TypeRegency, Name: "Penajam Paser Utara"},
+	{ID: 6471, ProvinceCode: "64", Type: TypeCity, Name: "Balikpapan"},
+	{ID: 6472, ProvinceCode: "64", Type: TypeCity, Name: "Bontang"},
+	{ID: 6473, ProvinceCode: "64", Type: TypeCity, Name: "Samarinda"},
+	// Kalimantan Utara
+	{ID: 6501, ProvinceCode: "65", Type: TypeRegency, Name: "Bulungan"},
+	{ID: 6502, ProvinceCode: "65", Type: TypeRegency, Name: "Malinau"},
+	{ID: 6503, ProvinceCode: "65", Type: TypeRegency, Name: "Nunukan"},
+	{ID: 6504, ProvinceCode: "65", Type: TypeRegency, Name: "Tana Tidung"},
+	{ID: 6571, ProvinceCode: "65", Type: TypeCity, Name: "Tarakan"},
+	// Sulawesi Utara
+	{ID: 7101, ProvinceCode: "71", Type: TypeRegency, Name: "Bolaang Mongondow"},
+	{ID: 7102, ProvinceCode: "71", Type: TypeRegency, Name: "Bolaang Mongondow Selatan"},
+	{ID: 7103, ProvinceCode: "71", Type: TypeRegency, Name: "Bolaang Mongondow Timur"},
+	{ID: 7104, ProvinceCode: "71", Type: TypeRegency, Name: "Bolaang Mongondow Utara"},
+	{ID: 7105, ProvinceCode: "71", Type: TypeRegency, Name: "Kepulauan Sangihe"},
+	{ID: 7106, ProvinceCode: "71", Type: TypeRegency, Name: "Kepulauan Siau Tagulandang Biaro"},
+	{ID: 7107, ProvinceCode: "71", Type: TypeRegency, Name: "Kepulauan Talaud"},
+	{ID: 7108, ProvinceCode: "71", Type: TypeRegency, Name: "Minahasa"},
+	{ID: 7109, ProvinceCode: "71", Type: TypeRegency, Name: "Minahasa Selatan"},
+	{ID: 7110, ProvinceCode: "71", Type: TypeRegency, Name: "Minahasa Tenggara"},
+	{ID: 7111, ProvinceCode: "71", Type: TypeRegency, Name: "Minahasa Utara"},
+	{ID: 7171, ProvinceCode: "71", Type: TypeCity, Name: "Bitung"},
+	{ID: 7172, ProvinceCode: "71", Type: TypeCity, Name: "Kotamobagu"},
+	{ID: 7173, ProvinceCode: "71", Type: TypeCity, Name: "Manado"},
+	{ID: 7174, ProvinceCode: "71", Type: TypeCity, Name: "Tomohon"},
+	// Sulawesi Tengah
+	{ID: 7201, ProvinceCode: "72", Type: TypeRegency, Name: "Banggai"},
+	{ID: 7202, ProvinceCode: "72", Type: TypeRegency, Name: "Banggai Kepulauan"},
+	{ID: 7203, ProvinceCode: "72", Type: TypeRegency, Name: "Banggai Laut"},
+	{ID: 7204, ProvinceCode: "72", Type: TypeRegency, Name: "Buol"},
+	{ID: 7205, ProvinceCode: "72", Type: TypeRegency, Name: "Donggala"},
+	{ID: 7206, ProvinceCode: "72", Type: TypeRegency, Name: "Morowali"},
+	{ID: 7207, ProvinceCode: "72", Type: TypeRegency, Name: "Morowali Utara"},
+	{ID: 7208, ProvinceCode: "72", Type: TypeRegency, Name: "Parigi Moutong"},
+	{ID: 7209, ProvinceCode: "72", Type: TypeRegency, Name: "Poso"},
+	{ID: 7210, ProvinceCode: "72", Type: TypeRegency, Name: "Sigi"},
+	{ID: 7211, ProvinceCode: "72", Type: TypeRegency, Name: "Tojo Una-Una"},
+	{ID: 7212, ProvinceCode: "72", Type: TypeRegency, Name: "Tolitoli"},
+	{ID: 7271, ProvinceCode: "72", Type: TypeCity, Name: "Palu"},
+	// Sulawesi Selatan
+	{ID: 7301, ProvinceCode: "73", Type: TypeRegency, Name: "Bantaeng"},
+	{ID: 7302, ProvinceCode: "73", Type: TypeRegency, Name: "Barru"},
+	{ID: 7303, ProvinceCode: "73", Type: TypeRegency, Name: "Bone"},
+	{ID: 7304, ProvinceCode: "73", Type: TypeRegency, Name: "Bulukumba"},
+	{ID: 7305, ProvinceCode: "73", Type: TypeRegency, Name: "Enrekang"},
+	{ID: 7306, ProvinceCode: "73", Type: TypeRegency, Name: "Gowa"},
+	{ID: 7307, ProvinceCode: "73", Type: TypeRegency, Name: "Jeneponto"},
+	{ID: 7308, ProvinceCode: "73", Type: TypeRegency, Name: "Kepulauan Selayar"},
+	{ID: 7309, ProvinceCode: "73", Type: TypeRegency, Name: "Luwu"},
+	{ID: 7310, ProvinceCode: "73", Type: TypeRegency, Name: "Luwu Timur"},
+	{ID: 7311, ProvinceCode: "73", Type: TypeRegency, Name: "Luwu Utara"},
+	{ID: 7312, ProvinceCode: "73", Type: TypeRegency, Name: "Maros"},
+	{ID: 7313, ProvinceCode: "73", Type: TypeRegency, Name: "Pangkajene dan Kepulauan"},
+	{ID: 7314, ProvinceCode: "73", Type: TypeRegency, Name: "Pinrang"},
+	{ID: 7315, ProvinceCode: "73", Type: TypeRegency, Name: "Sidenreng Rappang"},
+	{ID: 7316, ProvinceCode: "73", Type: TypeRegency, Name: "Sinjai"},
+	{ID: 7317, ProvinceCode: "73", Type: TypeRegency, Name: "Soppeng"},
+	{ID: 7318, ProvinceCode: "73", Type: TypeRegency, Name: "Takalar"},
+	{ID: 7319, ProvinceCode: "73", Type: TypeRegency, Name: "Tana Toraja"},
+	{ID: 7320, ProvinceCode: "73", Type: TypeRegency, Name: "Toraja Utara"},
+	{ID: 7321, ProvinceCode: "73", Type: TypeRegency, Name: "Wajo"},
+	{ID: 7371, ProvinceCode: "73", Type: TypeCity, Name: "Makassar"},
+	{ID: 7372, ProvinceCode: "73", Type: TypeCity, Name: "Palopo"},
+	{ID: 7373, ProvinceCode: "73", Type: TypeCity, Name: "Parepare"},
+	// Sulawesi Tenggara
+	{ID: 7401, ProvinceCode: "74", Type: TypeRegency, Name: "Bombana"},
+	{ID: 7402, ProvinceCode: "74", Type: TypeRegency, Name: "Buton"},
+	{ID: 7403, ProvinceCode: "74", Type: TypeRegency, Name: "Buton Selatan"},
+	{ID: 7404, ProvinceCode: "74", Type: TypeRegency, Name: "Buton Tengah"},
+	{ID: 7405, ProvinceCode: "74", Type: TypeRegency, Name: "Buton Utara"},
+	{ID: 7406, ProvinceCode: "74", Type: TypeRegency, Name: "Kolaka"},
+	{ID: 7407, ProvinceCode: "74", Type: TypeRegency, Name: "Kolaka Timur"},
+	{ID: 7408, ProvinceCode: "74", Type: TypeRegency, Name: "Kolaka Utara"},
+	{ID: 7409, ProvinceCode: "74", Type: TypeRegency, Name: "Konawe"},
+	{ID: 7410, ProvinceCode: "74", Type: TypeRegency, Name: "Konawe Kepulauan"},
+	{ID: 7411, ProvinceCode: "74", Type: TypeRegency, Name: "Konawe Selatan"},
+	{ID: 7412, ProvinceCode: "74", Type: TypeRegency, Name: "Konawe Utara"},
+	{ID: 7413, ProvinceCode: "74", Type: TypeRegency, Name: "Muna"},
+	{ID: 7414, ProvinceCode: "74", Type: TypeRegency, Name: "Muna Barat"},
+	{ID: 7415, ProvinceCode: "74", Type: TypeRegency, Name: "Wakatobi"},
+	{ID: 7471, ProvinceCode: "74", Type: TypeCity, Name: "Baubau"},
+	{ID: 7472, ProvinceCode: "74", Type: TypeCity, Name: "Kendari"},
+	// Gorontalo
+	{ID: 7501, ProvinceCode: "75", Type: TypeRegency, Name: "Boalemo"},
+	{ID: 7502, ProvinceCode: "75", Type: TypeRegency, Name: "Bone Bolango"},
+	{ID: 7503, ProvinceCode: "75", Type: TypeRegency, Name: "Gorontalo"},
+	{ID: 7504, ProvinceCode: "75", Type: TypeRegency, Name: "Gorontalo Utara"},
+	{ID: 7505, ProvinceCode: "75", Type: TypeRegency, Name: "Pohuwato"},
+	{ID: 7571, ProvinceCode: "75", Type: TypeCity, Name: "Gorontalo"},
+	// Sulawesi Barat
+	{ID: 7601, ProvinceCode: "76", Type: TypeRegency, Name: "Majene"},
+	{ID: 7602, ProvinceCode: "76", Type: TypeRegency, Name: "Mamasa"},
+	{ID: 7603, ProvinceCode: "76", Type: TypeRegency, Name: "Mamuju"},
+	{ID: 7604, ProvinceCode: "76", Type: TypeRegency, Name: "Mamuju Tengah"},
+	{ID: 7605, ProvinceCode: "76", Type: TypeRegency, Name: "Pasangkayu"},
+	{ID: 7606, ProvinceCode: "76", Type: TypeRegency, Name: "Polewali Mandar"},
+	// Maluku
+	{ID: 8101, ProvinceCode: "81", Type: TypeRegency, Name: "Buru"},
+	{ID: 8102, ProvinceCode: "81", Type: TypeRegency, Name: "Buru Selatan"},
+	{ID: 8103, ProvinceCode: "81", Type: TypeRegency, Name: "Kepulauan Aru"},
+	{ID: 8104, ProvinceCode: "81", Type: TypeRegency, Name: "Kepulauan Tanimbar"},
+	{ID: 8105, ProvinceCode: "81", Type: TypeRegency, Name: "Maluku Barat Daya"},
+	{ID: 8106, ProvinceCode: "81", Type: TypeRegency, Name: "Maluku Tengah"},
+	{ID: 8107, ProvinceCode: "81", Type: TypeRegency, Name: "Maluku Tenggara"},
+	{ID: 8108, ProvinceCode: "81", Type: TypeRegency, Name: "Seram Bagian Barat"},
+	{ID: 8109, ProvinceCode: "81", Type: TypeRegency, Name: "Seram Bagian Timur"},
+	{ID: 8171, ProvinceCode: "81", Type: TypeCity, Name: "Ambon"},
+	{ID: 8172, ProvinceCode: "81", Type: TypeCity, Name: "Tual"},
+	// Maluku Utara
+	{ID: 8201, ProvinceCode: "82", Type: TypeRegency, Name: "Halmahera Barat"},
+	{ID: 8202, ProvinceCode: "82", Type: TypeRegency, Name: "Halmahera Selatan"},
+	{ID: 8203, ProvinceCode: "82", Type: TypeRegency, Name: "Halmahera Tengah"},
+	{ID: 8204, ProvinceCode: "82", Type: TypeRegency, Name: "Halmahera Timur"},
+	{ID: 8205, ProvinceCode: "82", Type: TypeRegency, Name: "Halmahera Utara"},
+	{ID: 8206, ProvinceCode: "82", Type: TypeRegency, Name: "Kepulauan Sula"},
+	{ID: 8207, ProvinceCode: "82", Type: TypeRegency, Name: "Pulau Morotai"},
+	{ID: 8208, ProvinceCode: "82", Type: TypeRegency, Name: "Pulau Taliabu"},
+	{ID: 8271, ProvinceCode: "82", Type: TypeCity, Name: "Ternate"},
+	{ID: 8272, ProvinceCode: "82", Type: TypeCity, Name: "Tidore Kepulauan"},
+	// Papua
+	{ID: 9101, ProvinceCode: "91", Type: TypeRegency, Name: "Biak Numfor"},
+	{ID: 9102, ProvinceCode: "91", Type: TypeRegency, Name: "Jayapura"},
+	{ID: 9103, ProvinceCode: "91", Type: TypeRegency, Name: "Keerom"},
+	{ID: 9104, ProvinceCode: "91", Type: TypeRegency, Name: "Kepulauan Yapen"},
+	{ID: 9105, ProvinceCode: "91", Type: TypeRegency, Name: "Mamberamo Raya"},
+	{ID: 9106, ProvinceCode: "91", Type: TypeRegency, Name: "Sarmi"},
+	{ID: 9107, ProvinceCode: "91", Type: TypeRegency, Name: "Supiori"},
+	{ID: 9108, ProvinceCode: "91", Type: TypeRegency, Name: "Waropen"},
+	{ID: 9171, ProvinceCode: "91", Type: TypeCity, Name: "Jayapura"},
+	// Papua Barat
+	{ID: 9201, ProvinceCode: "92", Type: TypeRegency, Name: "Fakfak"},
+	{ID: 9202, ProvinceCode: "92", Type: TypeRegency, Name: "Kaimana"},
+	{ID: 9203, ProvinceCode: "92", Type: TypeRegency, Name: "Manokwari"},
+	{ID: 9204, ProvinceCode: "92", Type: TypeRegency, Name: "Manokwari Selatan"},
+	{ID: 9205, ProvinceCode: "92", Type: TypeRegency, Name: "Pegunungan Arfak"},
+	{ID: 9206, ProvinceCode: "92", Type: TypeRegency, Name: "Teluk Bintuni"},
+	{ID: 9207, ProvinceCode: "92", Type: TypeRegency, Name: "Teluk Wondama"},
+	// Papua Selatan
+	{ID: 9301, ProvinceCode: "93", Type: TypeRegency, Name: "Asmat"},
+	{ID: 9302, ProvinceCode: "93", Type: TypeRegency, Name: "Boven Digoel"},
+	{ID: 9303, ProvinceCode: "93", Type: TypeRegency, Name: "Mappi"},
+	{ID: 9304, ProvinceCode: "93", Type: TypeRegency, Name: "Merauke"},
+	// Papua Tengah
+	{ID: 9401, ProvinceCode: "94", Type: TypeRegency, Name: "Deiyai"},
+	{ID: 9402, ProvinceCode: "94", Type: TypeRegency, Name: "Dogiyai"},
+	{ID: 9403, ProvinceCode: "94", Type: TypeRegency, Name: "Intan Jaya"},
+	{ID: 9404, ProvinceCode: "94", Type: TypeRegency, Name: "Mimika"},
+	{ID: 9405, ProvinceCode: "94", Type: TypeRegency, Name: "Nabire"},
+	{ID: 9406, ProvinceCode: "94", Type: TypeRegency, Name: "Paniai"},
+	{ID: 9407, ProvinceCode: "94", Type: TypeRegency, Name: "Puncak"},
+	{ID: 9408, ProvinceCode: "94", Type: TypeRegency, Name: "Puncak Jaya"},
+	// Papua Pegunungan
+	{ID: 9501, ProvinceCode: "95", Type: TypeRegency, Name: "Jayawijaya"},
+	{ID: 9502, ProvinceCode: "95", Type: TypeRegency, Name: "Lanny Jaya"},
+	{ID: 9503, ProvinceCode: "95", Type: TypeRegency, Name: "Mamberamo Tengah"},
+	{ID: 9504, ProvinceCode: "95", Type: TypeRegency, Name: "Nduga"},
+	{ID: 9505, ProvinceCode: "95", Type: TypeRegency, Name: "Pegunungan Bintang"},
+	{ID: 9506, ProvinceCode: "95", Type: TypeRegency, Name: "Tolikara"},
+	{ID: 9507, ProvinceCode: "95", Type: TypeRegency, Name: "Yahukimo"},
+	{ID: 9508, ProvinceCode: "95", Type: TypeRegency, Name: "Yalimo"},
+	// Papua Barat Daya
+	{ID: 9601, ProvinceCode: "96", Type: TypeRegency, Name: "Maybrat"},
+	{ID: 9602, ProvinceCode: "96", Type: TypeRegency, Name: "Raja Ampat"},
+	{ID: 9603, ProvinceCode: "96", Type: TypeRegency, Name: "Sorong"},
+	{ID: 9604, ProvinceCode: "96", Type: TypeRegency, Name: "Sorong Selatan"},
+	{ID: 9605, ProvinceCode: "96", Type: TypeRegency, Name: "Tambrauw"},
+	{ID: 9671, ProvinceCode: "96", Type: TypeCity, Name: "Sorong"},
+}
+
+// Aliases are the common other names of provinces and cities.
+var Aliases = []Alias{
+	provinceAlias("NAD", "11"),
+	provinceAlias("Nanggroe Aceh Darussalam", "11"),
+	provinceAlias("Sumut", "12"),
+	provinceAlias("Sumbar", "13"),
+	provinceAlias("Sumsel", "16"),
+	provinceAlias("Bangka Belitung", "19"),
+	provinceAlias("Babel", "19"),
+	provinceAlias("Kepri", "21"),
+	provinceAlias("Jakarta", "31"),
+	provinceAlias("DKI", "31"),
+	provinceAlias("Jkt", "31"),
+	provinceAlias("Daerah Khusus Ibukota Jakarta", "31"),
+	provinceAlias("Jabar", "32"),
+	provinceAlias("Jateng", "33"),
+	provinceAlias("Yogyakarta", "34"),
+	provinceAlias("DIY", "34"),
+	provinceAlias("Jogja", "34"),
+	provinceAlias("Jogjakarta", "34"),
+	provinceAlias("Daerah Istimewa Yogyakarta", "34"),
+	provinceAlias("Jatim", "35"),
+	provinceAlias("NTB", "52"),
+	provinceAlias("NTT", "53"),
+	provinceAlias("Kalbar", "61"),
+	provinceAlias("Kalteng", "62"),
+	provinceAlias("Kalsel", "63"),
+	provinceAlias("Kaltim", "64"),
+	provinceAlias("Kaltara", "65"),
+	provinceAlias("Sulut", "71"),
+	provinceAlias("Sulteng", "72"),
+	provinceAlias("Sulsel", "73"),
+	provinceAlias("Sultra", "74"),
+	provinceAlias("Sulbar", "76"),
+	provinceAlias("Malut", "82"),
+	cityAlias("Padang Sidempuan", 1274),
+	cityAlias("Siantar", 1275),
+	cityAlias("Jakbar", 3171),
+	cityAlias("Jakpus", 3172),
+	cityAlias("Jaksel", 3173),
+	cityAlias("Jaktim", 3174),
+	cityAlias("Jakut", 3175),
+	cityAlias("Bdg", 3271),
+	cityAlias("Smg", 3374),
+	cityAlias("Solo", 3375),
+	cityAlias("Jogja", 3471),
+	cityAlias("Jogjakarta", 3471),
+	cityAlias("Yogya", 3471),
+	cityAlias("Sby", 3579),
+	cityAlias("Tangsel", 3674),
+	cityAlias("Makasar", 7371),
+	cityAlias("Ujung Pandang", 7371),
+	cityAlias("Tidore", 8272),
+}
+
+func provinceAlias(alias, provinceCode string) Alias {
+	return Alias{Alias: alias, ProvinceCode: &provinceCode}
+}
+
+func cityAlias(alias string, cityID int) Alias {
+	return Alias{Alias: alias, CityID: &cityID}
+}
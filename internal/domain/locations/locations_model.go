@@ -0,0 +1,309 @@
+package locations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnknownProvince   = errors.New("unknown province")
+	ErrUnknownCity       = errors.New("unknown city")
+	ErrAmbiguousCity     = errors.New("ambiguous city")
+	ErrCityNotInProvince = errors.New("city is not in the province")
+)
+
+// City types: regencies (kabupaten) and cities (kota) are both second-level
+// divisions of a province.
+const (
+	TypeRegency = "regency"
+	TypeCity    = "city"
+)
+
+// Province is a province of the reference data.
+type Province struct {
+	Code string `db:"code" json:"code"`
+	Name string `db:"name" json:"name"`
+}
+
+// City is a regency or city of the reference data. FullName tells apart the
+// regencies and cities of the same name, such as Kabupaten Bandung and Kota
+// Bandung, and is what profiles store.
+type City struct {
+	ID           int    `db:"id" json:"id"`
+	ProvinceCode string `db:"province_code" json:"provinceCode"`
+	Type         string `db:"type" json:"type"`
+	Name         string `db:"name" json:"name"`
+	FullName     string `db:"-" json:"fullName"`
+}
+
+// Alias is another name of a province or a city, such as Jabar for Jawa
+// Barat or Solo for Surakarta. Exactly one of ProvinceCode and CityID is set.
+type Alias struct {
+	Alias        string  `db:"alias" json:"alias"`
+	ProvinceCode *string `db:"province_code" json:"provinceCode"`
+	CityID       *int    `db:"city_id" json:"cityId"`
+}
+
+// Location is a resolved province and, if one was given, city.
+type Location struct {
+	Province Province
+	City     *City
+}
+
+// SetFullName sets the full name of c from its type and name.
+func (c *City) SetFullName() {
+	if c.Type == TypeCity {
+		c.FullName = "Kota " + c.Name
+	} else {
+		c.FullName = "Kabupaten " + c.Name
+	}
+}
+
+// Prefixes of free-text province and city names, ignored when matching. City
+// prefixes tell which of a regency and a city of the same name is meant.
+var (
+	provincePrefixes = [][]string{{"provinsi"}, {"prov"}, {"propinsi"}}
+	cityPrefixes     = []struct {
+		words    []string
+		cityType string
+	}{
+		{[]string{"kota", "administrasi"}, TypeCity},
+		{[]string{"kota", "adm"}, TypeCity},
+		{[]string{"kota"}, TypeCity},
+		{[]string{"kabupaten", "administrasi"}, TypeRegency},
+		{[]string{"kabupaten"}, TypeRegency},
+		{[]string{"kab", "adm"}, TypeRegency},
+		{[]string{"kab"}, TypeRegency},
+		{[]string{"city"}, TypeCity},
+		{[]string{"regency"}, TypeRegency},
+	}
+)
+
+// words splits s into lower-case words of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// hasPrefix reports whether w starts with the words of prefix and has words
+// after them.
+func hasPrefix(w, prefix []string) bool {
+	if len(w) <= len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if w[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Key returns the matching key of a name: its letters and digits in lower
+// case, so that "Pare-Pare", "parepare" and "Pare Pare" match.
+func Key(name string) string {
+	return strings.Join(words(name), "")
+}
+
+// provinceKey returns the matching key of a free-text province name.
+func provinceKey(name string) string {
+	w := words(name)
+	for _, prefix := range provincePrefixes {
+		if hasPrefix(w, prefix) {
+			w = w[len(prefix):]
+			break
+		}
+	}
+	return strings.Join(w, "")
+}
+
+// cityKey returns the matching key of a free-text city name and the type its
+// prefix names, if any.
+func cityKey(name string) (key, cityType string) {
+	w := words(name)
+	for _, prefix := range cityPrefixes {
+		if hasPrefix(w, prefix.words) {
+			return strings.Join(w[len(prefix.words):], ""), prefix.cityType
+		}
+	}
+	return strings.Join(w, ""), ""
+}
+
+// Index matches free-text province and city names, including aliases, to the
+// reference data.
+type Index struct {
+	provinces     []Province
+	provinceByKey map[string]Province
+	cities        []City
+	cityByID      map[int]City
+	citiesByKey   map[string][]City
+}
+
+// NewIndex indexes provinces, cities and aliases. It fails if two provinces
+// or aliases have the same key.
+func NewIndex(provinces []Province, cities []City, aliases []Alias) (*Index, error) {
+	index := &Index{
+		provinces:     provinces,
+		provinceByKey: map[string]Province{},
+		cityByID:      map[int]City{},
+		citiesByKey:   map[string][]City{},
+	}
+
+	provinceByCode := map[string]Province{}
+	for _, province := range provinces {
+		key := Key(province.Name)
+		if _, ok := index.provinceByKey[key]; ok {
+			return nil, fmt.Errorf("duplicate province name %q", province.Name)
+		}
+		index.provinceByKey[key] = province
+		provinceByCode[province.Code] = province
+	}
+
+	for _, city := range cities {
+		city.SetFullName()
+		index.cities = append(index.cities, city)
+		index.cityByID[city.ID] = city
+		key := Key(city.Name)
+		index.citiesByKey[key] = append(index.citiesByKey[key], city)
+	}
+
+	for _, alias := range aliases {
+		key := Key(alias.Alias)
+		switch {
+		case alias.ProvinceCode != nil:
+			province, ok := provinceByCode[*alias.ProvinceCode]
+			if !ok {
+				continue
+			}
+			if _, ok := index.provinceByKey[key]; ok {
+				return nil, fmt.Errorf("duplicate province alias %q", alias.Alias)
+			}
+			index.provinceByKey[key] = province
+		case alias.CityID != nil:
+			city, ok := index.cityByID[*alias.CityID]
+			if !ok {
+				continue
+			}
+			index.citiesByKey[key] = append(index.citiesByKey[key], city)
+		}
+	}
+	return index, nil
+}
+
+// Provinces returns the provinces of the index.
+func (i *Index) Provinces() []Province {
+	return i.provinces
+}
+
+// Cities returns the cities of a province, or of all provinces if
+// provinceCode is empty.
+func (i *Index) Cities(provinceCode string) []City {
+	cities := []City{}
+	for _, city := range i.cities {
+		if provinceCode == "" || city.ProvinceCode == provinceCode {
+			cities = append(cities, city)
+		}
+	}
+	return cities
+}
+
+// Province returns the province of a free-text name, alias or code.
+func (i *Index) Province(name string) (Province, error) {
+	if province, ok := i.provinceByKey[provinceKey(name)]; ok {
+		return province, nil
+	}
+	code := strings.TrimSpace(name)
+	for _, province := range i.provinces {
+		if province.Code == code {
+			return province, nil
+		}
+	}
+	return Province{}, ErrUnknownProvince
+}
+
+// Resolve matches a free-text province and city, either of which may be
+// empty. The province of a city is found from the city when not given. A
+// name shared by a regency and a city means the city unless prefixed with
+// Kabupaten, as in common usage.
+func (i *Index) Resolve(province, city string) (*Location, error) {
+	location := &Location{}
+	hasProvince := strings.TrimSpace(province) != ""
+	if hasProvince {
+		var err error
+		location.Province, err = i.Province(province)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(city) == "" {
+		if !hasProvince {
+			return nil, ErrUnknownProvince
+		}
+		return location, nil
+	}
+
+	key, cityType := cityKey(city)
+	candidates := []City{}
+	seen := map[int]bool{}
+	for _, candidate := range i.citiesByKey[key] {
+		if seen[candidate.ID] || (cityType != "" && candidate.Type != cityType) {
+			continue
+		}
+		seen[candidate.ID] = true
+		candidates = append(candidates, candidate)
+	}
+	if len(candidates) == 0 {
+		return nil, ErrUnknownCity
+	}
+
+	if hasProvince {
+		inProvince := []City{}
+		for _, candidate := range candidates {
+			if candidate.ProvinceCode == location.Province.Code {
+				inProvince = append(inProvince, candidate)
+			}
+		}
+		if len(inProvince) == 0 {
+			return nil, ErrCityNotInProvince
+		}
+		candidates = inProvince
+	}
+
+	match, ok := pickCity(candidates)
+	if !ok {
+		return nil, ErrAmbiguousCity
+	}
+
+	if !hasProvince {
+		for _, p := range i.provinces {
+			if p.Code == match.ProvinceCode {
+				location.Province = p
+				break
+			}
+		}
+	}
+	location.City = &match
+	return location, nil
+}
+
+// pickCity returns the only candidate, or the only city among regencies of
+// the same name.
+func pickCity(candidates []City) (City, bool) {
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	var picked []City
+	for _, candidate := range candidates {
+		if candidate.Type == TypeCity {
+			picked = append(picked, candidate)
+		}
+	}
+	if len(picked) == 1 {
+		return picked[0], true
+	}
+	return City{}, false
+}
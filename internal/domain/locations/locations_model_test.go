@@ -0,0 +1,91 @@
+package locations
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataset(t *testing.T) {
+	assert.Len(t, Provinces, 38)
+	assert.Len(t, Cities, 514)
+
+	provinces := map[string]bool{}
+	for _, province := range Provinces {
+		provinces[province.Code] = true
+	}
+
+	ids := map[int]bool{}
+	for _, city := range Cities {
+		assert.False(t, ids[city.ID], "duplicate city %d", city.ID)
+		ids[city.ID] = true
+		assert.True(t, provinces[city.ProvinceCode], "city %d", city.ID)
+		assert.Equal(t, city.ProvinceCode, strconv.Itoa(city.ID)[:2], "city %d", city.ID)
+		assert.Contains(t, []string{TypeRegency, TypeCity}, city.Type)
+
+		city.SetFullName()
+		assert.LessOrEqual(t, len(city.FullName), 50, "full name of city %d must fit ums_profiles.city", city.ID)
+	}
+
+	for _, alias := range Aliases {
+		if alias.CityID != nil {
+			assert.True(t, ids[*alias.CityID], "alias %q", alias.Alias)
+		} else {
+			assert.True(t, provinces[*alias.ProvinceCode], "alias %q", alias.Alias)
+		}
+	}
+
+	_, err := NewIndex(Provinces, Cities, Aliases)
+	assert.NoError(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	index, err := NewIndex(Provinces, Cities, Aliases)
+	require.NoError(t, err)
+
+	tests := []struct {
+		province string
+		city     string
+		want     string
+		wantCity string
+		err      error
+	}{
+		{province: "jawa barat", want: "Jawa Barat"},
+		{province: "Provinsi Jawa Barat", want: "Jawa Barat"},
+		{province: "jabar", want: "Jawa Barat"},
+		{province: "32", want: "Jawa Barat"},
+		{province: "jkt", want: "DKI Jakarta"},
+		{province: "Jakarta", want: "DKI Jakarta"},
+		{province: "Daerah Istimewa Yogyakarta", want: "DI Yogyakarta"},
+		{province: "atlantis", err: ErrUnknownProvince},
+		{city: "bandung", want: "Jawa Barat", wantCity: "Kota Bandung"},
+		{city: "Kab. Bandung", want: "Jawa Barat", wantCity: "Kabupaten Bandung"},
+		{city: "kabupaten bandung barat", want: "Jawa Barat", wantCity: "Kabupaten Bandung Barat"},
+		{city: "Pare-Pare", want: "Sulawesi Selatan", wantCity: "Kota Parepare"},
+		{city: "solo", want: "Jawa Tengah", wantCity: "Kota Surakarta"},
+		{city: "Kota Adm. Jakarta Selatan", want: "DKI Jakarta", wantCity: "Kota Jakarta Selatan"},
+		{province: "DKI Jakarta", city: "jaksel", want: "DKI Jakarta", wantCity: "Kota Jakarta Selatan"},
+		{province: "kalsel", city: "banjar", want: "Kalimantan Selatan", wantCity: "Kabupaten Banjar"},
+		{city: "banjar", want: "Jawa Barat", wantCity: "Kota Banjar"},
+		{province: "jatim", city: "bandung", err: ErrCityNotInProvince},
+		{city: "jakarta", err: ErrUnknownCity},
+		{city: "kota sleman", err: ErrUnknownCity},
+		{province: "atlantis", city: "bandung", err: ErrUnknownProvince},
+	}
+	for _, test := range tests {
+		location, err := index.Resolve(test.province, test.city)
+		if test.err != nil {
+			assert.Equal(t, test.err, err, "%q %q", test.province, test.city)
+			continue
+		}
+		require.NoError(t, err, "%q %q", test.province, test.city)
+		assert.Equal(t, test.want, location.Province.Name, "%q %q", test.province, test.city)
+		if test.wantCity == "" {
+			assert.Nil(t, location.City)
+		} else if assert.NotNil(t, location.City) {
+			assert.Equal(t, test.wantCity, location.City.FullName, "%q %q", test.province, test.city)
+		}
+	}
+}
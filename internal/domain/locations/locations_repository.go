@@ -0,0 +1,62 @@
+package locations
+
+import (
+	"context"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+)
+
+type LocationRepository interface {
+	ListProvinces(ctx context.Context) ([]Province, error)
+	ListCities(ctx context.Context) ([]City, error)
+	ListAliases(ctx context.Context) ([]Alias, error)
+}
+
+type LocationRepositoryMySQL struct {
+	DB *infras.MySQLConn
+}
+
+func ProvideLocationRepositoryMySQL(db *infras.MySQLConn) *LocationRepositoryMySQL {
+	return &LocationRepositoryMySQL{
+		DB: db,
+	}
+}
+
+// ListProvinces returns the provinces by name.
+func (r *LocationRepositoryMySQL) ListProvinces(ctx context.Context) ([]Province, error) {
+	provinces := []Province{}
+	err := r.DB.Read.SelectContext(ctx, &provinces, `SELECT code, name FROM ums_provinces ORDER BY name`)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list provinces")
+		return nil, err
+	}
+	return provinces, nil
+}
+
+// ListCities returns the regencies and cities of all provinces by name,
+// regencies first.
+func (r *LocationRepositoryMySQL) ListCities(ctx context.Context) ([]City, error) {
+	cities := []City{}
+	err := r.DB.Read.SelectContext(ctx, &cities, `
+	SELECT id, province_code, type, name
+	FROM ums_cities
+	ORDER BY province_code, type DESC, name
+	`)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list cities")
+		return nil, err
+	}
+	return cities, nil
+}
+
+// ListAliases returns the aliases of provinces and cities.
+func (r *LocationRepositoryMySQL) ListAliases(ctx context.Context) ([]Alias, error) {
+	aliases := []Alias{}
+	err := r.DB.Read.SelectContext(ctx, &aliases, `SELECT alias, province_code, city_id FROM ums_location_aliases`)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list location aliases")
+		return nil, err
+	}
+	return aliases, nil
+}
@@ -0,0 +1,111 @@
+package locations
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/tracing"
+)
+
+// indexTTL is how long the reference data is cached before it is read again.
+// It only changes with migrations, so a stale index is short-lived at worst.
+const indexTTL = 10 * time.Minute
+
+type LocationService interface {
+	ListProvinces(ctx context.Context) ([]Province, error)
+	ListCities(ctx context.Context, province string) ([]City, error)
+	ResolveLocation(ctx context.Context, province, city string) (*Location, error)
+}
+
+type LocationServiceImpl struct {
+	LocationRepository LocationRepository
+
+	mu       sync.Mutex
+	index    *Index
+	loadedAt time.Time
+}
+
+func ProvideLocationServiceImpl(locationRepository LocationRepository) *LocationServiceImpl {
+	return &LocationServiceImpl{
+		LocationRepository: locationRepository,
+	}
+}
+
+// ListProvinces returns all provinces.
+func (s *LocationServiceImpl) ListProvinces(ctx context.Context) ([]Province, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationService.ListProvinces")
+	defer span.End()
+
+	index, err := s.getIndex(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return index.Provinces(), nil
+}
+
+// ListCities returns the regencies and cities of a province given by code,
+// name or alias, or ErrUnknownProvince if there is no such province.
+func (s *LocationServiceImpl) ListCities(ctx context.Context, province string) ([]City, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationService.ListCities")
+	defer span.End()
+
+	index, err := s.getIndex(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	match, err := index.Province(province)
+	if err != nil {
+		return nil, err
+	}
+	return index.Cities(match.Code), nil
+}
+
+// ResolveLocation matches a free-text province and city to the reference
+// data. See Index.Resolve.
+func (s *LocationServiceImpl) ResolveLocation(ctx context.Context, province, city string) (*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationService.ResolveLocation")
+	defer span.End()
+
+	index, err := s.getIndex(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	return index.Resolve(province, city)
+}
+
+// getIndex returns the index of the reference data, reading it again once
+// indexTTL has passed.
+func (s *LocationServiceImpl) getIndex(ctx context.Context) (*Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index != nil && time.Since(s.loadedAt) < indexTTL {
+		return s.index, nil
+	}
+
+	provinces, err := s.LocationRepository.ListProvinces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cities, err := s.LocationRepository.ListCities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := s.LocationRepository.ListAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := NewIndex(provinces, cities, aliases)
+	if err != nil {
+		return nil, err
+	}
+	s.index = index
+	s.loadedAt = time.Now()
+	return index, nil
+}
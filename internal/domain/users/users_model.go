@@ -1,14 +1,84 @@
 package users
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/evermos/boilerplate-go/shared/civil"
 )
 
 var (
 	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+
+	ErrInvalidStatus     = errors.New("invalid trainee status")
+	ErrIllegalTransition = errors.New("illegal trainee status transition")
+	ErrStatusChanged     = errors.New("trainee status changed concurrently")
+
+	ErrProfileVersionNotFound = errors.New("profile version not found")
+
+	ErrInvalidPhoto  = errors.New("photo is not a supported image")
+	ErrPhotoTooLarge = errors.New("photo dimensions are too large")
+
+	ErrCustomFieldNotFound  = errors.New("custom field not found")
+	ErrCustomFieldKeyExists = errors.New("custom field key already exists")
+
+	ErrPhoneNumberExists = errors.New("phone number already in use")
+)
+
+// Trainee statuses, kept in ums_status.status. Users without a status have
+// not entered the workflow yet.
+const (
+	StatusApplied    = "applied"
+	StatusOnboarding = "onboarding"
+	StatusActive     = "active"
+	StatusOnLeave    = "on_leave"
+	StatusGraduated  = "graduated"
+	StatusTerminated = "terminated"
 )
 
+// statusTransitions are the statuses each status may move to. Graduated and
+// terminated are final. Users without a status may enter the workflow at any
+// of the first three statuses, for trainees who joined before it existed.
+var statusTransitions = map[string][]string{
+	"":               {StatusApplied, StatusOnboarding, StatusActive},
+	StatusApplied:    {StatusOnboarding, StatusTerminated},
+	StatusOnboarding: {StatusActive, StatusTerminated},
+	StatusActive:     {StatusOnLeave, StatusGraduated, StatusTerminated},
+	StatusOnLeave:    {StatusActive, StatusTerminated},
+	StatusGraduated:  {},
+	StatusTerminated: {},
+}
+
+// IsValidStatus reports whether status is a trainee status.
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok && status != ""
+}
+
+// CanTransition reports whether a trainee may move from one status to
+// another. An empty from is a trainee without a status.
+func CanTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextStatuses returns the statuses a trainee may move to from status.
+func NextStatuses(status string) []string {
+	return append([]string{}, statusTransitions[status]...)
+}
+
 type User struct {
 	ID        string     `db:"id" json:"id"`
 	Username  string     `db:"username" json:"username"`
@@ -23,20 +93,27 @@ type User struct {
 }
 
 type UserView struct {
-	Username       string  `db:"username"`
-	Name           *string `db:"name"`
-	Role           string  `db:"role"`
-	Gender         *string `db:"gender"`
-	DoB            *string `db:"dob"`
-	Education      *string `db:"education"`
-	City           *string `db:"city"`
-	Province       *string `db:"province"`
-	Address        *string `db:"address"`
-	PhoneNumber    *string `db:"phone_number"`
-	JobRole        *string `db:"job_role"`
-	Status         *string `db:"status"`
-	PlacementCity  *string `db:"placement"`
-	DepartmentName *string `db:"department_name"`
+	UserID         string                 `db:"id" json:"-"`
+	Username       string                 `db:"username"`
+	Email          *string                `db:"email"`
+	Name           *string                `db:"name"`
+	Role           string                 `db:"role"`
+	Gender         *string                `db:"gender"`
+	DoB            *civil.Date            `db:"dob"`
+	Age            *int                   `db:"-"`
+	Education      *string                `db:"education"`
+	City           *string                `db:"city"`
+	Province       *string                `db:"province"`
+	Address        *string                `db:"address"`
+	PhoneNumber    *string                `db:"phone_number"`
+	JobRole        *string                `db:"job_role"`
+	Status         *string                `db:"status"`
+	PlacementCity  *string                `db:"placement"`
+	DepartmentName *string                `db:"department_name"`
+	PhotoKey       *string                `db:"photo_key" json:"-"`
+	PhotoURL       *string                `db:"-"`
+	ThumbnailURL   *string                `db:"-"`
+	CustomFields   map[string]interface{} `db:"-"`
 }
 
 type UserList struct {
@@ -54,33 +131,333 @@ type UserFilter struct {
 	Province string `db:"province" json:"province"`
 	JobRole  string `db:"job_role" json:"job_role"`
 	Status   string `db:"status" json:"status"`
+	// PhoneNumber is matched exactly, once normalized to E.164.
+	PhoneNumber string `db:"phone_number" json:"phone_number"`
+	// MinAge and MaxAge bound the age of users in years, and BirthMonth, from
+	// 1 to 12, is the month of their birthday. Users without a date of birth
+	// do not match them.
+	MinAge     *int `db:"-" json:"min_age"`
+	MaxAge     *int `db:"-" json:"max_age"`
+	BirthMonth int  `db:"-" json:"birth_month"`
+	// BornOnOrBefore and BornAfter are the bounds of the dates of birth
+	// MinAge and MaxAge allow, set by the service.
+	BornOnOrBefore *civil.Date `db:"-" json:"-"`
+	BornAfter      *civil.Date `db:"-" json:"-"`
+	// CustomFields are the values of custom fields, by key, users must have.
+	CustomFields map[string]string `db:"-" json:"custom_fields"`
 }
 
 type ProfileView struct {
-	Name           *string `db:"name"`
-	Role           string  `db:"role"`
-	Gender         *string `db:"gender"`
-	DoB            *string `db:"dob"`
-	Education      *string `db:"education"`
-	City           *string `db:"city"`
-	Province       *string `db:"province"`
-	Address        *string `db:"address"`
-	PhoneNumber    *string `db:"phone_number"`
-	JobRole        *string `db:"job_role"`
-	Status         *string `db:"status"`
-	PlacementCity  *string `db:"placement_city"`
-	DepartmentName *string `db:"department_name"`
+	Name           *string                `db:"name"`
+	Email          *string                `db:"email"`
+	Role           string                 `db:"role"`
+	Gender         *string                `db:"gender"`
+	DoB            *civil.Date            `db:"dob"`
+	Age            *int                   `db:"-"`
+	Education      *string                `db:"education"`
+	City           *string                `db:"city"`
+	Province       *string                `db:"province"`
+	Address        *string                `db:"address"`
+	PhoneNumber    *string                `db:"phone_number"`
+	JobRole        *string                `db:"job_role"`
+	Status         *string                `db:"status"`
+	PlacementCity  *string                `db:"placement_city"`
+	DepartmentName *string                `db:"department_name"`
+	PhotoKey       *string                `db:"photo_key" json:"-"`
+	PhotoURL       *string                `db:"-"`
+	ThumbnailURL   *string                `db:"-"`
+	CustomFields   map[string]interface{} `db:"-"`
 }
 
 type UpdateProfile struct {
-	Name        *string   `db:"name" json:"name"`
-	Gender      *string   `db:"gender" json:"gender"`
-	DoB         *string   `db:"dob" json:"dob"`
-	Education   *string   `db:"education" json:"education"`
-	Address     *string   `db:"address" json:"address"`
-	City        *string   `db:"city" json:"city"`
-	Province    *string   `db:"province" json:"province"`
-	PhoneNumber *string   `db:"phone_number" json:"phone_number"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	UpdatedBy   string    `db:"updated_by" json:"updated_by"`
+	Name        *string     `db:"name" json:"name"`
+	Gender      *string     `db:"gender" json:"gender"`
+	DoB         *civil.Date `db:"dob" json:"dob"`
+	Education   *string     `db:"education" json:"education"`
+	Address     *string     `db:"address" json:"address"`
+	City        *string     `db:"city" json:"city"`
+	Province    *string     `db:"province" json:"province"`
+	PhoneNumber *string     `db:"phone_number" json:"phone_number"`
+	UpdatedAt   time.Time   `db:"updated_at" json:"updated_at"`
+	UpdatedBy   string      `db:"updated_by" json:"updated_by"`
+	// CustomFields are the custom field values to set by key. A null value
+	// clears the field.
+	CustomFields map[string]interface{} `db:"-" json:"custom_fields"`
+	// CustomFieldValues are CustomFields validated against their
+	// definitions, set by the service.
+	CustomFieldValues []CustomFieldValue `db:"-" json:"-"`
+}
+
+// StatusTransition is a request to move a trainee to another status.
+// EffectiveDate is in YYYY-MM-DD format and defaults to the current date.
+type StatusTransition struct {
+	Status        string `json:"status"`
+	Reason        string `json:"reason"`
+	EffectiveDate string `json:"effectiveDate"`
+	CreatedBy     string `json:"-"`
+}
+
+// StatusHistory is a recorded change of the status of a trainee.
+type StatusHistory struct {
+	ID            string    `db:"id" json:"id"`
+	UserID        string    `db:"user_id" json:"userId"`
+	FromStatus    *string   `db:"from_status" json:"fromStatus"`
+	ToStatus      string    `db:"to_status" json:"toStatus"`
+	Reason        string    `db:"reason" json:"reason"`
+	EffectiveDate string    `db:"effective_date" json:"effectiveDate"`
+	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+	CreatedBy     string    `db:"created_by" json:"createdBy"`
+}
+
+// ProfileVersion is the profile of a user as it was from CreatedAt until the
+// next version. RevertedFrom is set on versions restoring an older one.
+type ProfileVersion struct {
+	Version      int         `db:"version" json:"version"`
+	Name         *string     `db:"name" json:"name"`
+	Gender       *string     `db:"gender" json:"gender"`
+	DoB          *civil.Date `db:"dob" json:"dob"`
+	Education    *string     `db:"education" json:"education"`
+	Address      *string     `db:"address" json:"address"`
+	City         *string     `db:"city" json:"city"`
+	Province     *string     `db:"province" json:"province"`
+	PhoneNumber  *string     `db:"phone_number" json:"phoneNumber"`
+	RevertedFrom *int        `db:"reverted_from" json:"revertedFrom"`
+	CreatedAt    time.Time   `db:"created_at" json:"createdAt"`
+	CreatedBy    *string     `db:"created_by" json:"createdBy"`
+}
+
+// Photo holds the URLs of the photo of a profile.
+type Photo struct {
+	PhotoURL     string `json:"photoUrl"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// Custom field types.
+const (
+	FieldTypeText    = "text"
+	FieldTypeNumber  = "number"
+	FieldTypeBoolean = "boolean"
+	FieldTypeDate    = "date"
+	FieldTypeSelect  = "select"
+)
+
+// Custom field visibilities: hidden fields are only seen by admins, users can
+// see read fields on their profile and also change edit fields.
+const (
+	VisibilityHidden = "hidden"
+	VisibilityRead   = "read"
+	VisibilityEdit   = "edit"
+)
+
+// customFieldKeyPattern is the format of custom field keys, which are used as
+// JSON keys and query parameters.
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ValidationError reports an invalid custom field definition or value.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// FieldOptions are the allowed values of a select field, stored as a JSON
+// array.
+type FieldOptions []string
+
+// Scan implements sql.Scanner.
+func (o *FieldOptions) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, o)
+	case string:
+		return json.Unmarshal([]byte(src), o)
+	}
+	return fmt.Errorf("cannot scan %T into FieldOptions", src)
+}
+
+// Value implements driver.Valuer.
+func (o FieldOptions) Value() (driver.Value, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// CustomField is the definition of a profile field added by admins. Pattern
+// and MaxLength apply to text fields, Min and Max to number fields, and
+// Options lists the values of select fields.
+type CustomField struct {
+	ID         string       `db:"id" json:"id"`
+	Key        string       `db:"field_key" json:"key"`
+	Label      string       `db:"label" json:"label"`
+	Type       string       `db:"type" json:"type"`
+	Options    FieldOptions `db:"options" json:"options,omitempty"`
+	Pattern    *string      `db:"pattern" json:"pattern,omitempty"`
+	MaxLength  *int         `db:"max_length" json:"maxLength,omitempty"`
+	Min        *float64     `db:"min_value" json:"min,omitempty"`
+	Max        *float64     `db:"max_value" json:"max,omitempty"`
+	Required   bool         `db:"required" json:"required"`
+	Visibility string       `db:"visibility" json:"visibility"`
+	CreatedAt  time.Time    `db:"created_at" json:"createdAt"`
+	CreatedBy  string       `db:"created_by" json:"createdBy"`
+	UpdatedAt  *time.Time   `db:"updated_at" json:"updatedAt"`
+	UpdatedBy  *string      `db:"updated_by" json:"updatedBy"`
+}
+
+// CustomFieldValue is the value of a custom field of a user, normalized by
+// its definition. A nil Value clears the field.
+type CustomFieldValue struct {
+	UserID  string  `db:"user_id"`
+	FieldID string  `db:"field_id"`
+	Value   *string `db:"value"`
+}
+
+// Validate checks the definition of a custom field.
+func (f *CustomField) Validate() error {
+	if !customFieldKeyPattern.MatchString(f.Key) {
+		return &ValidationError{"key", "must start with a lowercase letter and have at most 64 lowercase letters, digits or underscores"}
+	}
+	if strings.TrimSpace(f.Label) == "" || utf8.RuneCountInString(f.Label) > 255 {
+		return &ValidationError{"label", "is required and must be at most 255 characters"}
+	}
+
+	switch f.Visibility {
+	case VisibilityHidden, VisibilityRead, VisibilityEdit:
+	default:
+		return &ValidationError{"visibility", "must be one of hidden, read or edit"}
+	}
+
+	switch f.Type {
+	case FieldTypeText, FieldTypeNumber, FieldTypeBoolean, FieldTypeDate, FieldTypeSelect:
+	default:
+		return &ValidationError{"type", "must be one of text, number, boolean, date or select"}
+	}
+
+	if f.Type != FieldTypeText && (f.Pattern != nil || f.MaxLength != nil) {
+		return &ValidationError{"pattern", "and maxLength only apply to text fields"}
+	}
+	if f.Pattern != nil {
+		if _, err := regexp.Compile(*f.Pattern); err != nil || len(*f.Pattern) > 255 {
+			return &ValidationError{"pattern", "must be a regular expression of at most 255 characters"}
+		}
+	}
+	if f.MaxLength != nil && *f.MaxLength < 1 {
+		return &ValidationError{"maxLength", "must be positive"}
+	}
+
+	if f.Type != FieldTypeNumber && (f.Min != nil || f.Max != nil) {
+		return &ValidationError{"min", "and max only apply to number fields"}
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return &ValidationError{"min", "must not be greater than max"}
+	}
+
+	if f.Type != FieldTypeSelect {
+		if len(f.Options) > 0 {
+			return &ValidationError{"options", "only apply to select fields"}
+		}
+		return nil
+	}
+	if len(f.Options) == 0 {
+		return &ValidationError{"options", "are required for select fields"}
+	}
+	seen := map[string]bool{}
+	for _, option := range f.Options {
+		if option == "" || utf8.RuneCountInString(option) > 255 || seen[option] {
+			return &ValidationError{"options", "must be unique non-empty values of at most 255 characters"}
+		}
+		seen[option] = true
+	}
+	return nil
+}
+
+// NormalizeValue validates a value of the field, as decoded from JSON or
+// given as a string in a query parameter, and returns it in the form it is
+// stored and filtered on.
+func (f *CustomField) NormalizeValue(value interface{}) (string, error) {
+	switch f.Type {
+	case FieldTypeNumber:
+		var number float64
+		switch value := value.(type) {
+		case float64:
+			number = value
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return "", &ValidationError{f.Key, "must be a number"}
+			}
+			number = parsed
+		default:
+			return "", &ValidationError{f.Key, "must be a number"}
+		}
+		if (f.Min != nil && number < *f.Min) || (f.Max != nil && number > *f.Max) {
+			return "", &ValidationError{f.Key, "is out of range"}
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+
+	case FieldTypeBoolean:
+		switch value := value.(type) {
+		case bool:
+			return strconv.FormatBool(value), nil
+		case string:
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				return strconv.FormatBool(parsed), nil
+			}
+		}
+		return "", &ValidationError{f.Key, "must be true or false"}
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return "", &ValidationError{f.Key, "must be a string"}
+	}
+	text = strings.TrimSpace(text)
+
+	switch f.Type {
+	case FieldTypeDate:
+		if _, err := time.Parse(civil.Layout, text); err != nil {
+			return "", &ValidationError{f.Key, "must be in YYYY-MM-DD format"}
+		}
+	case FieldTypeSelect:
+		for _, option := range f.Options {
+			if option == text {
+				return text, nil
+			}
+		}
+		return "", &ValidationError{f.Key, "must be one of " + strings.Join(f.Options, ", ")}
+	default:
+		if text == "" && f.Required {
+			return "", &ValidationError{f.Key, "is required"}
+		}
+		if f.MaxLength != nil && utf8.RuneCountInString(text) > *f.MaxLength {
+			return "", &ValidationError{f.Key, fmt.Sprintf("must be at most %d characters", *f.MaxLength)}
+		}
+		if f.Pattern != nil {
+			pattern, err := regexp.Compile(*f.Pattern)
+			if err != nil || !pattern.MatchString(text) {
+				return "", &ValidationError{f.Key, "has an invalid format"}
+			}
+		}
+	}
+	return text, nil
+}
+
+// DecodeValue returns a stored value of the field as the JSON value it was
+// given as.
+func (f *CustomField) DecodeValue(value string) interface{} {
+	switch f.Type {
+	case FieldTypeNumber:
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case FieldTypeBoolean:
+		return value == "true"
+	}
+	return value
 }
@@ -0,0 +1,124 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := [][2]string{
+		{"", StatusApplied},
+		{"", StatusActive},
+		{StatusApplied, StatusOnboarding},
+		{StatusOnboarding, StatusActive},
+		{StatusActive, StatusOnLeave},
+		{StatusOnLeave, StatusActive},
+		{StatusActive, StatusGraduated},
+		{StatusOnLeave, StatusTerminated},
+	}
+	for _, transition := range allowed {
+		assert.True(t, CanTransition(transition[0], transition[1]), "%q -> %q", transition[0], transition[1])
+	}
+
+	illegal := [][2]string{
+		{"", StatusGraduated},
+		{StatusApplied, StatusActive},
+		{StatusActive, StatusApplied},
+		{StatusOnLeave, StatusGraduated},
+		{StatusGraduated, StatusActive},
+		{StatusTerminated, StatusActive},
+		{StatusActive, StatusActive},
+		{StatusActive, "unknown"},
+		{"unknown", StatusActive},
+	}
+	for _, transition := range illegal {
+		assert.False(t, CanTransition(transition[0], transition[1]), "%q -> %q", transition[0], transition[1])
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	assert.True(t, IsValidStatus(StatusOnLeave))
+	assert.False(t, IsValidStatus(""))
+	assert.False(t, IsValidStatus("Active"))
+}
+
+func TestCustomFieldValidate(t *testing.T) {
+	pattern := "["
+	maxLength := 10
+	min, max := 5.0, 1.0
+
+	valid := []CustomField{
+		{Key: "emergency_contact", Label: "Emergency contact", Type: FieldTypeText, Visibility: VisibilityEdit, MaxLength: &maxLength},
+		{Key: "shirt_size", Label: "Shirt size", Type: FieldTypeSelect, Visibility: VisibilityRead, Options: FieldOptions{"S", "M"}},
+	}
+	for _, field := range valid {
+		assert.NoError(t, field.Validate(), field.Key)
+	}
+
+	invalid := []CustomField{
+		{Key: "Shirt Size", Label: "Shirt size", Type: FieldTypeText, Visibility: VisibilityEdit},
+		{Key: "shirt_size", Label: "", Type: FieldTypeText, Visibility: VisibilityEdit},
+		{Key: "shirt_size", Label: "Shirt size", Type: "color", Visibility: VisibilityEdit},
+		{Key: "shirt_size", Label: "Shirt size", Type: FieldTypeText, Visibility: "public"},
+		{Key: "shirt_size", Label: "Shirt size", Type: FieldTypeSelect, Visibility: VisibilityEdit},
+		{Key: "shirt_size", Label: "Shirt size", Type: FieldTypeSelect, Visibility: VisibilityEdit, Options: FieldOptions{"S", "S"}},
+		{Key: "shirt_size", Label: "Shirt size", Type: FieldTypeText, Visibility: VisibilityEdit, Options: FieldOptions{"S"}},
+		{Key: "bank_account", Label: "Bank account", Type: FieldTypeText, Visibility: VisibilityEdit, Pattern: &pattern},
+		{Key: "grade", Label: "Grade", Type: FieldTypeNumber, Visibility: VisibilityEdit, Min: &min, Max: &max},
+		{Key: "grade", Label: "Grade", Type: FieldTypeNumber, Visibility: VisibilityEdit, MaxLength: &maxLength},
+	}
+	for _, field := range invalid {
+		assert.Error(t, field.Validate(), field.Key)
+	}
+}
+
+func TestCustomFieldNormalizeValue(t *testing.T) {
+	pattern := `^[0-9]+$`
+	min, max := 0.0, 4.0
+
+	text := CustomField{Key: "bank_account", Type: FieldTypeText, Pattern: &pattern}
+	value, err := text.NormalizeValue(" 1234 ")
+	require.NoError(t, err)
+	assert.Equal(t, "1234", value)
+	_, err = text.NormalizeValue("12a4")
+	assert.Error(t, err)
+	_, err = text.NormalizeValue(1234.0)
+	assert.Error(t, err)
+
+	number := CustomField{Key: "gpa", Type: FieldTypeNumber, Min: &min, Max: &max}
+	value, err = number.NormalizeValue(3.50)
+	require.NoError(t, err)
+	assert.Equal(t, "3.5", value)
+	assert.Equal(t, 3.5, number.DecodeValue(value))
+	_, err = number.NormalizeValue(4.5)
+	assert.Error(t, err)
+
+	boolean := CustomField{Key: "has_laptop", Type: FieldTypeBoolean}
+	value, err = boolean.NormalizeValue("TRUE")
+	require.NoError(t, err)
+	assert.Equal(t, "true", value)
+	assert.Equal(t, true, boolean.DecodeValue(value))
+
+	date := CustomField{Key: "contract_end", Type: FieldTypeDate}
+	_, err = date.NormalizeValue("2026-02-30")
+	assert.Error(t, err)
+
+	selection := CustomField{Key: "shirt_size", Type: FieldTypeSelect, Options: FieldOptions{"S", "M"}}
+	_, err = selection.NormalizeValue("XL")
+	assert.EqualError(t, err, "shirt_size must be one of S, M")
+}
+
+func TestFieldOptionsScan(t *testing.T) {
+	var options FieldOptions
+	require.NoError(t, options.Scan([]byte(`["S","M"]`)))
+	assert.Equal(t, FieldOptions{"S", "M"}, options)
+
+	value, err := options.Value()
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`["S","M"]`), value)
+
+	require.NoError(t, options.Scan(nil))
+	assert.Nil(t, options)
+}
@@ -1,21 +1,46 @@
 package users
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/evermos/boilerplate-go/infras"
-	"github.com/rs/zerolog/log"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
 )
 
+// mysqlErrDuplicateEntry is the MySQL error number of a unique key violation.
+const mysqlErrDuplicateEntry = 1062
+
 type UserRepository interface {
-	GetData(filter UserFilter, page, size int) ([]UserView, error)
-	CountTotalData(filter UserFilter) (int, error)
-	GetProfile(uuid string) (*ProfileView, error)
-	UpdateProfile(uuid string, profile *UpdateProfile) (*UpdateProfile, error)
-	DeleteUserByID(uuid string) error
+	GetData(ctx context.Context, filter UserFilter, page, size int) ([]UserView, error)
+	CountTotalData(ctx context.Context, filter UserFilter) (int, error)
+	GetProfile(ctx context.Context, uuid string) (*ProfileView, error)
+	UpdateProfile(ctx context.Context, uuid string, profile *UpdateProfile) (*UpdateProfile, error)
+	DeleteUserByID(ctx context.Context, uuid string) error
+	GetStatus(ctx context.Context, uuid string) (string, error)
+	TransitionStatus(ctx context.Context, history *StatusHistory) error
+	GetStatusHistory(ctx context.Context, uuid string) ([]StatusHistory, error)
+	GetProfileHistory(ctx context.Context, uuid string) ([]ProfileVersion, error)
+	GetProfileAsOf(ctx context.Context, uuid string, asOf time.Time) (*ProfileVersion, error)
+	GetProfileVersion(ctx context.Context, uuid string, version int) (*ProfileVersion, error)
+	RevertProfile(ctx context.Context, uuid string, profile *ProfileVersion, updatedBy string) (*ProfileVersion, error)
+	SetPhoto(ctx context.Context, uuid, photoKey, updatedBy string) (*string, error)
+	ListCustomFields(ctx context.Context) ([]CustomField, error)
+	GetCustomField(ctx context.Context, id string) (*CustomField, error)
+	IsCustomFieldKeyExist(ctx context.Context, key string) (bool, error)
+	IsPhoneNumberExist(ctx context.Context, phoneNumber, excludeUUID string) (bool, error)
+	CreateCustomField(ctx context.Context, field *CustomField) error
+	UpdateCustomField(ctx context.Context, field *CustomField) error
+	DeleteCustomField(ctx context.Context, id string) error
+	GetCustomFieldValues(ctx context.Context, userIDs []string) ([]CustomFieldValue, error)
+	SetCustomFieldValues(ctx context.Context, uuid string, values []CustomFieldValue, updatedBy string) error
 }
 
 type UserRepositoryMySQL struct {
@@ -28,10 +53,12 @@ func ProvideUserRepositoryMySQL(db *infras.MySQLConn) *UserRepositoryMySQL {
 	}
 }
 
-func (r *UserRepositoryMySQL) GetData(filter UserFilter, page, size int) ([]UserView, error) {
+func (r *UserRepositoryMySQL) GetData(ctx context.Context, filter UserFilter, page, size int) ([]UserView, error) {
 	query := `
 		SELECT 
+			u.id,
 			u.username,
+			u.email,
 		 	p.name,
 			u.role,
 			p.gender,
@@ -44,7 +71,8 @@ func (r *UserRepositoryMySQL) GetData(filter UserFilter, page, size int) ([]User
 			s.job_role,
 			s.status,
 			pl.city AS placement,
-			d.name AS department_name
+			d.name AS department_name,
+			p.photo_key
 		FROM 
 			ums_users AS u
 		LEFT JOIN
@@ -92,6 +120,46 @@ func (r *UserRepositoryMySQL) GetData(filter UserFilter, page, size int) ([]User
 		args = append(args, "%"+filter.Province+"%")
 	}
 
+	if filter.PhoneNumber != "" {
+		if len(args) > 0 {
+			query += " AND"
+		} else {
+			query += " WHERE"
+		}
+		query += " p.phone_number = ?"
+		args = append(args, filter.PhoneNumber)
+	}
+
+	if filter.BornOnOrBefore != nil {
+		if len(args) > 0 {
+			query += " AND"
+		} else {
+			query += " WHERE"
+		}
+		query += " p.dob <= ?"
+		args = append(args, *filter.BornOnOrBefore)
+	}
+
+	if filter.BornAfter != nil {
+		if len(args) > 0 {
+			query += " AND"
+		} else {
+			query += " WHERE"
+		}
+		query += " p.dob > ?"
+		args = append(args, *filter.BornAfter)
+	}
+
+	if filter.BirthMonth != 0 {
+		if len(args) > 0 {
+			query += " AND"
+		} else {
+			query += " WHERE"
+		}
+		query += " MONTH(p.dob) = ?"
+		args = append(args, filter.BirthMonth)
+	}
+
 	if filter.JobRole != "" {
 		if len(args) > 0 {
 			query += " AND"
@@ -108,8 +176,19 @@ func (r *UserRepositoryMySQL) GetData(filter UserFilter, page, size int) ([]User
 		} else {
 			query += " WHERE"
 		}
-		query += " s.status LIKE ?"
-		args = append(args, "%"+filter.Status+"%")
+		query += " s.status = ?"
+		args = append(args, filter.Status)
+	}
+
+	conditions, conditionArgs := customFieldConditions(filter)
+	for i, condition := range conditions {
+		if len(args) > 0 {
+			query += " AND"
+		} else {
+			query += " WHERE"
+		}
+		query += " " + condition
+		args = append(args, conditionArgs[2*i:2*i+2]...)
 	}
 
 	if page < 1 {
@@ -124,16 +203,16 @@ func (r *UserRepositoryMySQL) GetData(filter UserFilter, page, size int) ([]User
 	args = append(args, size, offset)
 
 	var users []UserView
-	err := r.DB.Read.Select(&users, query, args...)
+	err := r.DB.Read.SelectContext(ctx, &users, query, args...)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to read users from db")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to read users from db")
 		return nil, err
 	}
 
 	return users, nil
 }
 
-func (r *UserRepositoryMySQL) CountTotalData(filter UserFilter) (int, error) {
+func (r *UserRepositoryMySQL) CountTotalData(ctx context.Context, filter UserFilter) (int, error) {
 	totalDataQuery := `
 		SELECT 
 			COUNT(*) 
@@ -184,6 +263,46 @@ func (r *UserRepositoryMySQL) CountTotalData(filter UserFilter) (int, error) {
 		argsTotalData = append(argsTotalData, "%"+filter.Province+"%")
 	}
 
+	if filter.PhoneNumber != "" {
+		if len(argsTotalData) > 0 {
+			totalDataQuery += " AND"
+		} else {
+			totalDataQuery += " WHERE"
+		}
+		totalDataQuery += " p.phone_number = ?"
+		argsTotalData = append(argsTotalData, filter.PhoneNumber)
+	}
+
+	if filter.BornOnOrBefore != nil {
+		if len(argsTotalData) > 0 {
+			totalDataQuery += " AND"
+		} else {
+			totalDataQuery += " WHERE"
+		}
+		totalDataQuery += " p.dob <= ?"
+		argsTotalData = append(argsTotalData, *filter.BornOnOrBefore)
+	}
+
+	if filter.BornAfter != nil {
+		if len(argsTotalData) > 0 {
+			totalDataQuery += " AND"
+		} else {
+			totalDataQuery += " WHERE"
+		}
+		totalDataQuery += " p.dob > ?"
+		argsTotalData = append(argsTotalData, *filter.BornAfter)
+	}
+
+	if filter.BirthMonth != 0 {
+		if len(argsTotalData) > 0 {
+			totalDataQuery += " AND"
+		} else {
+			totalDataQuery += " WHERE"
+		}
+		totalDataQuery += " MONTH(p.dob) = ?"
+		argsTotalData = append(argsTotalData, filter.BirthMonth)
+	}
+
 	if filter.JobRole != "" {
 		if len(argsTotalData) > 0 {
 			totalDataQuery += " AND"
@@ -200,35 +319,46 @@ func (r *UserRepositoryMySQL) CountTotalData(filter UserFilter) (int, error) {
 		} else {
 			totalDataQuery += " WHERE"
 		}
-		totalDataQuery += " s.status LIKE ?"
-		argsTotalData = append(argsTotalData, "%"+filter.Status+"%")
+		totalDataQuery += " s.status = ?"
+		argsTotalData = append(argsTotalData, filter.Status)
+	}
+
+	conditions, conditionArgs := customFieldConditions(filter)
+	for i, condition := range conditions {
+		if len(argsTotalData) > 0 {
+			totalDataQuery += " AND"
+		} else {
+			totalDataQuery += " WHERE"
+		}
+		totalDataQuery += " " + condition
+		argsTotalData = append(argsTotalData, conditionArgs[2*i:2*i+2]...)
 	}
 
 	var totalData int
-	err := r.DB.Read.Get(&totalData, totalDataQuery, argsTotalData...)
+	err := r.DB.Read.GetContext(ctx, &totalData, totalDataQuery, argsTotalData...)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get total data")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get total data")
 		return 0, err
 	}
 
 	return totalData, nil
 }
 
-func (r *UserRepositoryMySQL) DeleteUserByID(uuid string) error {
+func (r *UserRepositoryMySQL) DeleteUserByID(ctx context.Context, uuid string) error {
 	query := `
 		DELETE FROM ums_users
 		WHERE id = ? AND role != 'admin'
 	`
 
-	result, err := r.DB.Write.Exec(query, uuid)
+	result, err := r.DB.Write.ExecContext(ctx, query, uuid)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to delete user")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to delete user")
 		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to check affected rows")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check affected rows")
 		return err
 	}
 
@@ -239,10 +369,11 @@ func (r *UserRepositoryMySQL) DeleteUserByID(uuid string) error {
 	return nil
 }
 
-func (r *UserRepositoryMySQL) GetProfile(uuid string) (*ProfileView, error) {
+func (r *UserRepositoryMySQL) GetProfile(ctx context.Context, uuid string) (*ProfileView, error) {
 	query := `
 	SELECT 
 		p.name,
+		u.email,
 		u.role,
 		p.gender,
 		p.dob,
@@ -254,7 +385,8 @@ func (r *UserRepositoryMySQL) GetProfile(uuid string) (*ProfileView, error) {
 		s.job_role,
 		s.status,
 		pl.city AS placement_city,
-		d.name AS department_name
+		d.name AS department_name,
+		p.photo_key
 	FROM 
 		ums_users AS u
 	LEFT JOIN
@@ -273,15 +405,15 @@ func (r *UserRepositoryMySQL) GetProfile(uuid string) (*ProfileView, error) {
 	`
 
 	var profile ProfileView
-	err := r.DB.Read.Get(&profile, query, uuid)
+	err := r.DB.Read.GetContext(ctx, &profile, query, uuid)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get profile")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get profile")
 		return nil, err
 	}
 	return &profile, nil
 }
 
-func (r *UserRepositoryMySQL) UpdateProfile(uuid string, profile *UpdateProfile) (*UpdateProfile, error) {
+func (r *UserRepositoryMySQL) UpdateProfile(ctx context.Context, uuid string, profile *UpdateProfile) (*UpdateProfile, error) {
 	setClauses := []string{
 		"p.name = COALESCE(?, p.name)",
 		"p.gender = COALESCE(?, p.gender)",
@@ -309,8 +441,8 @@ func (r *UserRepositoryMySQL) UpdateProfile(uuid string, profile *UpdateProfile)
 		profile.DoB,
 		lowercaseOrNil(profile.Education),
 		lowercaseOrNil(profile.Address),
-		lowercaseOrNil(profile.City),
-		lowercaseOrNil(profile.Province),
+		profile.City,
+		profile.Province,
 		profile.PhoneNumber,
 		profile.UpdatedAt,
 		profile.UpdatedBy,
@@ -319,18 +451,610 @@ func (r *UserRepositoryMySQL) UpdateProfile(uuid string, profile *UpdateProfile)
 		uuid,
 	}
 
-	_, err := r.DB.Write.Exec(query, values...)
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to update profile")
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return nil, ErrPhoneNumberExists
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to update profile")
+		return nil, err
+	}
+
+	err = snapshotProfile(ctx, tx, uuid, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setCustomFieldValues(ctx, tx, uuid, profile.CustomFieldValues, profile.UpdatedBy, profile.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
 		return nil, err
 	}
 
 	return profile, nil
 }
 
+// profileVersionColumns are the columns of ums_profile_versions selected into
+// a ProfileVersion.
+const profileVersionColumns = `
+	v.version, v.name, v.gender, v.dob, v.education, v.address, v.city, v.province,
+	v.phone_number, v.reverted_from, v.created_at, v.created_by`
+
+// snapshotProfile records the current profile of a user as its next version.
+// It must run in the transaction updating the profile, whose row lock
+// serializes the version numbers.
+func snapshotProfile(ctx context.Context, tx *sqlx.Tx, userID string, revertedFrom *int) error {
+	query := `
+	INSERT INTO ums_profile_versions
+		(profile_id, version, name, gender, dob, education, address, city, province, phone_number, reverted_from, created_at, created_by)
+	SELECT
+		p.id,
+		COALESCE((SELECT MAX(v.version) FROM ums_profile_versions AS v WHERE v.profile_id = p.id), 0) + 1,
+		p.name, p.gender, p.dob, p.education, p.address, p.city, p.province, p.phone_number,
+		?, p.updated_at, p.updated_by
+	FROM ums_profiles AS p
+	INNER JOIN ums_users AS u ON u.profile_id = p.id
+	WHERE u.id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, revertedFrom, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert profile version")
+		return err
+	}
+	return nil
+}
+
+// GetProfileHistory returns the versions of the profile of a user, latest
+// first.
+func (r *UserRepositoryMySQL) GetProfileHistory(ctx context.Context, uuid string) ([]ProfileVersion, error) {
+	query := `
+	SELECT ` + profileVersionColumns + `
+	FROM ums_profile_versions AS v
+	INNER JOIN ums_users AS u ON u.profile_id = v.profile_id
+	WHERE u.id = ?
+	ORDER BY v.version DESC
+	`
+
+	versions := []ProfileVersion{}
+	err := r.DB.Read.SelectContext(ctx, &versions, query, uuid)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get profile history")
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetProfileAsOf returns the version of the profile of a user in effect at
+// asOf, or ErrProfileVersionNotFound if the profile did not exist yet.
+func (r *UserRepositoryMySQL) GetProfileAsOf(ctx context.Context, uuid string, asOf time.Time) (*ProfileVersion, error) {
+	query := `
+	SELECT ` + profileVersionColumns + `
+	FROM ums_profile_versions AS v
+	INNER JOIN ums_users AS u ON u.profile_id = v.profile_id
+	WHERE u.id = ? AND v.created_at <= ?
+	ORDER BY v.version DESC
+	LIMIT 1
+	`
+
+	var version ProfileVersion
+	err := r.DB.Read.GetContext(ctx, &version, query, uuid, asOf)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProfileVersionNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get profile version")
+		return nil, err
+	}
+	return &version, nil
+}
+
+// GetProfileVersion returns a version of the profile of a user, or
+// ErrProfileVersionNotFound if there is no such version.
+func (r *UserRepositoryMySQL) GetProfileVersion(ctx context.Context, uuid string, version int) (*ProfileVersion, error) {
+	query := `
+	SELECT ` + profileVersionColumns + `
+	FROM ums_profile_versions AS v
+	INNER JOIN ums_users AS u ON u.profile_id = v.profile_id
+	WHERE u.id = ? AND v.version = ?
+	`
+
+	var profileVersion ProfileVersion
+	err := r.DB.Read.GetContext(ctx, &profileVersion, query, uuid, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrProfileVersionNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get profile version")
+		return nil, err
+	}
+	return &profileVersion, nil
+}
+
+// RevertProfile restores the profile of a user to the values of profile, one
+// of its versions, which is recorded as a new version reverted from it, and
+// returns the new version.
+func (r *UserRepositoryMySQL) RevertProfile(ctx context.Context, uuid string, profile *ProfileVersion, updatedBy string) (*ProfileVersion, error) {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	revertQuery := `
+	UPDATE ums_profiles AS p
+	INNER JOIN ums_users AS u ON p.id = u.profile_id
+	SET
+		p.name = ?,
+		p.gender = ?,
+		p.dob = ?,
+		p.education = ?,
+		p.address = ?,
+		p.city = ?,
+		p.province = ?,
+		p.phone_number = ?,
+		p.updated_at = ?, p.updated_by = ?,
+		u.updated_at = ?, u.updated_by = ?
+	WHERE u.id = ?
+	`
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, revertQuery,
+		profile.Name,
+		profile.Gender,
+		profile.DoB,
+		profile.Education,
+		profile.Address,
+		profile.City,
+		profile.Province,
+		profile.PhoneNumber,
+		now, updatedBy,
+		now, updatedBy,
+		uuid)
+	if err != nil {
+		if isDuplicateEntry(err) {
+			return nil, ErrPhoneNumberExists
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to revert profile")
+		return nil, err
+	}
+
+	err = snapshotProfile(ctx, tx, uuid, &profile.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted ProfileVersion
+	err = tx.GetContext(ctx, &reverted, `
+	SELECT `+profileVersionColumns+`
+	FROM ums_profile_versions AS v
+	INNER JOIN ums_users AS u ON u.profile_id = v.profile_id
+	WHERE u.id = ?
+	ORDER BY v.version DESC
+	LIMIT 1
+	`, uuid)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get profile version")
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return nil, err
+	}
+	return &reverted, nil
+}
+
+// GetStatus returns the trainee status of a user, which is empty if the user
+// has none, or ErrNotFound if there is no such user.
+func (r *UserRepositoryMySQL) GetStatus(ctx context.Context, uuid string) (string, error) {
+	query := `
+	SELECT COALESCE(s.status, '')
+	FROM ums_users AS u
+	INNER JOIN ums_status AS s ON u.status_id = s.id
+	WHERE u.id = ?
+	`
+
+	var status string
+	err := r.DB.Read.GetContext(ctx, &status, query, uuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get status")
+		return "", err
+	}
+	return status, nil
+}
+
+// TransitionStatus moves a user from the FromStatus of history to its
+// ToStatus and records history. It returns ErrStatusChanged if the status of
+// the user is no longer FromStatus.
+func (r *UserRepositoryMySQL) TransitionStatus(ctx context.Context, history *StatusHistory) error {
+	tx, err := r.DB.Write.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	var from string
+	if history.FromStatus != nil {
+		from = *history.FromStatus
+	}
+
+	updateQuery := `
+	UPDATE ums_status AS s
+	INNER JOIN ums_users AS u ON u.status_id = s.id
+	SET s.status = ?, s.updated_at = ?, s.updated_by = ?
+	WHERE u.id = ? AND COALESCE(s.status, '') = ?
+	`
+	result, err := tx.ExecContext(ctx, updateQuery,
+		history.ToStatus,
+		history.CreatedAt,
+		history.CreatedBy,
+		history.UserID,
+		from,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to update status")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStatusChanged
+	}
+
+	historyQuery := `
+	INSERT INTO ums_status_history (id, user_id, from_status, to_status, reason, effective_date, created_at, created_by)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, historyQuery,
+		history.ID,
+		history.UserID,
+		history.FromStatus,
+		history.ToStatus,
+		history.Reason,
+		history.EffectiveDate,
+		history.CreatedAt,
+		history.CreatedBy,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to insert status history")
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// GetStatusHistory returns the status changes of a user, latest first.
+func (r *UserRepositoryMySQL) GetStatusHistory(ctx context.Context, uuid string) ([]StatusHistory, error) {
+	query := `
+	SELECT id, user_id, from_status, to_status, reason, DATE_FORMAT(effective_date, '%Y-%m-%d') AS effective_date, created_at, created_by
+	FROM ums_status_history
+	WHERE user_id = ?
+	ORDER BY created_at DESC
+	`
+
+	history := []StatusHistory{}
+	err := r.DB.Read.SelectContext(ctx, &history, query, uuid)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get status history")
+		return nil, err
+	}
+	return history, nil
+}
+
+// SetPhoto sets the photo of the profile of a user and returns the key of the
+// photo it replaced, if any.
+func (r *UserRepositoryMySQL) SetPhoto(ctx context.Context, uuid, photoKey, updatedBy string) (*string, error) {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previous struct {
+		ProfileID string  `db:"id"`
+		PhotoKey  *string `db:"photo_key"`
+	}
+	err = tx.GetContext(ctx, &previous, `
+	SELECT p.id, p.photo_key
+	FROM ums_profiles AS p
+	INNER JOIN ums_users AS u ON u.profile_id = p.id
+	WHERE u.id = ?
+	FOR UPDATE`, uuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get profile photo")
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	UPDATE ums_profiles
+	SET photo_key = ?, updated_at = ?, updated_by = ?
+	WHERE id = ?`, photoKey, time.Now(), updatedBy, previous.ProfileID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to set profile photo")
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return nil, err
+	}
+	return previous.PhotoKey, nil
+}
+
+// customFieldConditions returns the conditions restricting users to those
+// with the custom field values of filter, in key order, and their arguments,
+// two per condition.
+func customFieldConditions(filter UserFilter) ([]string, []interface{}) {
+	keys := make([]string, 0, len(filter.CustomFields))
+	for key := range filter.CustomFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := []string{}
+	args := []interface{}{}
+	for _, key := range keys {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1
+			FROM ums_custom_field_values AS cfv
+			INNER JOIN ums_custom_fields AS cf ON cf.id = cfv.field_id
+			WHERE cfv.user_id = u.id AND cf.field_key = ? AND cfv.value = ?)`)
+		args = append(args, key, filter.CustomFields[key])
+	}
+	return conditions, args
+}
+
+// customFieldColumns are the columns of ums_custom_fields selected into a
+// CustomField.
+const customFieldColumns = `
+	id, field_key, label, type, options, pattern, max_length, min_value, max_value,
+	required, visibility, created_at, created_by, updated_at, updated_by`
+
+// ListCustomFields returns the custom field definitions in key order.
+func (r *UserRepositoryMySQL) ListCustomFields(ctx context.Context) ([]CustomField, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM ums_custom_fields ORDER BY field_key`
+
+	fields := []CustomField{}
+	err := r.DB.Read.SelectContext(ctx, &fields, query)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to list custom fields")
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (r *UserRepositoryMySQL) GetCustomField(ctx context.Context, id string) (*CustomField, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM ums_custom_fields WHERE id = ?`
+
+	var field CustomField
+	err := r.DB.Read.GetContext(ctx, &field, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCustomFieldNotFound
+		}
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get custom field")
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (r *UserRepositoryMySQL) IsCustomFieldKeyExist(ctx context.Context, key string) (bool, error) {
+	query := "SELECT EXISTS(SELECT field_key FROM ums_custom_fields WHERE field_key = ? LIMIT 1)"
+
+	var exists bool
+	err := r.DB.Read.GetContext(ctx, &exists, query, key)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check custom field existence")
+		return false, err
+	}
+	return exists, nil
+}
+
+// IsPhoneNumberExist reports whether the profile of a user other than
+// excludeUUID has a phone number. It reads the primary, which replicas may
+// lag behind. The unique index the phone numbers migration adds still guards
+// against numbers taken between the check and the update.
+func (r *UserRepositoryMySQL) IsPhoneNumberExist(ctx context.Context, phoneNumber, excludeUUID string) (bool, error) {
+	query := `
+	SELECT EXISTS(
+		SELECT p.id
+		FROM ums_profiles AS p
+		INNER JOIN ums_users AS u ON u.profile_id = p.id
+		WHERE p.phone_number = ? AND u.id <> ?
+		LIMIT 1
+	)`
+
+	var exists bool
+	err := r.DB.Write.GetContext(ctx, &exists, query, phoneNumber, excludeUUID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check phone number existence")
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *UserRepositoryMySQL) CreateCustomField(ctx context.Context, field *CustomField) error {
+	query := `
+	INSERT INTO ums_custom_fields
+		(id, field_key, label, type, options, pattern, max_length, min_value, max_value, required, visibility, created_at, created_by)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.DB.Write.ExecContext(ctx, query,
+		field.ID, field.Key, field.Label, field.Type, field.Options, field.Pattern, field.MaxLength,
+		field.Min, field.Max, field.Required, field.Visibility, field.CreatedAt, field.CreatedBy)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to create custom field")
+		return err
+	}
+	return nil
+}
+
+// UpdateCustomField updates the definition of a custom field. Its key and type
+// cannot change, as stored values and API clients depend on them.
+func (r *UserRepositoryMySQL) UpdateCustomField(ctx context.Context, field *CustomField) error {
+	query := `
+	UPDATE ums_custom_fields
+	SET label = ?, options = ?, pattern = ?, max_length = ?, min_value = ?, max_value = ?,
+		required = ?, visibility = ?, updated_at = ?, updated_by = ?
+	WHERE id = ?
+	`
+
+	result, err := r.DB.Write.ExecContext(ctx, query,
+		field.Label, field.Options, field.Pattern, field.MaxLength, field.Min, field.Max,
+		field.Required, field.Visibility, field.UpdatedAt, field.UpdatedBy, field.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to update custom field")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+// DeleteCustomField deletes a custom field together with its values.
+func (r *UserRepositoryMySQL) DeleteCustomField(ctx context.Context, id string) error {
+	result, err := r.DB.Write.ExecContext(ctx, "DELETE FROM ums_custom_fields WHERE id = ?", id)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to delete custom field")
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+// GetCustomFieldValues returns the custom field values of users.
+func (r *UserRepositoryMySQL) GetCustomFieldValues(ctx context.Context, userIDs []string) ([]CustomFieldValue, error) {
+	values := []CustomFieldValue{}
+	if len(userIDs) == 0 {
+		return values, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT user_id, field_id, value FROM ums_custom_field_values WHERE user_id IN (?)`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.DB.Read.SelectContext(ctx, &values, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to get custom field values")
+		return nil, err
+	}
+	return values, nil
+}
+
+// SetCustomFieldValues sets custom field values of a user, or returns
+// ErrNotFound if the user does not exist.
+func (r *UserRepositoryMySQL) SetCustomFieldValues(ctx context.Context, uuid string, values []CustomFieldValue, updatedBy string) error {
+	tx, err := r.DB.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to start transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.GetContext(ctx, &exists, "SELECT EXISTS(SELECT id FROM ums_users WHERE id = ?)", uuid)
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to check user existence")
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+
+	err = setCustomFieldValues(ctx, tx, uuid, values, updatedBy, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to commit transaction")
+		return err
+	}
+	return nil
+}
+
+// setCustomFieldValues writes custom field values of a user in tx, deleting
+// those whose Value is nil.
+func setCustomFieldValues(ctx context.Context, tx *sqlx.Tx, userID string, values []CustomFieldValue, updatedBy string, now time.Time) error {
+	for _, value := range values {
+		var err error
+		if value.Value == nil {
+			_, err = tx.ExecContext(ctx, `
+			DELETE FROM ums_custom_field_values
+			WHERE user_id = ? AND field_id = ?`, userID, value.FieldID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+			INSERT INTO ums_custom_field_values (user_id, field_id, value, updated_at, updated_by)
+			VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE value = VALUES(value), updated_at = VALUES(updated_at), updated_by = VALUES(updated_by)`,
+				userID, value.FieldID, *value.Value, now, updatedBy)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error().Err(err).Msg("Failed to set custom field value")
+			return err
+		}
+	}
+	return nil
+}
+
 func lowercaseOrNil(s *string) interface{} {
 	if s != nil {
 		return strings.ToLower(*s)
 	}
 	return nil
 }
+
+// isDuplicateEntry reports whether err is a unique key violation, which on
+// profiles means a phone number another profile has.
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -1,35 +1,166 @@
 package users
 
-import "math"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/locations"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	"github.com/evermos/boilerplate-go/shared/imaging"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/phone"
+	"github.com/evermos/boilerplate-go/shared/storage"
+	"github.com/evermos/boilerplate-go/shared/tracing"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultPhoneRegion is the region of phone numbers written without calling
+// code when none is configured.
+const defaultPhoneRegion = "ID"
+
+// Profile photos are stored as a JPEG scaled to fit photoSize and a square
+// thumbnail of thumbnailSize pixels. Uploads of more than maxPhotoPixels
+// pixels are refused before decoding.
+const (
+	photoSize         = 1024
+	thumbnailSize     = 256
+	maxPhotoPixels    = 40000000
+	photoObject       = "photo.jpg"
+	thumbnailObject   = "thumbnail.jpg"
+	photoContentType  = "image/jpeg"
+	photoKeyPrefixFmt = "photos/%s/%s"
+)
 
 type UserService interface {
-	ReadUser(filter UserFilter, page, size int) (UserList, error)
-	GetProfile(uuid string) (*ProfileView, error)
-	UpdateProfile(uuid string, profile *UpdateProfile) (*UpdateProfile, error)
-	DeleteUserByID(uuid string) error
+	ReadUser(ctx context.Context, filter UserFilter, page, size int) (UserList, error)
+	GetProfile(ctx context.Context, uuid string) (*ProfileView, error)
+	UpdateProfile(ctx context.Context, uuid string, profile *UpdateProfile) (*UpdateProfile, error)
+	DeleteUserByID(ctx context.Context, uuid string) error
+	TransitionStatus(ctx context.Context, userID string, transition StatusTransition) (*StatusHistory, error)
+	GetStatusHistory(ctx context.Context, userID string) ([]StatusHistory, error)
+	GetProfileHistory(ctx context.Context, userID string) ([]ProfileVersion, error)
+	GetProfileAsOf(ctx context.Context, userID string, asOf time.Time) (*ProfileVersion, error)
+	RevertProfile(ctx context.Context, userID string, version int, updatedBy string) (*ProfileVersion, error)
+	UploadPhoto(ctx context.Context, userID string, data []byte, updatedBy string) (*Photo, error)
+	GetUserProfile(ctx context.Context, userID string) (*ProfileView, error)
+	SetCustomFields(ctx context.Context, userID string, values map[string]interface{}, updatedBy string) error
+	ListCustomFields(ctx context.Context) ([]CustomField, error)
+	CreateCustomField(ctx context.Context, field *CustomField) error
+	UpdateCustomField(ctx context.Context, field *CustomField) error
+	DeleteCustomField(ctx context.Context, id string) error
+}
+
+// LocationResolver matches free-text provinces and cities to the location
+// reference data.
+type LocationResolver interface {
+	ResolveLocation(ctx context.Context, province, city string) (*locations.Location, error)
 }
 
 type UserServiceImpl struct {
 	UserRepository UserRepository
+	Storage        storage.Storage
+	Locations      LocationResolver
+	// PhoneRegion is the region of phone numbers written without calling
+	// code, and UniquePhoneNumbers whether users may share a phone number.
+	PhoneRegion        string
+	UniquePhoneNumbers bool
 }
 
-func ProvideUserServiceImpl(userRepository UserRepository) *UserServiceImpl {
+func ProvideUserServiceImpl(userRepository UserRepository, storage storage.Storage, locations LocationResolver, config *configs.Config) *UserServiceImpl {
+	phoneRegion := config.App.Phones.DefaultRegion
+	if phoneRegion == "" {
+		phoneRegion = defaultPhoneRegion
+	}
+	if !phone.IsKnownRegion(phoneRegion) {
+		log.Fatal().Str("region", phoneRegion).Msg("Unsupported default phone region")
+	}
+
 	return &UserServiceImpl{
-		UserRepository: userRepository,
+		UserRepository:     userRepository,
+		Storage:            storage,
+		Locations:          locations,
+		PhoneRegion:        phoneRegion,
+		UniquePhoneNumbers: config.App.Phones.Unique,
 	}
 }
 
-func (s *UserServiceImpl) ReadUser(filter UserFilter, page, size int) (UserList, error) {
-	users, err := s.UserRepository.GetData(filter, page, size)
+// ReadUser lists users matching filter, with all their custom fields. The
+// custom field values and phone number of filter are validated and
+// normalized first, and its province and city are replaced by their reference
+// names when they match one, so that aliases such as "jabar" find the users
+// in Jawa Barat.
+func (s *UserServiceImpl) ReadUser(ctx context.Context, filter UserFilter, page, size int) (UserList, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.ReadUser")
+	defer span.End()
+
+	today := civil.Today()
+	err := ageFilter(&filter, today)
 	if err != nil {
 		return UserList{}, err
 	}
 
-	totalData, err := s.UserRepository.CountTotalData(filter)
+	if filter.PhoneNumber != "" {
+		phoneNumber, err := phone.Normalize(filter.PhoneNumber, s.PhoneRegion)
+		if err != nil {
+			return UserList{}, &ValidationError{"phoneNumber", "is not a valid phone number"}
+		}
+		filter.PhoneNumber = phoneNumber
+	}
+
+	err = s.normalizeLocationFilter(ctx, &filter)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return UserList{}, err
+	}
+
+	fields, err := s.UserRepository.ListCustomFields(ctx)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return UserList{}, err
 	}
 
+	filter.CustomFields, err = normalizeCustomFieldFilter(fields, filter.CustomFields)
+	if err != nil {
+		return UserList{}, err
+	}
+
+	users, err := s.UserRepository.GetData(ctx, filter, page, size)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return UserList{}, err
+	}
+
+	userIDs := make([]string, len(users))
+	for i := range users {
+		userIDs[i] = users[i].UserID
+	}
+	customFields, err := s.customFields(ctx, fields, userIDs, true)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return UserList{}, err
+	}
+
+	totalData, err := s.UserRepository.CountTotalData(ctx, filter)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return UserList{}, err
+	}
+
+	for i := range users {
+		users[i].Age = age(users[i].DoB, today)
+		users[i].PhotoURL, users[i].ThumbnailURL = s.photoURLs(users[i].PhotoKey)
+		users[i].CustomFields = customFields[users[i].UserID]
+		if users[i].CustomFields == nil {
+			users[i].CustomFields = map[string]interface{}{}
+		}
+	}
+
 	totalPages := int(math.Ceil(float64(totalData) / float64(size)))
 
 	var nextPage *int
@@ -56,14 +187,748 @@ func (s *UserServiceImpl) ReadUser(filter UserFilter, page, size int) (UserList,
 	return response, nil
 }
 
-func (s *UserServiceImpl) GetProfile(uuid string) (*ProfileView, error) {
-	return s.UserRepository.GetProfile(uuid)
+// GetProfile returns the profile of a user as the user sees it, with the
+// custom fields that are not hidden from them.
+func (s *UserServiceImpl) GetProfile(ctx context.Context, uuid string) (*ProfileView, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetProfile")
+	defer span.End()
+
+	profile, err := s.getProfile(ctx, uuid, false)
+	tracing.RecordError(span, err)
+	return profile, err
+}
+
+// GetUserProfile returns the profile of a user as admins see it, with all
+// custom fields.
+func (s *UserServiceImpl) GetUserProfile(ctx context.Context, uuid string) (*ProfileView, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetUserProfile")
+	defer span.End()
+
+	profile, err := s.getProfile(ctx, uuid, true)
+	tracing.RecordError(span, err)
+	return profile, err
+}
+
+func (s *UserServiceImpl) getProfile(ctx context.Context, uuid string, includeHidden bool) (*ProfileView, error) {
+	profile, err := s.UserRepository.GetProfile(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.UserRepository.ListCustomFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := s.customFields(ctx, fields, []string{uuid}, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Age = age(profile.DoB, civil.Today())
+	profile.PhotoURL, profile.ThumbnailURL = s.photoURLs(profile.PhotoKey)
+	profile.CustomFields = customFields[uuid]
+	if profile.CustomFields == nil {
+		profile.CustomFields = map[string]interface{}{}
+	}
+	return profile, nil
+}
+
+// UpdateProfile updates the profile of a user from the user themselves, who
+// may only change the custom fields visible to them for editing. The province
+// and city must be in the location reference data and are stored by their
+// reference names, the phone number is stored in E.164 form and the date of
+// birth cannot be in the future.
+func (s *UserServiceImpl) UpdateProfile(ctx context.Context, uuid string, profile *UpdateProfile) (*UpdateProfile, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.UpdateProfile")
+	defer span.End()
+
+	if profile.DoB != nil && profile.DoB.After(civil.Today()) {
+		return nil, &ValidationError{"dob", "cannot be in the future"}
+	}
+
+	err := s.normalizePhoneNumber(ctx, uuid, profile)
+	if err != nil {
+		if _, ok := err.(*ValidationError); !ok && err != ErrPhoneNumberExists {
+			tracing.RecordError(span, err)
+		}
+		return nil, err
+	}
+
+	err = s.resolveLocation(ctx, uuid, profile)
+	if err != nil {
+		if _, ok := err.(*ValidationError); !ok {
+			tracing.RecordError(span, err)
+		}
+		return nil, err
+	}
+
+	fields, err := s.UserRepository.ListCustomFields(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	profile.CustomFieldValues, err = customFieldValues(fields, profile.CustomFields, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.UserRepository.GetCustomFieldValues(ctx, []string{uuid})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	err = requireEditableFields(fields, stored, profile.CustomFieldValues)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.UserRepository.UpdateProfile(ctx, uuid, profile)
+	if err != nil && err != ErrPhoneNumberExists {
+		tracing.RecordError(span, err)
+	}
+	return updated, err
+}
+
+// ageFilter sets the bounds of the dates of birth of filter from its ages on
+// today. Someone born on today.AddYears(-n) turns n today.
+func ageFilter(filter *UserFilter, today civil.Date) error {
+	if filter.MinAge != nil {
+		if *filter.MinAge < 0 {
+			return &ValidationError{"minAge", "must not be negative"}
+		}
+		bound := today.AddYears(-*filter.MinAge)
+		filter.BornOnOrBefore = &bound
+	}
+	if filter.MaxAge != nil {
+		if *filter.MaxAge < 0 {
+			return &ValidationError{"maxAge", "must not be negative"}
+		}
+		if filter.MinAge != nil && *filter.MinAge > *filter.MaxAge {
+			return &ValidationError{"minAge", "must not be greater than maxAge"}
+		}
+		bound := today.AddYears(-*filter.MaxAge - 1)
+		filter.BornAfter = &bound
+	}
+	return nil
+}
+
+// age returns the age on today of someone born on dob, or nil if dob is not
+// known.
+func age(dob *civil.Date, today civil.Date) *int {
+	if dob == nil {
+		return nil
+	}
+	years := dob.Age(today)
+	return &years
+}
+
+// normalizePhoneNumber replaces the phone number of profile by its E.164
+// form, and checks that no other user has it when phone numbers are unique.
+// An empty phone number clears it.
+func (s *UserServiceImpl) normalizePhoneNumber(ctx context.Context, uuid string, profile *UpdateProfile) error {
+	if profile.PhoneNumber == nil || *profile.PhoneNumber == "" {
+		return nil
+	}
+
+	phoneNumber, err := phone.Normalize(*profile.PhoneNumber, s.PhoneRegion)
+	if err != nil {
+		return &ValidationError{"phoneNumber", "is not a valid phone number, such as +6281234567890"}
+	}
+	profile.PhoneNumber = &phoneNumber
+
+	if !s.UniquePhoneNumbers {
+		return nil
+	}
+	exists, err := s.UserRepository.IsPhoneNumberExist(ctx, phoneNumber, uuid)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrPhoneNumberExists
+	}
+	return nil
+}
+
+// resolveLocation checks the province and city of profile against the
+// location reference data and replaces them by their reference names. A city
+// also sets its province. A province changed alone must contain the city of
+// the profile, unless that city is free text predating the reference data.
+func (s *UserServiceImpl) resolveLocation(ctx context.Context, uuid string, profile *UpdateProfile) error {
+	province, city := stringValue(profile.Province), stringValue(profile.City)
+	if province == "" && city == "" {
+		return nil
+	}
+
+	keepsCity := profile.City == nil
+	if keepsCity {
+		current, err := s.UserRepository.GetProfile(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		city = stringValue(current.City)
+	}
+
+	location, err := s.Locations.ResolveLocation(ctx, province, city)
+	if keepsCity && (err == locations.ErrUnknownCity || err == locations.ErrAmbiguousCity) {
+		location, err = s.Locations.ResolveLocation(ctx, province, "")
+	}
+	switch err {
+	case nil:
+	case locations.ErrUnknownProvince:
+		return &ValidationError{"province", "is not a known province"}
+	case locations.ErrUnknownCity:
+		return &ValidationError{"city", "is not a known regency or city"}
+	case locations.ErrAmbiguousCity:
+		return &ValidationError{"city", "matches several regencies or cities, prefix it with Kota or Kabupaten"}
+	case locations.ErrCityNotInProvince:
+		if keepsCity {
+			return &ValidationError{"province", "does not contain the city of the profile, change the city as well"}
+		}
+		return &ValidationError{"city", "is not in the province"}
+	default:
+		return err
+	}
+
+	profile.Province = &location.Province.Name
+	if !keepsCity && location.City != nil {
+		profile.City = &location.City.FullName
+	}
+	return nil
+}
+
+// resolveVersionLocation replaces the province and city of a profile version
+// by their reference names, like an update setting both would, so a revert
+// cannot restore free text the reference data replaced.
+func (s *UserServiceImpl) resolveVersionLocation(ctx context.Context, uuid string, version *ProfileVersion) error {
+	city := stringValue(version.City)
+	profile := &UpdateProfile{Province: version.Province, City: &city}
+	err := s.resolveLocation(ctx, uuid, profile)
+	if err != nil {
+		return err
+	}
+
+	version.Province = profile.Province
+	if version.City != nil {
+		version.City = profile.City
+	}
+	return nil
+}
+
+// normalizeLocationFilter replaces the province and city of filter by their
+// reference names when they match one. A city without a Kota or Kabupaten
+// prefix keeps matching both the regency and the city of its name. Values
+// that match nothing are kept, to find the profiles whose free text could not
+// be mapped.
+func (s *UserServiceImpl) normalizeLocationFilter(ctx context.Context, filter *UserFilter) error {
+	if filter.Province != "" {
+		location, err := s.Locations.ResolveLocation(ctx, filter.Province, "")
+		switch err {
+		case nil:
+			filter.Province = location.Province.Name
+		case locations.ErrUnknownProvince:
+		default:
+			return err
+		}
+	}
+
+	if filter.City != "" {
+		location, err := s.Locations.ResolveLocation(ctx, "", filter.City)
+		switch err {
+		case nil:
+			if locations.Key(filter.City) == locations.Key(location.City.FullName) {
+				filter.City = location.City.FullName
+			} else {
+				filter.City = location.City.Name
+			}
+		case locations.ErrUnknownCity, locations.ErrAmbiguousCity:
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) DeleteUserByID(ctx context.Context, uuid string) error {
+	ctx, span := tracing.StartSpan(ctx, "UserService.DeleteUserByID")
+	defer span.End()
+
+	err := s.UserRepository.DeleteUserByID(ctx, uuid)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// TransitionStatus moves a trainee to another status, rejecting transitions
+// the workflow does not allow, and records the change in the history.
+func (s *UserServiceImpl) TransitionStatus(ctx context.Context, userID string, transition StatusTransition) (*StatusHistory, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.TransitionStatus")
+	defer span.End()
+
+	if !IsValidStatus(transition.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	from, err := s.UserRepository.GetStatus(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	if !CanTransition(from, transition.Status) {
+		logger.FromContext(ctx).Info().
+			Str("from_status", from).
+			Str("to_status", transition.Status).
+			Msg("Rejected illegal status transition")
+		return nil, ErrIllegalTransition
+	}
+
+	now := time.Now()
+	effectiveDate := transition.EffectiveDate
+	if effectiveDate == "" {
+		effectiveDate = now.Format(civil.Layout)
+	}
+
+	history := &StatusHistory{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		ToStatus:      transition.Status,
+		Reason:        transition.Reason,
+		EffectiveDate: effectiveDate,
+		CreatedAt:     now,
+		CreatedBy:     transition.CreatedBy,
+	}
+	if from != "" {
+		history.FromStatus = &from
+	}
+
+	err = s.UserRepository.TransitionStatus(ctx, history)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("target_user_id", userID).
+		Str("from_status", from).
+		Str("to_status", transition.Status).
+		Msg("Trainee status changed")
+	return history, nil
+}
+
+func (s *UserServiceImpl) GetStatusHistory(ctx context.Context, userID string) ([]StatusHistory, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetStatusHistory")
+	defer span.End()
+
+	history, err := s.UserRepository.GetStatusHistory(ctx, userID)
+	tracing.RecordError(span, err)
+	return history, err
+}
+
+func (s *UserServiceImpl) GetProfileHistory(ctx context.Context, userID string) ([]ProfileVersion, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetProfileHistory")
+	defer span.End()
+
+	versions, err := s.UserRepository.GetProfileHistory(ctx, userID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	// Users have versions from their first change on, so an empty history
+	// may mean there is no such user.
+	if len(versions) == 0 {
+		_, err = s.UserRepository.GetProfile(ctx, userID)
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+// GetProfileAsOf returns the profile of a user as it was at asOf.
+func (s *UserServiceImpl) GetProfileAsOf(ctx context.Context, userID string, asOf time.Time) (*ProfileVersion, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.GetProfileAsOf")
+	defer span.End()
+
+	version, err := s.UserRepository.GetProfileAsOf(ctx, userID, asOf)
+	tracing.RecordError(span, err)
+	return version, err
+}
+
+// RevertProfile restores the profile of a user to an earlier version. The
+// revert is itself recorded as a new version, so it can be undone. The
+// location and phone number of the version are checked and normalized like
+// in an update.
+func (s *UserServiceImpl) RevertProfile(ctx context.Context, userID string, version int, updatedBy string) (*ProfileVersion, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.RevertProfile")
+	defer span.End()
+
+	profile, err := s.UserRepository.GetProfileVersion(ctx, userID, version)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	err = s.resolveVersionLocation(ctx, userID, profile)
+	if err != nil {
+		if _, ok := err.(*ValidationError); !ok {
+			tracing.RecordError(span, err)
+		}
+		return nil, err
+	}
+
+	update := &UpdateProfile{PhoneNumber: profile.PhoneNumber}
+	err = s.normalizePhoneNumber(ctx, userID, update)
+	if err != nil {
+		if _, ok := err.(*ValidationError); !ok && err != ErrPhoneNumberExists {
+			tracing.RecordError(span, err)
+		}
+		return nil, err
+	}
+	profile.PhoneNumber = update.PhoneNumber
+
+	reverted, err := s.UserRepository.RevertProfile(ctx, userID, profile, updatedBy)
+	if err != nil {
+		if err != ErrPhoneNumberExists {
+			tracing.RecordError(span, err)
+		}
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info().
+		Str("target_user_id", userID).
+		Int("reverted_from", version).
+		Int("version", reverted.Version).
+		Msg("Profile reverted")
+	return reverted, nil
+}
+
+// UploadPhoto sets the photo of a user. The image is scaled down and
+// re-encoded as JPEG, which also drops any metadata it carried, and a square
+// thumbnail is made from it. The photo it replaces is deleted.
+func (s *UserServiceImpl) UploadPhoto(ctx context.Context, userID string, data []byte, updatedBy string) (*Photo, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.UploadPhoto")
+	defer span.End()
+
+	img, _, err := imaging.Decode(data, maxPhotoPixels)
+	if err != nil {
+		if err == imaging.ErrTooManyPixels {
+			return nil, ErrPhotoTooLarge
+		}
+		return nil, ErrInvalidPhoto
+	}
+
+	img = imaging.Fit(img, photoSize)
+	photo, err := imaging.JPEG(img)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	thumbnail, err := imaging.JPEG(imaging.Thumbnail(img, thumbnailSize))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	// Every upload gets new keys, so that caches never serve a stale photo.
+	key := fmt.Sprintf(photoKeyPrefixFmt, userID, uuid.New().String())
+	if err := s.Storage.Put(ctx, key+"/"+photoObject, photo, photoContentType); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to store photo")
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+	if err := s.Storage.Put(ctx, key+"/"+thumbnailObject, thumbnail, photoContentType); err != nil {
+		logger.FromContext(ctx).Error().Err(err).Msg("Failed to store thumbnail")
+		tracing.RecordError(span, err)
+		s.deletePhoto(ctx, key)
+		return nil, err
+	}
+
+	previous, err := s.UserRepository.SetPhoto(ctx, userID, key, updatedBy)
+	if err != nil {
+		tracing.RecordError(span, err)
+		s.deletePhoto(ctx, key)
+		return nil, err
+	}
+	if previous != nil {
+		s.deletePhoto(ctx, *previous)
+	}
+
+	logger.FromContext(ctx).Info().Str("target_user_id", userID).Msg("Profile photo updated")
+	photoURL, thumbnailURL := s.photoURLs(&key)
+	return &Photo{PhotoURL: *photoURL, ThumbnailURL: *thumbnailURL}, nil
+}
+
+// photoURLs returns the URLs of the photo and thumbnail stored under key, or
+// nil if there is no photo.
+func (s *UserServiceImpl) photoURLs(key *string) (photoURL, thumbnailURL *string) {
+	if key == nil || *key == "" {
+		return nil, nil
+	}
+	photo := s.Storage.URL(*key + "/" + photoObject)
+	thumbnail := s.Storage.URL(*key + "/" + thumbnailObject)
+	return &photo, &thumbnail
+}
+
+// deletePhoto deletes the objects of the photo stored under key. Failures are
+// only logged: an orphaned object is not worth failing the request for.
+func (s *UserServiceImpl) deletePhoto(ctx context.Context, key string) {
+	for _, object := range []string{photoObject, thumbnailObject} {
+		if err := s.Storage.Delete(ctx, key+"/"+object); err != nil {
+			logger.FromContext(ctx).Warn().Err(err).Str("key", key+"/"+object).Msg("Failed to delete photo")
+		}
+	}
+}
+
+// SetCustomFields sets custom field values of a user from an admin, who may
+// change every custom field.
+func (s *UserServiceImpl) SetCustomFields(ctx context.Context, userID string, values map[string]interface{}, updatedBy string) error {
+	ctx, span := tracing.StartSpan(ctx, "UserService.SetCustomFields")
+	defer span.End()
+
+	fields, err := s.UserRepository.ListCustomFields(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	customValues, err := customFieldValues(fields, values, false)
+	if err != nil {
+		return err
+	}
+
+	err = s.UserRepository.SetCustomFieldValues(ctx, userID, customValues, updatedBy)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (s *UserServiceImpl) ListCustomFields(ctx context.Context) ([]CustomField, error) {
+	ctx, span := tracing.StartSpan(ctx, "UserService.ListCustomFields")
+	defer span.End()
+
+	fields, err := s.UserRepository.ListCustomFields(ctx)
+	tracing.RecordError(span, err)
+	return fields, err
 }
 
-func (s *UserServiceImpl) UpdateProfile(uuid string, profile *UpdateProfile) (*UpdateProfile, error) {
-	return s.UserRepository.UpdateProfile(uuid, profile)
+// CreateCustomField defines a new custom field, hidden from users unless its
+// visibility says otherwise.
+func (s *UserServiceImpl) CreateCustomField(ctx context.Context, field *CustomField) error {
+	ctx, span := tracing.StartSpan(ctx, "UserService.CreateCustomField")
+	defer span.End()
+
+	if field.Visibility == "" {
+		field.Visibility = VisibilityHidden
+	}
+	if err := field.Validate(); err != nil {
+		return err
+	}
+
+	exists, err := s.UserRepository.IsCustomFieldKeyExist(ctx, field.Key)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	if exists {
+		return ErrCustomFieldKeyExists
+	}
+
+	field.ID = uuid.New().String()
+	field.CreatedAt = time.Now()
+
+	err = s.UserRepository.CreateCustomField(ctx, field)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("field_key", field.Key).Msg("Custom field created")
+	return nil
 }
 
-func (s *UserServiceImpl) DeleteUserByID(uuid string) error {
-	return s.UserRepository.DeleteUserByID(uuid)
+// UpdateCustomField changes the definition of a custom field. Its key and type
+// are kept: changing them would invalidate the stored values.
+func (s *UserServiceImpl) UpdateCustomField(ctx context.Context, field *CustomField) error {
+	ctx, span := tracing.StartSpan(ctx, "UserService.UpdateCustomField")
+	defer span.End()
+
+	existing, err := s.UserRepository.GetCustomField(ctx, field.ID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if field.Key != "" && field.Key != existing.Key {
+		return &ValidationError{"key", "cannot be changed"}
+	}
+	if field.Type != "" && field.Type != existing.Type {
+		return &ValidationError{"type", "cannot be changed"}
+	}
+	field.Key = existing.Key
+	field.Type = existing.Type
+	if field.Visibility == "" {
+		field.Visibility = existing.Visibility
+	}
+	if err := field.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	field.CreatedAt = existing.CreatedAt
+	field.CreatedBy = existing.CreatedBy
+	field.UpdatedAt = &now
+
+	err = s.UserRepository.UpdateCustomField(ctx, field)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("field_key", field.Key).Msg("Custom field updated")
+	return nil
+}
+
+// DeleteCustomField deletes a custom field and the values users have for it.
+func (s *UserServiceImpl) DeleteCustomField(ctx context.Context, id string) error {
+	ctx, span := tracing.StartSpan(ctx, "UserService.DeleteCustomField")
+	defer span.End()
+
+	err := s.UserRepository.DeleteCustomField(ctx, id)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	logger.FromContext(ctx).Info().Str("field_id", id).Msg("Custom field deleted")
+	return nil
+}
+
+// customFields returns the custom field values of users by user ID and key,
+// leaving out the fields hidden from users unless includeHidden is set.
+func (s *UserServiceImpl) customFields(ctx context.Context, fields []CustomField, userIDs []string, includeHidden bool) (map[string]map[string]interface{}, error) {
+	values, err := s.UserRepository.GetCustomFieldValues(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*CustomField{}
+	for i := range fields {
+		if includeHidden || fields[i].Visibility != VisibilityHidden {
+			byID[fields[i].ID] = &fields[i]
+		}
+	}
+
+	customFields := map[string]map[string]interface{}{}
+	for _, value := range values {
+		field, ok := byID[value.FieldID]
+		if !ok || value.Value == nil {
+			continue
+		}
+		if customFields[value.UserID] == nil {
+			customFields[value.UserID] = map[string]interface{}{}
+		}
+		customFields[value.UserID][field.Key] = field.DecodeValue(*value.Value)
+	}
+	return customFields, nil
+}
+
+// customFieldValues validates custom field values given by key against their
+// definitions. A nil value clears a field unless it is required. With
+// editableOnly, only fields users may edit can be set.
+func customFieldValues(fields []CustomField, values map[string]interface{}, editableOnly bool) ([]CustomFieldValue, error) {
+	byKey := map[string]*CustomField{}
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	customValues := make([]CustomFieldValue, 0, len(keys))
+	for _, key := range keys {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, &ValidationError{key, "is not a custom field"}
+		}
+		if editableOnly && field.Visibility != VisibilityEdit {
+			return nil, &ValidationError{key, "cannot be changed"}
+		}
+
+		value := CustomFieldValue{FieldID: field.ID}
+		if values[key] == nil {
+			if field.Required {
+				return nil, &ValidationError{key, "is required"}
+			}
+		} else {
+			normalized, err := field.NormalizeValue(values[key])
+			if err != nil {
+				return nil, err
+			}
+			value.Value = &normalized
+		}
+		customValues = append(customValues, value)
+	}
+	return customValues, nil
+}
+
+// requireEditableFields checks that every required field users can edit has
+// a value once updates are applied over the stored values of a user. Required
+// hidden and read fields are filled by admins, so users are not asked for them.
+func requireEditableFields(fields []CustomField, stored []CustomFieldValue, updates []CustomFieldValue) error {
+	values := map[string]*string{}
+	for _, value := range stored {
+		values[value.FieldID] = value.Value
+	}
+	for _, value := range updates {
+		values[value.FieldID] = value.Value
+	}
+
+	for _, field := range fields {
+		if field.Required && field.Visibility == VisibilityEdit && values[field.ID] == nil {
+			return &ValidationError{field.Key, "is required"}
+		}
+	}
+	return nil
+}
+
+// normalizeCustomFieldFilter validates the custom field values of a users
+// filter and returns them normalized like stored values.
+func normalizeCustomFieldFilter(fields []CustomField, filter map[string]string) (map[string]string, error) {
+	if len(filter) == 0 {
+		return filter, nil
+	}
+
+	byKey := map[string]*CustomField{}
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	normalized := map[string]string{}
+	for key, value := range filter {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, &ValidationError{key, "is not a custom field"}
+		}
+		normalizedValue, err := field.NormalizeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = normalizedValue
+	}
+	return normalized, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
@@ -0,0 +1,245 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/internal/domain/locations"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomFieldValues(t *testing.T) {
+	fields := []CustomField{
+		{ID: "1", Key: "shirt_size", Type: FieldTypeSelect, Options: FieldOptions{"S", "M", "L"}, Visibility: VisibilityEdit},
+		{ID: "2", Key: "bank_account", Type: FieldTypeText, Required: true, Visibility: VisibilityEdit},
+		{ID: "3", Key: "grade", Type: FieldTypeNumber, Visibility: VisibilityHidden},
+	}
+
+	values, err := customFieldValues(fields, map[string]interface{}{"shirt_size": "L", "grade": 3.0}, false)
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	assert.Equal(t, "3", values[0].FieldID)
+	assert.Equal(t, "3", *values[0].Value)
+	assert.Equal(t, "1", values[1].FieldID)
+	assert.Equal(t, "L", *values[1].Value)
+
+	values, err = customFieldValues(fields, map[string]interface{}{"shirt_size": nil}, true)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.Nil(t, values[0].Value)
+
+	_, err = customFieldValues(fields, map[string]interface{}{"grade": 3.0}, true)
+	assert.EqualError(t, err, "grade cannot be changed")
+
+	_, err = customFieldValues(fields, map[string]interface{}{"bank_account": nil}, true)
+	assert.EqualError(t, err, "bank_account is required")
+
+	_, err = customFieldValues(fields, map[string]interface{}{"unknown": "x"}, false)
+	assert.EqualError(t, err, "unknown is not a custom field")
+}
+
+func TestRequireEditableFields(t *testing.T) {
+	fields := []CustomField{
+		{ID: "1", Key: "bank_account", Type: FieldTypeText, Required: true, Visibility: VisibilityEdit},
+		{ID: "2", Key: "employee_id", Type: FieldTypeText, Required: true, Visibility: VisibilityRead},
+		{ID: "3", Key: "shirt_size", Type: FieldTypeText, Visibility: VisibilityEdit},
+	}
+	stored := []CustomFieldValue{{FieldID: "1", Value: stringPtr("123")}}
+
+	assert.NoError(t, requireEditableFields(fields, stored, nil))
+	assert.NoError(t, requireEditableFields(fields, nil, []CustomFieldValue{{FieldID: "1", Value: stringPtr("456")}}))
+
+	err := requireEditableFields(fields, nil, []CustomFieldValue{{FieldID: "3", Value: stringPtr("L")}})
+	assert.EqualError(t, err, "bank_account is required")
+}
+
+func TestNormalizeCustomFieldFilter(t *testing.T) {
+	fields := []CustomField{{ID: "1", Key: "grade", Type: FieldTypeNumber}}
+
+	filter, err := normalizeCustomFieldFilter(fields, map[string]string{"grade": "3.50"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"grade": "3.5"}, filter)
+
+	_, err = normalizeCustomFieldFilter(fields, map[string]string{"grade": "high"})
+	assert.EqualError(t, err, "grade must be a number")
+}
+
+func TestAgeFilter(t *testing.T) {
+	today := civil.Date{Year: 2024, Month: 3, Day: 15}
+	minAge, maxAge := 18, 25
+
+	filter := UserFilter{MinAge: &minAge, MaxAge: &maxAge}
+	require.NoError(t, ageFilter(&filter, today))
+	assert.Equal(t, civil.Date{Year: 2006, Month: 3, Day: 15}, *filter.BornOnOrBefore)
+	assert.Equal(t, civil.Date{Year: 1998, Month: 3, Day: 15}, *filter.BornAfter)
+
+	// Someone born on the day after the upper bound is maxAge today.
+	born := *filter.BornAfter
+	born.Day++
+	assert.Equal(t, maxAge, born.Age(today))
+	assert.Equal(t, minAge, filter.BornOnOrBefore.Age(today))
+
+	filter = UserFilter{}
+	require.NoError(t, ageFilter(&filter, today))
+	assert.Nil(t, filter.BornOnOrBefore)
+	assert.Nil(t, filter.BornAfter)
+
+	negative := -1
+	err := ageFilter(&UserFilter{MinAge: &negative}, today)
+	assert.Equal(t, &ValidationError{"minAge", "must not be negative"}, err)
+
+	err = ageFilter(&UserFilter{MinAge: &maxAge, MaxAge: &minAge}, today)
+	assert.Equal(t, &ValidationError{"minAge", "must not be greater than maxAge"}, err)
+}
+
+// fakeUserRepository keeps profiles and their versions in memory. Methods the
+// tests do not use are left to the embedded nil UserRepository.
+type fakeUserRepository struct {
+	UserRepository
+	profiles map[string]*ProfileView
+	versions map[string][]ProfileVersion
+}
+
+func (r *fakeUserRepository) GetProfile(ctx context.Context, uuid string) (*ProfileView, error) {
+	profile, ok := r.profiles[uuid]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return profile, nil
+}
+
+func (r *fakeUserRepository) GetProfileHistory(ctx context.Context, uuid string) ([]ProfileVersion, error) {
+	versions := []ProfileVersion{}
+	for i := len(r.versions[uuid]) - 1; i >= 0; i-- {
+		versions = append(versions, r.versions[uuid][i])
+	}
+	return versions, nil
+}
+
+func (r *fakeUserRepository) GetProfileAsOf(ctx context.Context, uuid string, asOf time.Time) (*ProfileVersion, error) {
+	for i := len(r.versions[uuid]) - 1; i >= 0; i-- {
+		if !r.versions[uuid][i].CreatedAt.After(asOf) {
+			version := r.versions[uuid][i]
+			return &version, nil
+		}
+	}
+	return nil, ErrProfileVersionNotFound
+}
+
+func (r *fakeUserRepository) GetProfileVersion(ctx context.Context, uuid string, version int) (*ProfileVersion, error) {
+	for _, v := range r.versions[uuid] {
+		if v.Version == version {
+			return &v, nil
+		}
+	}
+	return nil, ErrProfileVersionNotFound
+}
+
+func (r *fakeUserRepository) RevertProfile(ctx context.Context, uuid string, profile *ProfileVersion, updatedBy string) (*ProfileVersion, error) {
+	reverted := *profile
+	revertedFrom := profile.Version
+	reverted.Version = len(r.versions[uuid]) + 1
+	reverted.RevertedFrom = &revertedFrom
+	reverted.CreatedAt = time.Now()
+	reverted.CreatedBy = &updatedBy
+	r.versions[uuid] = append(r.versions[uuid], reverted)
+	return &reverted, nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// indexResolver resolves locations against the bundled dataset.
+type indexResolver struct {
+	*locations.Index
+}
+
+func (r indexResolver) ResolveLocation(ctx context.Context, province, city string) (*locations.Location, error) {
+	return r.Resolve(province, city)
+}
+
+func newProfileHistoryService(t *testing.T) (*UserServiceImpl, *fakeUserRepository) {
+	index, err := locations.NewIndex(locations.Provinces, locations.Cities, locations.Aliases)
+	require.NoError(t, err)
+
+	repo := &fakeUserRepository{
+		profiles: map[string]*ProfileView{"u1": {}, "u2": {}, "u3": {}},
+		versions: map[string][]ProfileVersion{
+			"u1": {
+				{Version: 1, Name: stringPtr("ani"), City: stringPtr("bandung"), PhoneNumber: stringPtr("0812-3456-7890"), CreatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+				{Version: 2, Name: stringPtr("ani rahma"), City: stringPtr("Kota Surabaya"), CreatedAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+			},
+			"u3": {
+				{Version: 1, City: stringPtr("atlantis"), CreatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+	return &UserServiceImpl{UserRepository: repo, Locations: indexResolver{index}, PhoneRegion: "ID"}, repo
+}
+
+func TestGetProfileHistory(t *testing.T) {
+	service, _ := newProfileHistoryService(t)
+	ctx := context.Background()
+
+	versions, err := service.GetProfileHistory(ctx, "u1")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, 1, versions[1].Version)
+
+	// A user whose profile never changed has no versions yet.
+	versions, err = service.GetProfileHistory(ctx, "u2")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	_, err = service.GetProfileHistory(ctx, "unknown")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestGetProfileAsOf(t *testing.T) {
+	service, _ := newProfileHistoryService(t)
+	ctx := context.Background()
+
+	version, err := service.GetProfileAsOf(ctx, "u1", time.Date(2026, 1, 1, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 1, version.Version)
+
+	version, err = service.GetProfileAsOf(ctx, "u1", time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 2, version.Version)
+
+	_, err = service.GetProfileAsOf(ctx, "u1", time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC))
+	assert.Equal(t, ErrProfileVersionNotFound, err)
+}
+
+func TestRevertProfile(t *testing.T) {
+	service, repo := newProfileHistoryService(t)
+	ctx := context.Background()
+
+	reverted, err := service.RevertProfile(ctx, "u1", 1, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, 3, reverted.Version)
+	require.NotNil(t, reverted.RevertedFrom)
+	assert.Equal(t, 1, *reverted.RevertedFrom)
+	assert.Equal(t, "ani", *reverted.Name)
+	// Locations and phone numbers are normalized like in updates.
+	assert.Equal(t, "Kota Bandung", *reverted.City)
+	assert.Equal(t, "Jawa Barat", *reverted.Province)
+	assert.Equal(t, "+6281234567890", *reverted.PhoneNumber)
+	assert.Equal(t, "admin", *reverted.CreatedBy)
+	// The versions it was reverted from are kept.
+	assert.Len(t, repo.versions["u1"], 3)
+
+	_, err = service.RevertProfile(ctx, "u1", 4, "admin")
+	assert.Equal(t, ErrProfileVersionNotFound, err)
+	assert.Len(t, repo.versions["u1"], 3)
+
+	_, err = service.RevertProfile(ctx, "u3", 1, "admin")
+	assert.EqualError(t, err, "city is not a known regency or city")
+	assert.Len(t, repo.versions["u3"], 1)
+}
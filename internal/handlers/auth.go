@@ -3,8 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/evermos/boilerplate-go/internal/domain/auth"
+	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	context_helpers "github.com/evermos/boilerplate-go/shared/context"
+	"github.com/evermos/boilerplate-go/shared/oauth"
 	"github.com/evermos/boilerplate-go/transport/http/middleware"
 	"github.com/go-chi/chi"
 )
@@ -12,22 +18,57 @@ import (
 type AuthHandler struct {
 	AuthService    auth.AuthService
 	Authentication *middleware.Authentication
+	RateLimiter    *middleware.RateLimiter
 }
 
-func ProvideAuthHandler(service auth.AuthService, auth *middleware.Authentication) AuthHandler {
+func ProvideAuthHandler(service auth.AuthService, auth *middleware.Authentication, rateLimiter *middleware.RateLimiter) AuthHandler {
 	return AuthHandler{
 		AuthService:    service,
 		Authentication: auth,
+		RateLimiter:    rateLimiter,
 	}
 }
 
 // Router sets up the router for this domain.
 func (h *AuthHandler) Router(r chi.Router) {
 	r.Route("/auth", func(r chi.Router) {
-		r.Post("/login", h.Login)
+		r.With(h.RateLimiter.Login).Post("/login", h.Login)
+		r.With(h.RateLimiter.LoginGroup("signup")).Post("/signup", h.Signup)
+		r.With(h.RateLimiter.LoginGroup("verify-email")).Post("/verify-email", h.VerifyEmail)
+		r.With(h.RateLimiter.LoginGroup("verify-email")).Post("/verify-email/resend", h.ResendVerification)
+		r.With(h.RateLimiter.LoginGroup("mfa")).Post("/mfa/verify", h.VerifyMFA)
 		r.Group(func(r chi.Router) {
 			r.Use(h.Authentication.VerifyJWT)
+			r.Use(h.Authentication.RequireScope(oauth.ScopeMFAEnroll))
+			r.Use(h.RateLimiter.Default)
+			r.Post("/mfa/enroll", h.EnrollMFA)
+			r.Post("/mfa/confirm", h.ConfirmMFA)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.Authentication.VerifyToken)
+			r.Use(h.RateLimiter.Default)
+			r.Group(func(r chi.Router) {
+				r.Use(h.Authentication.RequireUser)
+				r.With(h.Authentication.RequireScope(oauth.ScopeProfileRead)).Get("/sessions", h.ListSessions)
+				r.With(h.Authentication.RequireScope(oauth.ScopeProfileWrite)).Delete("/sessions/{id}", h.RevokeSession)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(h.Authentication.IsAdmin)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/signups", h.ListPendingSignups)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/signups/{uuid}/approve", h.ApproveSignup)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/signups/{uuid}/reject", h.RejectSignup)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users/{uuid}/sessions", h.ListUserSessions)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Delete("/users/{uuid}/sessions", h.RevokeUserSessions)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/users/{uuid}/suspend", h.SuspendAccount)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/users/{uuid}/reactivate", h.ReactivateAccount)
+				r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Put("/users/{uuid}/valid-until", h.SetValidUntil)
+			})
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.Authentication.VerifyToken)
 			r.Use(h.Authentication.IsAdmin)
+			r.Use(h.Authentication.RequireScope(oauth.ScopeUsersWrite))
+			r.Use(h.RateLimiter.Register)
 			r.Post("/register", h.Register)
 		})
 	})
@@ -48,18 +89,45 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.AuthService.Login(req.Username, req.Password)
+	result, err := h.AuthService.Login(r.Context(), req.Username, req.Password, h.device(r))
 	if err != nil {
 		if err == auth.ErrNotFound {
 			http.Error(w, "User not found", http.StatusUnauthorized)
 		} else if err == auth.ErrUnauthorized {
 			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		} else if err == auth.ErrAccountInactive {
+			http.Error(w, "Account is not active", http.StatusForbidden)
 		} else {
 			http.Error(w, "Failed to authenticate user", http.StatusInternalServerError)
 		}
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if req.ChallengeToken == "" || req.Code == "" {
+		http.Error(w, "challengeToken and code fields are required", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.AuthService.VerifyMFA(r.Context(), req.ChallengeToken, req.Code, h.device(r))
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	response := map[string]string{
 		"token": token,
@@ -67,11 +135,158 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (h *AuthHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	enrollment, err := h.AuthService.EnrollMFA(r.Context(), principal.ID, principal.Username)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+func (h *AuthHandler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if req.Code == "" {
+		http.Error(w, "code field is required", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	codes, err := h.AuthService.ConfirmMFA(r.Context(), principal.ID, req.Code)
+	if err != nil {
+		writeMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"recoveryCodes": codes,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.AuthService.ListSessions(r.Context(), principal.ID, principal.SessionID)
+	if err != nil {
+		http.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.AuthService.RevokeSession(r.Context(), principal.ID, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == auth.ErrSessionNotFound {
+			http.Error(w, "Session not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "Session revoked successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) ListUserSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.AuthService.ListSessions(r.Context(), chi.URLParam(r, "uuid"), "")
+	if err != nil {
+		http.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+func (h *AuthHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	revoked, err := h.AuthService.RevokeAllSessions(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "Sessions revoked successfully",
+		"revoked": revoked,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// device returns the client of a request, recorded with the session it
+// starts.
+func (h *AuthHandler) device(r *http.Request) auth.Device {
+	return auth.Device{
+		IPAddress: h.RateLimiter.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func writeMFAError(w http.ResponseWriter, err error) {
+	switch err {
+	case auth.ErrMFAInvalidChallenge:
+		http.Error(w, "Invalid or expired MFA challenge", http.StatusUnauthorized)
+	case auth.ErrMFAInvalidCode:
+		http.Error(w, "Invalid MFA code", http.StatusUnauthorized)
+	case auth.ErrMFALocked:
+		http.Error(w, "Too many failed MFA attempts, try again later", http.StatusTooManyRequests)
+	case auth.ErrAccountInactive:
+		http.Error(w, "Account is not active", http.StatusForbidden)
+	case auth.ErrMFANotEnrolled:
+		http.Error(w, "MFA is not enrolled", http.StatusBadRequest)
+	case auth.ErrMFAAlreadyEnabled:
+		http.Error(w, "MFA is already enabled", http.StatusConflict)
+	default:
+		http.Error(w, "Failed to process MFA request", http.StatusInternalServerError)
+	}
+}
+
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
-		Role     string `json:"role"`
+		Username   string  `json:"username"`
+		Password   string  `json:"password"`
+		Role       string  `json:"role"`
+		Email      string  `json:"email"`
+		ValidUntil *string `json:"validUntil"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -83,19 +298,41 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "username, password, and role fields are required", http.StatusBadRequest)
 		return
 	}
-	adminUser := r.Context().Value("username").(string)
+	if req.Email != "" && shared.GetValidator().Var(req.Email, "email") != nil {
+		http.Error(w, "email must be a valid email address", http.StatusBadRequest)
+		return
+	}
+	if !validDate(req.ValidUntil) {
+		http.Error(w, "validUntil must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	adminUser := principal.Username
 	user := &auth.User{
-		Username:  req.Username,
-		Password:  req.Password,
-		Role:      req.Role,
-		CreatedBy: adminUser,
-		UpdatedBy: adminUser,
+		Username:   req.Username,
+		Password:   req.Password,
+		Role:       req.Role,
+		ValidUntil: req.ValidUntil,
+		CreatedBy:  adminUser,
+		UpdatedBy:  adminUser,
+	}
+	if req.Email != "" {
+		email := strings.ToLower(strings.TrimSpace(req.Email))
+		user.Email = &email
 	}
 
-	err := h.AuthService.Register(user)
+	err = h.AuthService.Register(r.Context(), user)
 	if err != nil {
 		if err == auth.ErrUserExist {
 			http.Error(w, "Username is already exist", http.StatusConflict)
+		} else if err == auth.ErrEmailExist {
+			http.Error(w, "Email is already exist", http.StatusConflict)
+		} else if err == auth.ErrValidUntilPassed {
+			http.Error(w, "validUntil must not be in the past", http.StatusBadRequest)
 		} else {
 			http.Error(w, "Failed to register user", http.StatusInternalServerError)
 		}
@@ -109,3 +346,293 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewEncoder(w).Encode(response)
 }
+
+func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	var req auth.Signup
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := shared.GetValidator().Struct(req); err != nil {
+		http.Error(w, "username, password (at least 8 characters) and a valid email are required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.AuthService.Signup(r.Context(), req)
+	if err != nil {
+		if err == auth.ErrUserExist {
+			http.Error(w, "Username is already exist", http.StatusConflict)
+		} else if err == auth.ErrEmailExist {
+			http.Error(w, "Email is already exist", http.StatusConflict)
+		} else {
+			http.Error(w, "Failed to sign up", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	response := map[string]interface{}{
+		"message": "Check your email to verify your account",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" {
+		http.Error(w, "token field is required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.AuthService.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		if err == auth.ErrInvalidVerificationToken {
+			http.Error(w, "Invalid or expired verification token", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "Email verified, your account is awaiting approval",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if req.Email == "" {
+		http.Error(w, "email field is required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.AuthService.ResendVerification(r.Context(), req.Email)
+	if err != nil {
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	response := map[string]interface{}{
+		"message": "If the email belongs to an unverified account, a verification email has been sent",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) ListPendingSignups(w http.ResponseWriter, r *http.Request) {
+	users, err := h.AuthService.ListPendingSignups(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to fetch pending signups", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+func (h *AuthHandler) ApproveSignup(w http.ResponseWriter, r *http.Request) {
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.AuthService.ApproveSignup(r.Context(), chi.URLParam(r, "uuid"), principal.Username)
+	if err != nil {
+		writeSignupReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "User approved successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) RejectSignup(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.AuthService.RejectSignup(r.Context(), chi.URLParam(r, "uuid"), principal.Username, req.Reason)
+	if err != nil {
+		writeSignupReviewError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "User rejected successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) SuspendAccount(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "reason field is required", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "uuid")
+	if userID == principal.ID {
+		http.Error(w, "Cannot suspend your own account", http.StatusBadRequest)
+		return
+	}
+
+	err = h.AuthService.SuspendAccount(r.Context(), userID, principal.Username, req.Reason)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "Account suspended successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) ReactivateAccount(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason     string  `json:"reason"`
+		ValidUntil *string `json:"validUntil"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "reason field is required", http.StatusBadRequest)
+		return
+	}
+	if !validDate(req.ValidUntil) {
+		http.Error(w, "validUntil must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.AuthService.ReactivateAccount(r.Context(), chi.URLParam(r, "uuid"), principal.Username, req.Reason, req.ValidUntil)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "Account reactivated successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *AuthHandler) SetValidUntil(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ValidUntil *string `json:"validUntil"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if !validDate(req.ValidUntil) {
+		http.Error(w, "validUntil must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.AuthService.SetValidUntil(r.Context(), chi.URLParam(r, "uuid"), req.ValidUntil, principal.Username)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]interface{}{
+		"message": "Valid until date updated successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch err {
+	case auth.ErrNotFound:
+		http.Error(w, "User not found", http.StatusNotFound)
+	case auth.ErrAccountStatusConflict:
+		http.Error(w, "Account status does not allow this change", http.StatusConflict)
+	case auth.ErrValidUntilPassed:
+		http.Error(w, "validUntil must not be in the past", http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to update account", http.StatusInternalServerError)
+	}
+}
+
+// validDate reports whether date is nil or in YYYY-MM-DD format.
+func validDate(date *string) bool {
+	if date == nil {
+		return true
+	}
+	_, err := time.Parse(civil.Layout, *date)
+	return err == nil
+}
+
+func writeSignupReviewError(w http.ResponseWriter, err error) {
+	if err == auth.ErrNotPendingApproval {
+		http.Error(w, "User is not pending approval", http.StatusConflict)
+		return
+	}
+	http.Error(w, "Failed to review signup", http.StatusInternalServerError)
+}
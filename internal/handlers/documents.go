@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/internal/domain/documents"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	context_helpers "github.com/evermos/boilerplate-go/shared/context"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/go-chi/chi"
+)
+
+// defaultMaxDocumentSize is the largest document upload accepted when none
+// is configured.
+const defaultMaxDocumentSize = 10 << 20
+
+// documentContentTypes are the types of documents that can be uploaded.
+var documentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+type DocumentHandler struct {
+	DocumentService documents.DocumentService
+	Authentication  *middleware.Authentication
+	RateLimiter     *middleware.RateLimiter
+	MaxDocumentSize int64
+}
+
+func ProvideDocumentHandler(service documents.DocumentService, auth *middleware.Authentication, rateLimiter *middleware.RateLimiter, config *configs.Config) DocumentHandler {
+	maxDocumentSize := config.App.Documents.MaxSizeBytes
+	if maxDocumentSize <= 0 {
+		maxDocumentSize = defaultMaxDocumentSize
+	}
+
+	return DocumentHandler{
+		DocumentService: service,
+		Authentication:  auth,
+		RateLimiter:     rateLimiter,
+		MaxDocumentSize: maxDocumentSize,
+	}
+}
+
+// Router sets up the router for this domain. Users can read their own
+// documents; admins manage the documents of every user.
+func (h *DocumentHandler) Router(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.Authentication.VerifyToken)
+		r.Use(h.RateLimiter.Default)
+		r.Group(func(r chi.Router) {
+			r.Use(h.Authentication.RequireUser)
+			r.With(h.Authentication.RequireScope(oauth.ScopeProfileRead)).Get("/profiles/documents", h.ListOwnDocuments)
+			r.With(h.Authentication.RequireScope(oauth.ScopeProfileRead)).Get("/profiles/documents/{id}/download", h.DownloadOwnDocument)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(h.Authentication.IsAdmin)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users/{uuid}/documents", h.ListDocuments)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users/{uuid}/documents/{id}/download", h.DownloadDocument)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/users/{uuid}/documents", h.UploadDocument)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Delete("/users/{uuid}/documents/{id}", h.DeleteDocument)
+		})
+	})
+}
+
+func (h *DocumentHandler) ListOwnDocuments(w http.ResponseWriter, r *http.Request) {
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	h.listDocuments(w, r, principal.ID)
+}
+
+func (h *DocumentHandler) DownloadOwnDocument(w http.ResponseWriter, r *http.Request) {
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	h.downloadDocument(w, r, principal.ID)
+}
+
+func (h *DocumentHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	h.listDocuments(w, r, chi.URLParam(r, "uuid"))
+}
+
+func (h *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	h.downloadDocument(w, r, chi.URLParam(r, "uuid"))
+}
+
+// UploadDocument adds a document to a user from a multipart form with the
+// document in the file field, its type, and optionally its expiresOn date
+// and the SHA-256 checksum of the file.
+func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxDocumentSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.MaxDocumentSize); err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			http.Error(w, "Document is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file field is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(file, h.MaxDocumentSize+1))
+	if err != nil {
+		http.Error(w, "Failed to read document", http.StatusBadRequest)
+		return
+	}
+	if int64(len(content)) > h.MaxDocumentSize {
+		http.Error(w, "Document is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	contentType := http.DetectContentType(content)
+	if !documentContentTypes[contentType] {
+		http.Error(w, "file must be a PDF, JPEG or PNG document", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	upload := documents.Upload{
+		UserID:      chi.URLParam(r, "uuid"),
+		Type:        r.FormValue("type"),
+		FileName:    documentFileName(header.Filename),
+		ContentType: contentType,
+		Checksum:    strings.TrimSpace(r.FormValue("checksum")),
+		Content:     content,
+	}
+
+	if !documents.IsValidType(upload.Type) {
+		http.Error(w, "type must be one of contract, certificate, identity or other", http.StatusBadRequest)
+		return
+	}
+	if expiresOn := r.FormValue("expiresOn"); expiresOn != "" {
+		if _, err := time.Parse(civil.Layout, expiresOn); err != nil {
+			http.Error(w, "expiresOn must be in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		upload.ExpiresOn = &expiresOn
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	upload.CreatedBy = principal.Username
+
+	document, err := h.DocumentService.UploadDocument(r.Context(), upload)
+	if err != nil {
+		switch err {
+		case documents.ErrChecksumMismatch:
+			http.Error(w, "Document does not match its checksum", http.StatusBadRequest)
+		case documents.ErrUserNotFound:
+			http.Error(w, "User not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to upload document", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(document)
+}
+
+func (h *DocumentHandler) DeleteDocument(w http.ResponseWriter, r *http.Request) {
+	err := h.DocumentService.DeleteDocument(r.Context(), chi.URLParam(r, "uuid"), chi.URLParam(r, "id"))
+	if err != nil {
+		if err == documents.ErrNotFound {
+			http.Error(w, "Document not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete document", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	response := map[string]interface{}{
+		"message": "Document deleted successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *DocumentHandler) listDocuments(w http.ResponseWriter, r *http.Request, userID string) {
+	list, err := h.DocumentService.ListDocuments(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch documents", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (h *DocumentHandler) downloadDocument(w http.ResponseWriter, r *http.Request, userID string) {
+	document, content, err := h.DocumentService.DownloadDocument(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == documents.ErrNotFound {
+			http.Error(w, "Document not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to download document", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", document.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("X-Checksum-Sha256", document.Checksum)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
+
+// documentFileName returns the base name of an uploaded file name, limited to
+// the size of the file_name column.
+func documentFileName(name string) string {
+	name = filepath.Base(strings.Replace(name, "\\", "/", -1))
+	if name == "." || name == "/" {
+		name = "document"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
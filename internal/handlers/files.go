@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/shared/storage"
+	"github.com/go-chi/chi"
+)
+
+type FilesHandler struct {
+	Storage storage.Storage
+}
+
+func ProvideFilesHandler(storage storage.Storage) FilesHandler {
+	return FilesHandler{
+		Storage: storage,
+	}
+}
+
+// Router serves the stored objects, such as profile photos, when the storage
+// serves them itself. Other storages serve their objects from their own URLs.
+func (h *FilesHandler) Router(r chi.Router) {
+	handler, ok := h.Storage.(http.Handler)
+	if !ok {
+		return
+	}
+	r.Handle("/files/*", http.StripPrefix("/files", handler))
+}
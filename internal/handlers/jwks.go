@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/shared/jwks"
+	"github.com/go-chi/chi"
+)
+
+type JWKSHandler struct {
+	KeySet *jwks.KeySet
+}
+
+func ProvideJWKSHandler(keySet *jwks.KeySet) JWKSHandler {
+	return JWKSHandler{
+		KeySet: keySet,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *JWKSHandler) Router(r chi.Router) {
+	r.Get("/.well-known/jwks.json", h.GetJWKS)
+}
+
+// GetJWKS publishes the public keys JWTs are verified with, so that other
+// services can verify our tokens without holding a secret.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.KeySet.JWKS())
+}
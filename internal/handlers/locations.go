@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/internal/domain/locations"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/go-chi/chi"
+)
+
+type LocationHandler struct {
+	LocationService locations.LocationService
+	Authentication  *middleware.Authentication
+	RateLimiter     *middleware.RateLimiter
+}
+
+func ProvideLocationHandler(service locations.LocationService, auth *middleware.Authentication, rateLimiter *middleware.RateLimiter) LocationHandler {
+	return LocationHandler{
+		LocationService: service,
+		Authentication:  auth,
+		RateLimiter:     rateLimiter,
+	}
+}
+
+// Router sets up the router for this domain. The lookups list the provinces
+// and cities profiles accept.
+func (h *LocationHandler) Router(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.Authentication.VerifyToken)
+		r.Use(h.RateLimiter.Default)
+		r.Use(h.Authentication.RequireScope(oauth.ScopeProfileRead))
+		r.Get("/locations/provinces", h.ListProvinces)
+		r.Get("/locations/cities", h.ListCities)
+	})
+}
+
+func (h *LocationHandler) ListProvinces(w http.ResponseWriter, r *http.Request) {
+	provinces, err := h.LocationService.ListProvinces(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to fetch provinces", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(provinces)
+}
+
+// ListCities lists the regencies and cities of the province given by code,
+// name or alias in the province query parameter.
+func (h *LocationHandler) ListCities(w http.ResponseWriter, r *http.Request) {
+	province := r.URL.Query().Get("province")
+	if province == "" {
+		http.Error(w, "province is required", http.StatusBadRequest)
+		return
+	}
+
+	cities, err := h.LocationService.ListCities(r.Context(), province)
+	if err != nil {
+		if err == locations.ErrUnknownProvince {
+			http.Error(w, "Province not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch cities", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(cities)
+}
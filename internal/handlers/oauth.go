@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/go-chi/chi"
+)
+
+type OAuthHandler struct {
+	Token       *oauth.Token
+	RateLimiter *middleware.RateLimiter
+}
+
+func ProvideOAuthHandler(db *infras.MySQLConn, config *configs.Config, rateLimiter *middleware.RateLimiter) OAuthHandler {
+	return OAuthHandler{
+		// Tokens are written on issuance, so the store must use the write connection.
+		Token: oauth.New(db.Write, oauth.Config{
+			Expiration:         config.App.OAuth.AccessTokenExpirySeconds,
+			RefreshExpiration:  config.App.OAuth.RefreshTokenExpirySeconds,
+			RequireMFAForAdmin: config.App.MFA.RequireForAdmin,
+		}),
+		RateLimiter: rateLimiter,
+	}
+}
+
+// Router sets up the router for this domain.
+func (h *OAuthHandler) Router(r chi.Router) {
+	r.Route("/oauth", func(r chi.Router) {
+		r.With(h.RateLimiter.LoginGroup("oauth-token")).Post("/token", h.IssueToken)
+		r.With(h.RateLimiter.Default).Post("/revoke", h.RevokeToken)
+		r.With(h.RateLimiter.Default).Post("/introspect", h.IntrospectToken)
+	})
+}
+
+// IssueToken is the OAuth 2.0 token endpoint (RFC 6749 section 3.2). It supports
+// the client_credentials, password and refresh_token grants. Clients authenticate with HTTP
+// Basic authentication or with client_id and client_secret form parameters.
+func (h *OAuthHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, oauth.NewError(oauth.ErrCodeInvalidRequest, "Invalid form payload"))
+		return
+	}
+
+	clientID, clientSecret, basicAuth, err := clientCredentials(r)
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	grantType := r.PostForm.Get("grant_type")
+	if grantType == "" || clientID == "" {
+		writeOAuthError(w, oauth.NewError(oauth.ErrCodeInvalidRequest, oauth.ErrorMissingParameter))
+		return
+	}
+
+	token, err := h.Token.Create(oauth.Credential{
+		GrantType:    oauth.GrantType(grantType),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Username:     r.PostForm.Get("username"),
+		Password:     r.PostForm.Get("password"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+	})
+	if err != nil {
+		oauthErr, ok := err.(*oauth.Error)
+		if !ok {
+			logger.FromContext(r.Context()).Error().Err(err).Msg("Failed to issue access token")
+			oauthErr = oauth.NewError(oauth.ErrCodeServerError, "")
+		}
+		if oauthErr.Code == oauth.ErrCodeInvalidClient && basicAuth {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		writeOAuthError(w, oauthErr)
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, token)
+}
+
+// RevokeToken is the OAuth 2.0 token revocation endpoint (RFC 7009). Clients
+// can only revoke their own tokens. Unknown tokens are reported as revoked, so
+// the response does not reveal whether a token exists.
+func (h *OAuthHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		writeOAuthError(w, oauth.NewError(oauth.ErrCodeInvalidRequest, oauth.ErrorMissingParameter))
+		return
+	}
+
+	err := h.Token.Revoke(client, token, oauth.TokenTypeHint(r.PostForm.Get("token_type_hint")))
+	if err != nil {
+		logger.FromContext(r.Context()).Error().Err(err).Msg("Failed to revoke token")
+		writeOAuthError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// IntrospectToken is the OAuth 2.0 token introspection endpoint (RFC 7662).
+// Only authenticated clients may introspect tokens.
+func (h *OAuthHandler) IntrospectToken(w http.ResponseWriter, r *http.Request) {
+	_, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		writeOAuthError(w, oauth.NewError(oauth.ErrCodeInvalidRequest, oauth.ErrorMissingParameter))
+		return
+	}
+
+	introspection, err := h.Token.Introspect(token, oauth.TokenTypeHint(r.PostForm.Get("token_type_hint")))
+	if err != nil {
+		logger.FromContext(r.Context()).Error().Err(err).Msg("Failed to introspect token")
+		writeOAuthError(w, err)
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, introspection)
+}
+
+// authenticateClient parses the form and authenticates the client of a
+// revocation or introspection request, writing the error response on failure.
+func (h *OAuthHandler) authenticateClient(w http.ResponseWriter, r *http.Request) (oauth.OauthClient, bool) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, oauth.NewError(oauth.ErrCodeInvalidRequest, "Invalid form payload"))
+		return oauth.OauthClient{}, false
+	}
+
+	clientID, clientSecret, basicAuth, err := clientCredentials(r)
+	if err == nil && clientID == "" {
+		err = oauth.NewError(oauth.ErrCodeInvalidClient, oauth.ErrorInvalidClient)
+	}
+
+	var client oauth.OauthClient
+	if err == nil {
+		client, err = h.Token.AuthenticateClient(oauth.Credential{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+		})
+	}
+	if err != nil {
+		oauthErr, ok := err.(*oauth.Error)
+		if !ok {
+			logger.FromContext(r.Context()).Error().Err(err).Msg("Failed to authenticate client")
+			oauthErr = oauth.NewError(oauth.ErrCodeServerError, "")
+		}
+		if oauthErr.Code == oauth.ErrCodeInvalidClient && basicAuth {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		writeOAuthError(w, oauthErr)
+		return oauth.OauthClient{}, false
+	}
+
+	return client, true
+}
+
+// clientCredentials extracts the client credentials from the Authorization
+// header or, failing that, from the form parameters. Using both is rejected
+// as required by RFC 6749 section 2.3.1.
+func clientCredentials(r *http.Request) (clientID, clientSecret string, basicAuth bool, err error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret"), false, nil
+	}
+
+	if r.PostForm.Get("client_secret") != "" {
+		return "", "", true, oauth.NewError(oauth.ErrCodeInvalidRequest, "Multiple client authentication methods used")
+	}
+
+	// Basic credentials are form-encoded before being base64-encoded.
+	clientID, errID := url.QueryUnescape(username)
+	clientSecret, errSecret := url.QueryUnescape(password)
+	if errID != nil || errSecret != nil {
+		return "", "", true, oauth.NewError(oauth.ErrCodeInvalidClient, oauth.ErrorInvalidClient)
+	}
+	return clientID, clientSecret, true, nil
+}
+
+func writeOAuthError(w http.ResponseWriter, err error) {
+	oauthErr, ok := err.(*oauth.Error)
+	if !ok {
+		oauthErr = oauth.NewError(oauth.ErrCodeServerError, "")
+	}
+	writeOAuthJSON(w, oauthErr.StatusCode(), oauthErr)
+}
+
+// writeOAuthJSON writes a token endpoint response, which must never be cached.
+func writeOAuthJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(payload)
+}
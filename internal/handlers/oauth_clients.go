@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/evermos/boilerplate-go/transport/http/middleware"
+	"github.com/go-chi/chi"
+)
+
+type OAuthClientHandler struct {
+	Clients        *oauth.Clients
+	Authentication *middleware.Authentication
+	RateLimiter    *middleware.RateLimiter
+}
+
+func ProvideOAuthClientHandler(db *infras.MySQLConn, auth *middleware.Authentication, rateLimiter *middleware.RateLimiter) OAuthClientHandler {
+	return OAuthClientHandler{
+		Clients:        oauth.NewClients(db.Write),
+		Authentication: auth,
+		RateLimiter:    rateLimiter,
+	}
+}
+
+// Router sets up the router for this domain. Managing OAuth clients is
+// restricted to admins.
+func (h *OAuthClientHandler) Router(r chi.Router) {
+	r.Route("/oauth/clients", func(r chi.Router) {
+		r.Use(h.Authentication.VerifyToken)
+		r.Use(h.Authentication.IsAdmin)
+		r.Use(h.Authentication.RequireScope(oauth.ScopeClientsWrite))
+		r.Use(h.RateLimiter.Default)
+		r.Post("/", h.CreateClient)
+		r.Post("/{clientID}/secret", h.RotateClientSecret)
+	})
+}
+
+// CreateClient registers a new OAuth client. The generated client secret is
+// only returned in this response.
+func (h *OAuthClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	var req oauth.ClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	client, err := h.Clients.Create(req)
+	if err != nil {
+		writeClientError(w, r, err, "Failed to create OAuth client")
+		return
+	}
+
+	writeClientSecret(w, http.StatusCreated, client)
+}
+
+// RotateClientSecret replaces the secret of an OAuth client. The new secret
+// is only returned in this response and the old one stops working at once.
+func (h *OAuthClientHandler) RotateClientSecret(w http.ResponseWriter, r *http.Request) {
+	client, err := h.Clients.RotateSecret(chi.URLParam(r, "clientID"))
+	if err != nil {
+		writeClientError(w, r, err, "Failed to rotate OAuth client secret")
+		return
+	}
+
+	writeClientSecret(w, http.StatusOK, client)
+}
+
+func writeClientError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if f, ok := err.(*failure.Failure); ok {
+		http.Error(w, f.Message, f.Code)
+		return
+	}
+
+	logger.FromContext(r.Context()).Error().Err(err).Msg(message)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// writeClientSecret writes a response carrying a client secret, which must
+// never be cached.
+func writeClientSecret(w http.ResponseWriter, code int, client oauth.ClientSecretResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(client)
+}
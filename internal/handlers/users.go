@@ -1,39 +1,93 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/evermos/boilerplate-go/configs"
 	"github.com/evermos/boilerplate-go/internal/domain/users"
+	"github.com/evermos/boilerplate-go/shared/civil"
 	context_helpers "github.com/evermos/boilerplate-go/shared/context"
+	"github.com/evermos/boilerplate-go/shared/oauth"
 	"github.com/evermos/boilerplate-go/transport/http/middleware"
 	"github.com/go-chi/chi"
 )
 
+// maxStatusReasonLength is the size of the reason column of the status
+// history.
+const maxStatusReasonLength = 500
+
+// defaultMaxPhotoSize is the largest photo upload accepted when none is
+// configured, and multipartOverhead the room left for the rest of the
+// multipart request.
+const (
+	defaultMaxPhotoSize = 5 << 20
+	multipartOverhead   = 64 << 10
+)
+
+// customFieldParamPrefix prefixes the query parameters filtering users on
+// custom fields.
+const customFieldParamPrefix = "cf."
+
+// photoContentTypes are the image types accepted for profile photos.
+var photoContentTypes = map[string]bool{
+	"image/gif":  true,
+	"image/jpeg": true,
+	"image/png":  true,
+}
+
 type UserHandler struct {
 	UserService    users.UserService
 	Authentication *middleware.Authentication
+	RateLimiter    *middleware.RateLimiter
+	MaxPhotoSize   int64
 }
 
-func ProvideUserHandler(service users.UserService, auth *middleware.Authentication) UserHandler {
+func ProvideUserHandler(service users.UserService, auth *middleware.Authentication, rateLimiter *middleware.RateLimiter, config *configs.Config) UserHandler {
+	maxPhotoSize := config.App.Photos.MaxSizeBytes
+	if maxPhotoSize <= 0 {
+		maxPhotoSize = defaultMaxPhotoSize
+	}
+
 	return UserHandler{
 		UserService:    service,
 		Authentication: auth,
+		RateLimiter:    rateLimiter,
+		MaxPhotoSize:   maxPhotoSize,
 	}
 }
 
 func (h *UserHandler) Router(r chi.Router) {
 	r.Route("/", func(r chi.Router) {
-		r.Use(h.Authentication.VerifyJWT)
-		r.Get("/profiles", h.GetProfile)
-		r.Patch("/profiles", h.UpdateProfile)
+		r.Use(h.Authentication.VerifyToken)
+		r.Use(h.RateLimiter.Default)
+		r.Group(func(r chi.Router) {
+			r.Use(h.Authentication.RequireUser)
+			r.With(h.Authentication.RequireScope(oauth.ScopeProfileRead)).Get("/profiles", h.GetProfile)
+			r.With(h.Authentication.RequireScope(oauth.ScopeProfileWrite)).Patch("/profiles", h.UpdateProfile)
+			r.With(h.Authentication.RequireScope(oauth.ScopeProfileWrite)).Put("/profiles/photo", h.UploadPhoto)
+		})
 		r.Group(func(r chi.Router) {
 			r.Use(h.Authentication.IsAdmin)
-			r.Get("/users", h.ReadUser)
-			r.Delete("/users/{uuid}", h.DeleteUserByID)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users", h.ReadUser)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Delete("/users/{uuid}", h.DeleteUserByID)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/users/{uuid}/status", h.TransitionStatus)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users/{uuid}/status/history", h.GetStatusHistory)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users/{uuid}/profile", h.GetUserProfile)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/users/{uuid}/profile/history", h.GetProfileHistory)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/users/{uuid}/profile/revert", h.RevertProfile)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Put("/users/{uuid}/custom-fields", h.SetCustomFields)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersRead)).Get("/custom-fields", h.ListCustomFields)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Post("/custom-fields", h.CreateCustomField)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Put("/custom-fields/{id}", h.UpdateCustomField)
+			r.With(h.Authentication.RequireScope(oauth.ScopeUsersWrite)).Delete("/custom-fields/{id}", h.DeleteCustomField)
 		})
 	})
 }
@@ -43,11 +97,36 @@ func (h *UserHandler) ReadUser(w http.ResponseWriter, r *http.Request) {
 	name := q.Get("name")
 	city := q.Get("city")
 	province := q.Get("province")
+	phoneNumber := q.Get("phoneNumber")
 	jobRole := q.Get("jobRole")
 	status := q.Get("status")
 	page, _ := strconv.Atoi(q.Get("page"))
 	size, _ := strconv.Atoi(q.Get("size"))
 
+	if status != "" && !users.IsValidStatus(status) {
+		http.Error(w, "status must be one of applied, onboarding, active, on_leave, graduated or terminated", http.StatusBadRequest)
+		return
+	}
+
+	minAge, err := optionalIntParam(q, "minAge")
+	if err != nil {
+		http.Error(w, "minAge must be a number", http.StatusBadRequest)
+		return
+	}
+	maxAge, err := optionalIntParam(q, "maxAge")
+	if err != nil {
+		http.Error(w, "maxAge must be a number", http.StatusBadRequest)
+		return
+	}
+	birthMonth := 0
+	if q.Get("birthMonth") != "" {
+		birthMonth, err = strconv.Atoi(q.Get("birthMonth"))
+		if err != nil || birthMonth < 1 || birthMonth > 12 {
+			http.Error(w, "birthMonth must be a month number from 1 to 12", http.StatusBadRequest)
+			return
+		}
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -55,16 +134,33 @@ func (h *UserHandler) ReadUser(w http.ResponseWriter, r *http.Request) {
 		size = 5
 	}
 
-	response, err := h.UserService.ReadUser(users.UserFilter{
-		Name:     name,
-		City:     city,
-		Province: province,
-		JobRole:  jobRole,
-		Status:   status,
+	// Custom fields are filtered on with cf.<key>=<value>.
+	customFields := map[string]string{}
+	for param, values := range q {
+		if strings.HasPrefix(param, customFieldParamPrefix) && len(values) > 0 {
+			customFields[strings.TrimPrefix(param, customFieldParamPrefix)] = values[0]
+		}
+	}
+
+	response, err := h.UserService.ReadUser(r.Context(), users.UserFilter{
+		Name:         name,
+		City:         city,
+		Province:     province,
+		PhoneNumber:  phoneNumber,
+		MinAge:       minAge,
+		MaxAge:       maxAge,
+		BirthMonth:   birthMonth,
+		JobRole:      jobRole,
+		Status:       status,
+		CustomFields: customFields,
 	},
 		page,
 		size)
 	if err != nil {
+		if validationErr, ok := err.(*users.ValidationError); ok {
+			http.Error(w, validationErr.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
 		return
 	}
@@ -77,7 +173,7 @@ func (h *UserHandler) ReadUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
 	uuid := chi.URLParam(r, "uuid")
 
-	err := h.UserService.DeleteUserByID(uuid)
+	err := h.UserService.DeleteUserByID(r.Context(), uuid)
 	if err != nil {
 		if strings.Contains(err.Error(), "admin role") {
 			http.Error(w, "Cannot delete user with admin role or user not found", http.StatusForbidden)
@@ -96,12 +192,12 @@ func (h *UserHandler) DeleteUserByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	uuid, err := context_helpers.GetUserIDFromContext(r)
+	principal, err := context_helpers.GetPrincipal(r)
 	if err != nil {
-		http.Error(w, "Failed to get user ID from context", http.StatusInternalServerError)
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
 		return
 	}
-	profile, err := h.UserService.GetProfile(uuid)
+	profile, err := h.UserService.GetProfile(r.Context(), principal.ID)
 	if err != nil {
 		http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
 		return
@@ -114,44 +210,425 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	var update users.UpdateProfile
 	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		if err == civil.ErrInvalidDate {
+			http.Error(w, "dob must be in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	updatedBy, err := context_helpers.GetUsernameFromContext(r)
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	update.UpdatedBy = principal.Username
+	uuid := principal.ID
+
+	_, err = h.UserService.UpdateProfile(r.Context(), uuid, &update)
+	if err != nil {
+		if validationErr, ok := err.(*users.ValidationError); ok {
+			http.Error(w, validationErr.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == users.ErrPhoneNumberExists {
+			http.Error(w, "Phone number is already in use", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	response := map[string]interface{}{
+		"message": "Profile updated successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UploadPhoto sets the photo of the current user from the image in the photo
+// field of a multipart form.
+func (h *UserHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxPhotoSize+multipartOverhead)
+	if err := r.ParseMultipartForm(h.MaxPhotoSize); err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			http.Error(w, "Photo is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("photo")
 	if err != nil {
-		http.Error(w, "Failed to get username from context", http.StatusInternalServerError)
+		http.Error(w, "photo field is required", http.StatusBadRequest)
 		return
 	}
-	update.UpdatedBy = updatedBy
+	defer file.Close()
 
-	uuid, err := context_helpers.GetUserIDFromContext(r)
+	data, err := ioutil.ReadAll(io.LimitReader(file, h.MaxPhotoSize+1))
 	if err != nil {
-		http.Error(w, "Failed to get user ID from context", http.StatusInternalServerError)
+		http.Error(w, "Failed to read photo", http.StatusBadRequest)
+		return
+	}
+	if int64(len(data)) > h.MaxPhotoSize {
+		http.Error(w, "Photo is too large", http.StatusRequestEntityTooLarge)
 		return
 	}
 
-	if update.DoB != nil && *update.DoB != "" {
-		_, err := time.Parse("2006-01-02", *update.DoB)
+	// Both the declared type and the sniffed content must be a supported
+	// image.
+	declared := header.Header.Get("Content-Type")
+	if declared != "" && !photoContentTypes[strings.ToLower(declared)] {
+		http.Error(w, "photo must be a JPEG, PNG or GIF image", http.StatusUnsupportedMediaType)
+		return
+	}
+	if !photoContentTypes[http.DetectContentType(data)] {
+		http.Error(w, "photo must be a JPEG, PNG or GIF image", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+
+	photo, err := h.UserService.UploadPhoto(r.Context(), principal.ID, data, principal.Username)
+	if err != nil {
+		switch err {
+		case users.ErrInvalidPhoto:
+			http.Error(w, "photo must be a JPEG, PNG or GIF image", http.StatusUnsupportedMediaType)
+		case users.ErrPhotoTooLarge:
+			http.Error(w, "Photo dimensions are too large", http.StatusRequestEntityTooLarge)
+		case users.ErrNotFound:
+			http.Error(w, "Profile not found", http.StatusNotFound)
+		default:
+			http.Error(w, "Failed to upload photo", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(photo)
+}
+
+func (h *UserHandler) TransitionStatus(w http.ResponseWriter, r *http.Request) {
+	var transition users.StatusTransition
+	if err := json.NewDecoder(r.Body).Decode(&transition); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if transition.Status == "" || strings.TrimSpace(transition.Reason) == "" {
+		http.Error(w, "status and reason fields are required", http.StatusBadRequest)
+		return
+	}
+	if len(transition.Reason) > maxStatusReasonLength {
+		http.Error(w, "reason must be at most 500 characters", http.StatusBadRequest)
+		return
+	}
+	if transition.EffectiveDate != "" {
+		if _, err := time.Parse(civil.Layout, transition.EffectiveDate); err != nil {
+			http.Error(w, "effectiveDate must be in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	transition.CreatedBy = principal.Username
+
+	history, err := h.UserService.TransitionStatus(r.Context(), chi.URLParam(r, "uuid"), transition)
+	if err != nil {
+		switch err {
+		case users.ErrInvalidStatus:
+			http.Error(w, "status must be one of applied, onboarding, active, on_leave, graduated or terminated", http.StatusBadRequest)
+		case users.ErrNotFound:
+			http.Error(w, "User not found", http.StatusNotFound)
+		case users.ErrIllegalTransition:
+			http.Error(w, "Status transition is not allowed", http.StatusUnprocessableEntity)
+		case users.ErrStatusChanged:
+			http.Error(w, "Status was changed by another request, try again", http.StatusConflict)
+		default:
+			http.Error(w, "Failed to change status", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h *UserHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.UserService.GetStatusHistory(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		http.Error(w, "Failed to fetch status history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetUserProfile returns the profile of a user, or with the asOf query
+// parameter the version of the profile in effect at that time.
+func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	uuid := chi.URLParam(r, "uuid")
+
+	asOfParam := r.URL.Query().Get("asOf")
+	if asOfParam == "" {
+		profile, err := h.UserService.GetUserProfile(r.Context(), uuid)
 		if err != nil {
-			http.Error(w, "dob must be in YYYY-MM-DD format", http.StatusBadRequest)
+			if err == sql.ErrNoRows {
+				http.Error(w, "User not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
 			return
 		}
-	} else if update.DoB != nil && *update.DoB == "" {
-		http.Error(w, "dob cannot be an empty string", http.StatusBadRequest)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(profile)
 		return
 	}
 
-	_, err = h.UserService.UpdateProfile(uuid, &update)
+	asOf, err := parseAsOf(asOfParam)
 	if err != nil {
-		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		http.Error(w, "asOf must be in YYYY-MM-DD or RFC 3339 format", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.UserService.GetProfileAsOf(r.Context(), uuid, asOf)
+	if err != nil {
+		if err == users.ErrProfileVersionNotFound {
+			http.Error(w, "No profile at that time", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(version)
+}
+
+func (h *UserHandler) GetProfileHistory(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.UserService.GetProfileHistory(r.Context(), chi.URLParam(r, "uuid"))
+	if err != nil {
+		if err == users.ErrNotFound {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to fetch profile history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
+}
+
+func (h *UserHandler) RevertProfile(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Version int `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	if req.Version < 1 {
+		http.Error(w, "version field is required", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+
+	version, err := h.UserService.RevertProfile(r.Context(), chi.URLParam(r, "uuid"), req.Version, principal.Username)
+	if err != nil {
+		if err == users.ErrProfileVersionNotFound {
+			http.Error(w, "Profile version not found", http.StatusNotFound)
+			return
+		}
+		if validationErr, ok := err.(*users.ValidationError); ok {
+			http.Error(w, validationErr.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == users.ErrPhoneNumberExists {
+			http.Error(w, "Phone number is already in use", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to revert profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(version)
+}
+
+// parseAsOf parses a point in time given as a date, meaning the end of that
+// day in UTC, or as an RFC 3339 timestamp.
+func parseAsOf(value string) (time.Time, error) {
+	if date, err := time.Parse(civil.Layout, value); err == nil {
+		return date.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// optionalIntParam returns the value of an integer query parameter, or nil if
+// it is not set.
+func optionalIntParam(q url.Values, name string) (*int, error) {
+	if q.Get(name) == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(q.Get(name))
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// SetCustomFields sets the custom field values of a user from a JSON object
+// keyed by field key. A null value clears a field.
+func (h *UserHandler) SetCustomFields(w http.ResponseWriter, r *http.Request) {
+	var values map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+
+	err = h.UserService.SetCustomFields(r.Context(), chi.URLParam(r, "uuid"), values, principal.Username)
+	if err != nil {
+		writeCustomFieldError(w, err, "Failed to set custom fields")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	response := map[string]interface{}{
-		"message": "Profile updated successfully",
+		"message": "Custom fields updated successfully",
 	}
 	json.NewEncoder(w).Encode(response)
 }
+
+func (h *UserHandler) ListCustomFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.UserService.ListCustomFields(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to fetch custom fields", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(fields)
+}
+
+func (h *UserHandler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	var field users.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	field.CreatedBy = principal.Username
+
+	err = h.UserService.CreateCustomField(r.Context(), &field)
+	if err != nil {
+		writeCustomFieldError(w, err, "Failed to create custom field")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(field)
+}
+
+// UpdateCustomField replaces the definition of a custom field, except for its
+// key and type which cannot change.
+func (h *UserHandler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	var field users.CustomField
+	if err := json.NewDecoder(r.Body).Decode(&field); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	principal, err := context_helpers.GetPrincipal(r)
+	if err != nil {
+		http.Error(w, "Failed to get user from context", http.StatusInternalServerError)
+		return
+	}
+	field.ID = chi.URLParam(r, "id")
+	field.UpdatedBy = &principal.Username
+
+	err = h.UserService.UpdateCustomField(r.Context(), &field)
+	if err != nil {
+		writeCustomFieldError(w, err, "Failed to update custom field")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(field)
+}
+
+func (h *UserHandler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	err := h.UserService.DeleteCustomField(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeCustomFieldError(w, err, "Failed to delete custom field")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	response := map[string]interface{}{
+		"message": "Custom field deleted successfully",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeCustomFieldError writes the response for an error managing custom
+// fields, or message for unexpected errors.
+func writeCustomFieldError(w http.ResponseWriter, err error, message string) {
+	if validationErr, ok := err.(*users.ValidationError); ok {
+		http.Error(w, validationErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err {
+	case users.ErrCustomFieldNotFound:
+		http.Error(w, "Custom field not found", http.StatusNotFound)
+	case users.ErrCustomFieldKeyExists:
+		http.Error(w, "Custom field key already exists", http.StatusConflict)
+	case users.ErrNotFound:
+		http.Error(w, "User not found", http.StatusNotFound)
+	default:
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
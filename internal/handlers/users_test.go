@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAsOf(t *testing.T) {
+	// A date means the end of that day in UTC, so that changes made during
+	// the day are included.
+	asOf, err := parseAsOf("2026-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 1, 23, 59, 59, 0, time.UTC), asOf)
+
+	asOf, err = parseAsOf("2026-01-01T10:30:00+07:00")
+	require.NoError(t, err)
+	assert.True(t, asOf.Equal(time.Date(2026, 1, 1, 3, 30, 0, 0, time.UTC)))
+
+	for _, value := range []string{"", "2026-13-01", "01/01/2026", "2026-01-01 10:30"} {
+		_, err = parseAsOf(value)
+		assert.Error(t, err, value)
+	}
+}
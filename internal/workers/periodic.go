@@ -0,0 +1,62 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Periodic is a worker running a task every interval. The context of the task
+// is cancelled when the worker is stopped.
+type Periodic struct {
+	interval time.Duration
+	task     func(ctx context.Context)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewPeriodic creates a worker running task every interval.
+func NewPeriodic(interval time.Duration, task func(ctx context.Context)) *Periodic {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Periodic{
+		interval: interval,
+		task:     task,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the task in the background until Stop is called.
+func (p *Periodic) Start() {
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.task(p.ctx)
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the worker, cancelling a task in progress and waiting for it to
+// return or for ctx to be done. It must only be called after Start.
+func (p *Periodic) Stop(ctx context.Context) error {
+	p.once.Do(p.cancel)
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
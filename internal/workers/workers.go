@@ -0,0 +1,75 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/internal/domain/auth"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/rs/zerolog/log"
+)
+
+// Worker is a background job running alongside the HTTP server.
+type Worker interface {
+	Start()
+	Stop(ctx context.Context) error
+}
+
+// Workers are the background jobs of the service. They are started with the
+// HTTP server and stopped during its shutdown.
+type Workers []Worker
+
+// ProvideWorkers is the provider for Workers.
+func ProvideWorkers(tokenCleanup *oauth.CleanupJob, accountExpiry *AccountExpiryJob) Workers {
+	var workers Workers
+	if tokenCleanup != nil {
+		workers = append(workers, tokenCleanup)
+	}
+	if accountExpiry != nil {
+		workers = append(workers, accountExpiry)
+	}
+	return workers
+}
+
+// ProvideTokenCleanupJob is the provider for the OAuth token cleanup job. It
+// returns nil, disabling the job, when no cleanup interval is configured.
+func ProvideTokenCleanupJob(db *infras.MySQLConn, config *configs.Config) *oauth.CleanupJob {
+	intervalSeconds := config.App.OAuth.CleanupIntervalSeconds
+	if intervalSeconds <= 0 {
+		log.Info().Msg("OAuth token cleanup is disabled.")
+		return nil
+	}
+
+	return oauth.NewCleanupJob(db.Write, time.Duration(intervalSeconds)*time.Second)
+}
+
+// AccountExpiryJob periodically expires the accounts whose valid until date
+// has passed.
+type AccountExpiryJob struct {
+	*Periodic
+}
+
+// ProvideAccountExpiryJob is the provider for the account expiry job. It
+// returns nil, disabling the job, when no expiry interval is configured.
+func ProvideAccountExpiryJob(authService auth.AuthService, config *configs.Config) *AccountExpiryJob {
+	intervalSeconds := config.App.Accounts.ExpiryIntervalSeconds
+	if intervalSeconds <= 0 {
+		log.Info().Msg("Account expiry is disabled.")
+		return nil
+	}
+
+	return &AccountExpiryJob{
+		Periodic: NewPeriodic(time.Duration(intervalSeconds)*time.Second, func(ctx context.Context) {
+			expired, err := authService.ExpireAccounts(ctx)
+			if err != nil {
+				log.Error().Err(err).Int64("expired", expired).Msg("Failed to expire accounts")
+				return
+			}
+			if expired > 0 {
+				log.Info().Int64("expired", expired).Msg("Expired accounts.")
+			}
+		}),
+	}
+}
@@ -6,6 +6,8 @@ package main
 import (
 	"github.com/evermos/boilerplate-go/configs"
 	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/tracing"
+	"github.com/rs/zerolog/log"
 )
 
 var config *configs.Config
@@ -20,8 +22,14 @@ func main() {
 	// Initialize config
 	config = configs.Get()
 
-	// Set desired log level
+	// Set desired log level and format
 	logger.SetLogLevel(config)
+	logger.SetLogFormat(config)
+
+	// Set up tracing before any connection is opened
+	if err := tracing.Init(config); err != nil {
+		log.Fatal().Err(err).Msg("Failed initializing tracing")
+	}
 
 	// Wire everything up
 	http := InitializeService()
@@ -0,0 +1,11 @@
+-- Each migration records the schema version it brings the database to, which
+-- the readiness probe compares with the version the server expects. Apply
+-- this one first, after the users migration on new databases.
+CREATE TABLE IF NOT EXISTS `ums_schema_migrations` (
+    `version` INT NOT NULL,
+    `applied_at` TIMESTAMP NOT NULL,
+    PRIMARY KEY (`version`)
+) ENGINE=InnoDB;
+
+-- The baseline schema: the users tables (1) and 02-oauth.sql (2).
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (1, NOW()), (2, NOW());
@@ -0,0 +1,6 @@
+-- ums_users uses UUIDs, which don't fit the numeric user ID columns of the
+-- OAuth tables.
+ALTER TABLE `oauth_clients` MODIFY `user_id` VARCHAR(36) NULL;
+ALTER TABLE `oauth_access_tokens` MODIFY `user_id` VARCHAR(36) NULL;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (3, NOW());
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `oauth_refresh_tokens` (
+    `refresh_token` VARCHAR(40) NOT NULL,
+    `client_id` VARCHAR(32) NOT NULL,
+    `user_id` VARCHAR(36) NULL,
+    `expires` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    `scope` VARCHAR(2000) NULL,
+    PRIMARY KEY (`refresh_token`),
+    KEY `idx_oauth_refresh_tokens_expires` (`expires`)
+) ENGINE=InnoDB
+DEFAULT CHARSET=utf8;
+
+-- The cleanup job purges expired access tokens by expiry.
+ALTER TABLE `oauth_access_tokens` ADD KEY `idx_oauth_access_tokens_expires` (`expires`);
+
+-- Version 4 is recorded by oauth/hash_credentials.go, which completes this
+-- migration by hashing the tokens of these tables.
@@ -0,0 +1,7 @@
+-- Clients now list the scopes they may be granted. The legacy "user" scope
+-- granted access to everything; it becomes the profile scopes, so broader
+-- scopes must be granted to clients explicitly.
+UPDATE `oauth_clients` SET `scope` = 'profile:read profile:write' WHERE `scope` = 'user';
+UPDATE `oauth_access_tokens` SET `scope` = 'profile:read profile:write' WHERE `scope` = 'user';
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (5, NOW());
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `ums_user_mfa` (
+    `user_id` VARCHAR(36) NOT NULL,
+    `secret` VARCHAR(64) NOT NULL,
+    `enabled` BOOLEAN NOT NULL DEFAULT FALSE,
+    -- The last TOTP time step used, so that a code cannot be used twice.
+    `last_used_step` BIGINT NOT NULL DEFAULT 0,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `ums_user_recovery_codes` (
+    `user_id` VARCHAR(36) NOT NULL,
+    `code_hash` CHAR(64) NOT NULL,
+    `used_at` TIMESTAMP NULL,
+    PRIMARY KEY (`user_id`, `code_hash`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (6, NOW());
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `ums_user_sessions` (
+    `id` VARCHAR(36) NOT NULL,
+    `user_id` VARCHAR(36) NOT NULL,
+    `ip_address` VARCHAR(45) NOT NULL DEFAULT '',
+    `user_agent` VARCHAR(255) NOT NULL DEFAULT '',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_seen_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Sessions expire with the token they were created with.
+    `expires_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `revoked_at` TIMESTAMP NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_ums_user_sessions_user_id` (`user_id`, `revoked_at`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (7, NOW());
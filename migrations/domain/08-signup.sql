@@ -0,0 +1,21 @@
+ALTER TABLE `ums_users`
+    ADD COLUMN `email` VARCHAR(255) NULL AFTER `username`,
+    -- pending_verification and pending_approval accounts come from the
+    -- public sign-up and cannot log in until approved by an admin.
+    ADD COLUMN `account_status` VARCHAR(32) NOT NULL DEFAULT 'active' AFTER `role`,
+    ADD UNIQUE KEY `uq_users_email` (`email`),
+    ADD KEY `idx_users_account_status` (`account_status`);
+
+CREATE TABLE IF NOT EXISTS `ums_email_verifications` (
+    `token_hash` CHAR(64) NOT NULL,
+    `user_id` VARCHAR(36) NOT NULL,
+    `email` VARCHAR(255) NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expires_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `used_at` TIMESTAMP NULL,
+    PRIMARY KEY (`token_hash`),
+    KEY `idx_email_verifications_user_id` (`user_id`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (8, NOW());
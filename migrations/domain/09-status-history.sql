@@ -0,0 +1,22 @@
+-- Trainee statuses are now a fixed set of values written only through status
+-- transitions. Nothing wrote the free-text column before, so any other value
+-- is cleared and the trainee re-enters the workflow.
+UPDATE `ums_status`
+SET `status` = NULL
+WHERE `status` NOT IN ('applied', 'onboarding', 'active', 'on_leave', 'graduated', 'terminated');
+
+CREATE TABLE IF NOT EXISTS `ums_status_history` (
+    `id` VARCHAR(36) NOT NULL,
+    `user_id` VARCHAR(36) NOT NULL,
+    `from_status` VARCHAR(50) NULL,
+    `to_status` VARCHAR(50) NOT NULL,
+    `reason` VARCHAR(500) NOT NULL DEFAULT '',
+    `effective_date` DATE NOT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_status_history_user_id` (`user_id`, `created_at`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (9, NOW());
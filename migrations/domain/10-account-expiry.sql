@@ -0,0 +1,23 @@
+-- Accounts past their valid_until date are expired by a background job.
+ALTER TABLE `ums_users`
+    ADD COLUMN `valid_until` DATE NULL AFTER `account_status`,
+    ADD KEY `idx_users_account_status_valid_until` (`account_status`, `valid_until`);
+
+CREATE TABLE IF NOT EXISTS `ums_account_status_history` (
+    `id` VARCHAR(36) NOT NULL,
+    `user_id` VARCHAR(36) NOT NULL,
+    `from_status` VARCHAR(32) NOT NULL,
+    `to_status` VARCHAR(32) NOT NULL,
+    `reason` VARCHAR(500) NOT NULL DEFAULT '',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_account_status_history_user_id` (`user_id`, `created_at`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- OAuth tokens of users are deleted when their account stops being active.
+ALTER TABLE `oauth_access_tokens` ADD KEY `idx_oauth_access_tokens_user_id` (`user_id`);
+ALTER TABLE `oauth_refresh_tokens` ADD KEY `idx_oauth_refresh_tokens_user_id` (`user_id`);
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (10, NOW());
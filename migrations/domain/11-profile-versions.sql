@@ -0,0 +1,31 @@
+-- Every change of a profile is kept as a new version holding the whole
+-- profile, effective from its created_at until the next version.
+CREATE TABLE IF NOT EXISTS `ums_profile_versions` (
+    `profile_id` VARCHAR(36) NOT NULL,
+    `version` INT NOT NULL,
+    `name` VARCHAR(255) NULL,
+    `gender` ENUM('male', 'female') NULL,
+    `dob` VARCHAR(10) NULL,
+    `education` VARCHAR(50) NULL,
+    `address` VARCHAR(255) NULL,
+    `city` VARCHAR(50) NULL,
+    `province` VARCHAR(50) NULL,
+    `phone_number` VARCHAR(50) NULL,
+    -- The version this one restored, if it was created by a revert.
+    `reverted_from` INT NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` VARCHAR(255) NULL,
+    PRIMARY KEY (`profile_id`, `version`),
+    KEY `idx_profile_versions_created_at` (`profile_id`, `created_at`),
+    FOREIGN KEY (`profile_id`) REFERENCES `ums_profiles` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- The current profiles become the first version, effective since their last
+-- update.
+INSERT IGNORE INTO `ums_profile_versions`
+(`profile_id`, `version`, `name`, `gender`, `dob`, `education`, `address`, `city`, `province`, `phone_number`, `created_at`, `created_by`)
+SELECT `id`, 1, `name`, `gender`, `dob`, `education`, `address`, `city`, `province`, `phone_number`,
+    COALESCE(`updated_at`, `created_at`, NOW()), COALESCE(`updated_by`, `created_by`)
+FROM `ums_profiles`;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (11, NOW());
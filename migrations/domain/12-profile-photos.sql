@@ -0,0 +1,6 @@
+-- The photo of a profile is kept in the object storage. photo_key is the
+-- prefix of its objects, which holds the resized photo and its thumbnail.
+ALTER TABLE `ums_profiles`
+    ADD COLUMN `photo_key` VARCHAR(255) NULL AFTER `phone_number`;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (12, NOW());
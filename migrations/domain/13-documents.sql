@@ -0,0 +1,21 @@
+-- Documents of users, such as contracts and certificates. Their content is
+-- kept in the document storage under storage_key; checksum is its SHA-256.
+CREATE TABLE IF NOT EXISTS `ums_user_documents` (
+    `id` VARCHAR(36) NOT NULL,
+    `user_id` VARCHAR(36) NOT NULL,
+    `type` VARCHAR(32) NOT NULL,
+    `file_name` VARCHAR(255) NOT NULL,
+    `content_type` VARCHAR(100) NOT NULL,
+    `size` BIGINT NOT NULL,
+    `checksum` CHAR(64) NOT NULL,
+    `storage_key` VARCHAR(255) NOT NULL,
+    `expires_on` DATE NULL,
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_ums_user_documents_user_id` (`user_id`, `created_at`),
+    KEY `idx_ums_user_documents_expires_on` (`expires_on`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (13, NOW());
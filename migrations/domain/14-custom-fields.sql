@@ -0,0 +1,37 @@
+-- Profile fields defined by admins. Values are stored as text in the form
+-- the field type normalizes them to, so that they can be filtered on.
+CREATE TABLE IF NOT EXISTS `ums_custom_fields` (
+    `id` VARCHAR(36) NOT NULL,
+    `field_key` VARCHAR(64) NOT NULL,
+    `label` VARCHAR(255) NOT NULL,
+    `type` VARCHAR(16) NOT NULL,
+    -- JSON array of the allowed values of select fields.
+    `options` TEXT NULL,
+    `pattern` VARCHAR(255) NULL,
+    `max_length` INT NULL,
+    `min_value` DOUBLE NULL,
+    `max_value` DOUBLE NULL,
+    `required` TINYINT(1) NOT NULL DEFAULT 0,
+    -- hidden: admins only, read: users can see it, edit: users can change it.
+    `visibility` VARCHAR(16) NOT NULL DEFAULT 'hidden',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_by` VARCHAR(255) NOT NULL,
+    `updated_at` TIMESTAMP NULL,
+    `updated_by` VARCHAR(255) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_ums_custom_fields_field_key` (`field_key`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `ums_custom_field_values` (
+    `user_id` VARCHAR(36) NOT NULL,
+    `field_id` VARCHAR(36) NOT NULL,
+    `value` TEXT NOT NULL,
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_by` VARCHAR(255) NOT NULL,
+    PRIMARY KEY (`user_id`, `field_id`),
+    KEY `idx_ums_custom_field_values_value` (`field_id`, `value`(191)),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE,
+    FOREIGN KEY (`field_id`) REFERENCES `ums_custom_fields` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (14, NOW());
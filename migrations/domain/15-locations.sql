@@ -0,0 +1,36 @@
+-- Reference data of provinces and their regencies and cities. The tables are
+-- seeded from the bundled dataset by migrations/domain/locations, which also
+-- maps the free-text locations of profiles and placements to them.
+CREATE TABLE IF NOT EXISTS `ums_provinces` (
+    `code` CHAR(2) NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    PRIMARY KEY (`code`),
+    UNIQUE KEY `uk_ums_provinces_name` (`name`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `ums_cities` (
+    `id` INT NOT NULL,
+    `province_code` CHAR(2) NOT NULL,
+    -- regency (kabupaten) or city (kota).
+    `type` VARCHAR(16) NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_ums_cities_name` (`province_code`, `type`, `name`),
+    FOREIGN KEY (`province_code`) REFERENCES `ums_provinces` (`code`)
+) ENGINE=InnoDB;
+
+-- Other names of provinces and cities. Each alias names either a province or
+-- a city.
+CREATE TABLE IF NOT EXISTS `ums_location_aliases` (
+    `id` INT NOT NULL AUTO_INCREMENT,
+    `alias` VARCHAR(100) NOT NULL,
+    `province_code` CHAR(2) NULL,
+    `city_id` INT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_ums_location_aliases_province` (`alias`, `province_code`),
+    UNIQUE KEY `uk_ums_location_aliases_city` (`alias`, `city_id`),
+    FOREIGN KEY (`province_code`) REFERENCES `ums_provinces` (`code`) ON DELETE CASCADE,
+    FOREIGN KEY (`city_id`) REFERENCES `ums_cities` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (15, NOW());
@@ -0,0 +1,19 @@
+-- Wrong MFA codes are counted per user, and lock verification for a while
+-- once there are too many.
+ALTER TABLE `ums_user_mfa`
+    ADD COLUMN `failed_attempts` INT NOT NULL DEFAULT 0 AFTER `last_used_step`,
+    ADD COLUMN `locked_until` TIMESTAMP NULL AFTER `failed_attempts`;
+
+-- MFA challenges already verified, so that each is used once. Rows are
+-- purged once the challenge has expired.
+CREATE TABLE IF NOT EXISTS `ums_used_mfa_challenges` (
+    `id` VARCHAR(36) NOT NULL,
+    `user_id` VARCHAR(36) NOT NULL,
+    `expires_at` TIMESTAMP NOT NULL,
+    `used_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_ums_used_mfa_challenges_expires_at` (`expires_at`),
+    FOREIGN KEY (`user_id`) REFERENCES `ums_users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (19, NOW());
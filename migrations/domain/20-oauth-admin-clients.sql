@@ -0,0 +1,11 @@
+-- Clients acting on their own have no role, so only clients flagged as admin
+-- are granted the admin scopes with the client_credentials grant.
+ALTER TABLE `oauth_clients`
+    ADD COLUMN `admin` BOOLEAN NOT NULL DEFAULT FALSE AFTER `scope`;
+
+-- Admin scopes granted to other clients acting on their own are withdrawn.
+DELETE FROM `oauth_access_tokens`
+WHERE `user_id` IS NULL
+    AND (`scope` LIKE '%users:%' OR `scope` LIKE '%clients:write%');
+
+INSERT IGNORE INTO `ums_schema_migrations` (`version`, `applied_at`) VALUES (20, NOW());
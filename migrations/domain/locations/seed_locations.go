@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/internal/domain/locations"
+	"github.com/rs/zerolog/log"
+)
+
+// unmatched is a free-text location the migration could not map, reported
+// with the number of rows holding it.
+type unmatched struct {
+	table  string
+	column string
+	value  string
+	rows   int
+	reason string
+}
+
+// This migration seeds the location reference data from the bundled dataset,
+// then maps the free-text cities and provinces of profiles, their versions and
+// placements to their names in it. Values it cannot map are left as they are and reported
+// as CSV on stdout, for admins to fix by hand or to add as aliases:
+//
+//	go run ./migrations/domain/locations > unmatched-locations.csv
+//
+// It can be run again safely. Apply it after 15-locations.sql.
+func main() {
+	config := configs.Get()
+
+	mysqlConn := infras.ProvideMySQLConn(config)
+	ctx := context.Background()
+
+	err := seed(ctx, mysqlConn)
+	if err != nil {
+		log.Error().Err(err).Msg("Error seeding locations")
+		return
+	}
+
+	repository := locations.ProvideLocationRepositoryMySQL(mysqlConn)
+	provinces, err := repository.ListProvinces(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Error reading provinces")
+		return
+	}
+	cities, err := repository.ListCities(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Error reading cities")
+		return
+	}
+	aliases, err := repository.ListAliases(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Error reading location aliases")
+		return
+	}
+	index, err := locations.NewIndex(provinces, cities, aliases)
+	if err != nil {
+		log.Error().Err(err).Msg("Error indexing locations")
+		return
+	}
+
+	profileReport, mappedProfiles, err := mapProfiles(ctx, mysqlConn, index, "ums_profiles")
+	if err != nil {
+		log.Error().Err(err).Msg("Error mapping profile locations")
+		return
+	}
+	versionReport, mappedVersions, err := mapProfiles(ctx, mysqlConn, index, "ums_profile_versions")
+	if err != nil {
+		log.Error().Err(err).Msg("Error mapping profile version locations")
+		return
+	}
+	placementReport, mappedPlacements, err := mapPlacements(ctx, mysqlConn, index)
+	if err != nil {
+		log.Error().Err(err).Msg("Error mapping placement cities")
+		return
+	}
+
+	report := csv.NewWriter(os.Stdout)
+	report.Write([]string{"table", "column", "value", "rows", "reason"})
+	for _, u := range append(append(profileReport, versionReport...), placementReport...) {
+		report.Write([]string{u.table, u.column, u.value, strconv.Itoa(u.rows), u.reason})
+	}
+	report.Flush()
+	if err := report.Error(); err != nil {
+		log.Error().Err(err).Msg("Error writing report")
+		return
+	}
+
+	_, err = mysqlConn.Write.Exec("INSERT IGNORE INTO ums_schema_migrations (version, applied_at) VALUES (16, NOW())")
+	if err != nil {
+		log.Error().Err(err).Msg("Error recording schema version")
+		return
+	}
+
+	log.Info().
+		Int("provinces", len(provinces)).
+		Int("cities", len(cities)).
+		Int("mappedProfiles", mappedProfiles).
+		Int("mappedVersions", mappedVersions).
+		Int("mappedPlacements", mappedPlacements).
+		Int("unmatched", len(profileReport)+len(versionReport)+len(placementReport)).
+		Msg("Seeded and mapped locations.")
+}
+
+// seed inserts the bundled dataset, updating the names of existing entries.
+func seed(ctx context.Context, mysqlConn *infras.MySQLConn) error {
+	tx, err := mysqlConn.Write.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, province := range locations.Provinces {
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO ums_provinces (code, name) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE name = VALUES(name)`,
+			province.Code, province.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, city := range locations.Cities {
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO ums_cities (id, province_code, type, name) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE province_code = VALUES(province_code), type = VALUES(type), name = VALUES(name)`,
+			city.ID, city.ProvinceCode, city.Type, city.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, alias := range locations.Aliases {
+		_, err = tx.ExecContext(ctx, `
+		INSERT IGNORE INTO ums_location_aliases (alias, province_code, city_id) VALUES (?, ?, ?)`,
+			alias.Alias, alias.ProvinceCode, alias.CityID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// mapProfiles replaces the cities and provinces in table, ums_profiles or
+// ums_profile_versions, with their names in the reference data, inferring
+// missing provinces from cities. It returns the values it could not map and
+// the number of rows changed.
+func mapProfiles(ctx context.Context, mysqlConn *infras.MySQLConn, index *locations.Index, table string) ([]unmatched, int, error) {
+	var pairs []struct {
+		City     *string `db:"city"`
+		Province *string `db:"province"`
+		Count    int     `db:"count"`
+	}
+	err := mysqlConn.Write.SelectContext(ctx, &pairs, `
+	SELECT city, province, COUNT(*) AS count
+	FROM `+table+`
+	WHERE city <> '' OR province <> ''
+	GROUP BY city, province
+	`)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	report := []unmatched{}
+	mapped := 0
+	for _, pair := range pairs {
+		city, province := valueOf(pair.City), valueOf(pair.Province)
+		newCity, newProvince, problems := mapLocation(index, province, city)
+		for _, problem := range problems {
+			problem.table = table
+			problem.rows = pair.Count
+			report = append(report, problem)
+		}
+		if newCity == city && newProvince == province {
+			continue
+		}
+
+		result, err := mysqlConn.Write.ExecContext(ctx, `
+		UPDATE `+table+`
+		SET city = ?, province = ?
+		WHERE city <=> ? AND province <=> ?`,
+			nilIfEmpty(newCity), nilIfEmpty(newProvince), pair.City, pair.Province)
+		if err != nil {
+			return nil, 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, 0, err
+		}
+		mapped += int(affected)
+	}
+	return report, mapped, nil
+}
+
+// mapLocation returns the names in the reference data of a free-text city and
+// province, keeping the values it cannot map, which it reports.
+func mapLocation(index *locations.Index, province, city string) (newCity, newProvince string, problems []unmatched) {
+	newCity, newProvince = city, province
+
+	location, err := index.Resolve(province, city)
+	if err == nil {
+		newProvince = location.Province.Name
+		if location.City != nil {
+			newCity = location.City.FullName
+		}
+		return newCity, newProvince, nil
+	}
+
+	if strings.TrimSpace(province) != "" {
+		match, err := index.Province(province)
+		if err != nil {
+			problems = append(problems, unmatched{column: "province", value: province, reason: err.Error()})
+		} else {
+			newProvince = match.Name
+		}
+	}
+
+	if strings.TrimSpace(city) == "" {
+		return newCity, newProvince, problems
+	}
+
+	// The city alone may resolve when the province was wrong.
+	location, cityErr := index.Resolve("", city)
+	switch {
+	case cityErr == nil && len(problems) > 0:
+		newCity = location.City.FullName
+		newProvince = location.Province.Name
+		problems[0].reason += "; replaced by the province of the city"
+	case cityErr == nil:
+		problems = append(problems, unmatched{column: "city", value: city, reason: locations.ErrCityNotInProvince.Error()})
+	default:
+		// Cities such as "Jakarta" name a province rather than a city.
+		if match, err := index.Province(city); err == nil && strings.TrimSpace(province) == "" {
+			newProvince = match.Name
+		}
+		problems = append(problems, unmatched{column: "city", value: city, reason: cityErr.Error()})
+	}
+	return newCity, newProvince, problems
+}
+
+// mapPlacements replaces the cities of placements with their full names in
+// the reference data. A placement whose city maps to the city of another one
+// is reported rather than merged. It returns the values it could not map and
+// the number of placements changed.
+func mapPlacements(ctx context.Context, mysqlConn *infras.MySQLConn, index *locations.Index) ([]unmatched, int, error) {
+	var placements []struct {
+		ID   string `db:"id"`
+		City string `db:"city"`
+	}
+	err := mysqlConn.Write.SelectContext(ctx, &placements, `SELECT id, city FROM ums_placement`)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	report := []unmatched{}
+	mapped := 0
+	for _, placement := range placements {
+		location, err := index.Resolve("", placement.City)
+		if err != nil {
+			report = append(report, unmatched{"ums_placement", "city", placement.City, 1, err.Error()})
+			continue
+		}
+		if location.City.FullName == placement.City {
+			continue
+		}
+
+		var duplicateID string
+		err = mysqlConn.Write.GetContext(ctx, &duplicateID, `
+		SELECT COALESCE(MAX(id), '') FROM ums_placement WHERE city = ? AND id <> ?`,
+			location.City.FullName, placement.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if duplicateID != "" {
+			report = append(report, unmatched{"ums_placement", "city", placement.City, 1,
+				"same city as placement " + duplicateID + ", " + location.City.FullName})
+			continue
+		}
+
+		_, err = mysqlConn.Write.ExecContext(ctx, `UPDATE ums_placement SET city = ? WHERE id = ?`, location.City.FullName, placement.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		mapped++
+	}
+	return report, mapped, nil
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/oauth"
+	"github.com/rs/zerolog/log"
+)
+
+// This migration hashes the OAuth credentials stored in plaintext: access and
+// refresh tokens become SHA-256 digests and client secrets become bcrypt
+// hashes. It can be run again safely, as already hashed rows are skipped.
+// Apply it after 04-oauth-refresh-tokens.sql: it records version 4 for both.
+func main() {
+	config := configs.Get()
+
+	mysqlConn := infras.ProvideMySQLConn(config)
+
+	query := `
+		ALTER TABLE oauth_access_tokens MODIFY access_token VARCHAR(64) NOT NULL;
+		ALTER TABLE oauth_refresh_tokens MODIFY refresh_token VARCHAR(64) NOT NULL;
+		ALTER TABLE oauth_clients MODIFY client_secret VARCHAR(60) NOT NULL;
+
+		-- Generated tokens are 40 characters long, digests are 64
+		UPDATE oauth_access_tokens SET access_token = SHA2(access_token, 256) WHERE CHAR_LENGTH(access_token) < 64;
+		UPDATE oauth_refresh_tokens SET refresh_token = SHA2(refresh_token, 256) WHERE CHAR_LENGTH(refresh_token) < 64
+	`
+
+	statements := strings.Split(query, ";")
+
+	var validStatements []string
+	for _, stmt := range statements {
+		if strings.TrimSpace(stmt) != "" {
+			validStatements = append(validStatements, stmt)
+		}
+	}
+
+	for _, stmt := range validStatements {
+		_, err := mysqlConn.Write.Exec(stmt)
+		if err != nil {
+			log.Error().Err(err).Msg("Error executing statement")
+			return
+		}
+	}
+
+	// bcrypt is not available in MySQL, so client secrets are hashed here.
+	var clients []struct {
+		ClientID     string `db:"client_id"`
+		ClientSecret string `db:"client_secret"`
+	}
+	err := mysqlConn.Write.Select(&clients, "SELECT client_id, client_secret FROM oauth_clients WHERE client_secret NOT LIKE '$2_$%'")
+	if err != nil {
+		log.Error().Err(err).Msg("Error selecting OAuth clients")
+		return
+	}
+
+	for _, client := range clients {
+		hashedSecret, err := oauth.HashClientSecret(client.ClientSecret)
+		if err != nil {
+			log.Error().Err(err).Str("clientId", client.ClientID).Msg("Error hashing client secret")
+			return
+		}
+
+		_, err = mysqlConn.Write.Exec("UPDATE oauth_clients SET client_secret = ? WHERE client_id = ?", hashedSecret, client.ClientID)
+		if err != nil {
+			log.Error().Err(err).Str("clientId", client.ClientID).Msg("Error updating client secret")
+			return
+		}
+	}
+
+	_, err = mysqlConn.Write.Exec("INSERT IGNORE INTO ums_schema_migrations (version, applied_at) VALUES (4, NOW())")
+	if err != nil {
+		log.Error().Err(err).Msg("Error recording schema version")
+		return
+	}
+
+	log.Info().Int("clients", len(clients)).Msg("Hashed OAuth credentials.")
+}
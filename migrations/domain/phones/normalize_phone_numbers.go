@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/phone"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultRegion is the region of numbers written without calling code when
+// APP.PHONES.DEFAULT_REGION is not set, as in the user service.
+const defaultRegion = "ID"
+
+// This migration normalizes the phone numbers of profiles and their versions
+// to E.164, reading numbers without calling code as numbers of
+// APP.PHONES.DEFAULT_REGION, and indexes those of profiles. Numbers that are not valid are left as they are, and are
+// reported as CSV on stdout with the numbers several profiles share, which
+// must be resolved before enabling APP.PHONES.UNIQUE:
+//
+//	go run ./migrations/domain/phones > phone-numbers.csv
+//
+// With APP.PHONES.UNIQUE enabled, it also adds a unique index on the phone
+// numbers, and fails while profiles share one. It can be run again safely, so
+// run it again after enabling APP.PHONES.UNIQUE. Apply it after
+// 15-locations.sql and the locations migration.
+func main() {
+	config := configs.Get()
+
+	mysqlConn := infras.ProvideMySQLConn(config)
+
+	region := config.App.Phones.DefaultRegion
+	if region == "" {
+		region = defaultRegion
+	}
+	if !phone.IsKnownRegion(region) {
+		log.Error().Str("region", region).Msg("Unsupported default phone region")
+		return
+	}
+
+	var indexed bool
+	err := mysqlConn.Write.Get(&indexed, `
+		SELECT EXISTS(
+			SELECT index_name
+			FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = 'ums_profiles' AND index_name = 'idx_ums_profiles_phone_number'
+		)`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error checking phone number index")
+		return
+	}
+	if !indexed {
+		_, err = mysqlConn.Write.Exec("CREATE INDEX idx_ums_profiles_phone_number ON ums_profiles (phone_number)")
+		if err != nil {
+			log.Error().Err(err).Msg("Error creating phone number index")
+			return
+		}
+	}
+
+	var profiles []struct {
+		ID          string `db:"id"`
+		PhoneNumber string `db:"phone_number"`
+	}
+	err = mysqlConn.Write.Select(&profiles, "SELECT id, phone_number FROM ums_profiles WHERE phone_number <> ''")
+	if err != nil {
+		log.Error().Err(err).Msg("Error selecting phone numbers")
+		return
+	}
+
+	report := csv.NewWriter(os.Stdout)
+	report.Write([]string{"profile_id", "phone_number", "reason"})
+
+	normalized, invalid := 0, 0
+	for _, profile := range profiles {
+		phoneNumber, err := phone.Normalize(profile.PhoneNumber, region)
+		if err != nil {
+			report.Write([]string{profile.ID, profile.PhoneNumber, err.Error()})
+			invalid++
+			continue
+		}
+		if phoneNumber == profile.PhoneNumber {
+			continue
+		}
+
+		_, err = mysqlConn.Write.Exec("UPDATE ums_profiles SET phone_number = ? WHERE id = ?", phoneNumber, profile.ID)
+		if err != nil {
+			log.Error().Err(err).Str("profileId", profile.ID).Msg("Error updating phone number")
+			return
+		}
+		normalized++
+	}
+
+	versions, err := normalizeVersions(mysqlConn, region)
+	if err != nil {
+		log.Error().Err(err).Msg("Error normalizing phone numbers of profile versions")
+		return
+	}
+
+	var shared []struct {
+		ID          string `db:"id"`
+		PhoneNumber string `db:"phone_number"`
+		Count       int    `db:"count"`
+	}
+	err = mysqlConn.Write.Select(&shared, `
+		SELECT p.id, p.phone_number, d.count
+		FROM ums_profiles AS p
+		INNER JOIN (
+			SELECT phone_number, COUNT(*) AS count
+			FROM ums_profiles
+			WHERE phone_number <> ''
+			GROUP BY phone_number
+			HAVING COUNT(*) > 1
+		) AS d ON d.phone_number = p.phone_number
+		ORDER BY p.phone_number, p.id`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error selecting shared phone numbers")
+		return
+	}
+	for _, profile := range shared {
+		report.Write([]string{profile.ID, profile.PhoneNumber, "shared by " + strconv.Itoa(profile.Count) + " profiles"})
+	}
+
+	report.Flush()
+	if err := report.Error(); err != nil {
+		log.Error().Err(err).Msg("Error writing report")
+		return
+	}
+
+	if config.App.Phones.Unique {
+		if len(shared) > 0 {
+			log.Error().Int("shared", len(shared)).Msg("Phone numbers shared by several profiles must be resolved before enabling APP.PHONES.UNIQUE")
+			return
+		}
+		err = addUniqueIndex(mysqlConn)
+		if err != nil {
+			log.Error().Err(err).Msg("Error creating unique phone number index")
+			return
+		}
+	}
+
+	_, err = mysqlConn.Write.Exec("INSERT IGNORE INTO ums_schema_migrations (version, applied_at) VALUES (17, NOW())")
+	if err != nil {
+		log.Error().Err(err).Msg("Error recording schema version")
+		return
+	}
+
+	log.Info().
+		Int("profiles", len(profiles)).
+		Int("normalized", normalized).
+		Int("normalizedVersions", versions).
+		Int("invalid", invalid).
+		Int("shared", len(shared)).
+		Msg("Normalized phone numbers.")
+}
+
+// normalizeVersions normalizes the phone numbers of profile versions, so
+// reverts restore normalized numbers, and returns the number of distinct
+// numbers changed. Numbers that are not valid are left as they are, which
+// reverts refuse.
+func normalizeVersions(mysqlConn *infras.MySQLConn, region string) (int, error) {
+	var phoneNumbers []string
+	err := mysqlConn.Write.Select(&phoneNumbers, "SELECT DISTINCT phone_number FROM ums_profile_versions WHERE phone_number <> ''")
+	if err != nil {
+		return 0, err
+	}
+
+	normalized := 0
+	for _, phoneNumber := range phoneNumbers {
+		normalizedNumber, err := phone.Normalize(phoneNumber, region)
+		if err != nil || normalizedNumber == phoneNumber {
+			continue
+		}
+
+		_, err = mysqlConn.Write.Exec("UPDATE ums_profile_versions SET phone_number = ? WHERE phone_number = ?", normalizedNumber, phoneNumber)
+		if err != nil {
+			return 0, err
+		}
+		normalized++
+	}
+	return normalized, nil
+}
+
+// addUniqueIndex makes the phone numbers of profiles unique, unless they
+// already are. Empty numbers are indexed as NULL, so profiles may share them.
+func addUniqueIndex(mysqlConn *infras.MySQLConn) error {
+	var indexed bool
+	err := mysqlConn.Write.Get(&indexed, `
+		SELECT EXISTS(
+			SELECT index_name
+			FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = 'ums_profiles' AND index_name = 'uq_ums_profiles_phone_number'
+		)`)
+	if err != nil || indexed {
+		return err
+	}
+
+	_, err = mysqlConn.Write.Exec(`
+		ALTER TABLE ums_profiles
+		ADD COLUMN phone_number_key VARCHAR(50) AS (NULLIF(phone_number, '')) VIRTUAL,
+		ADD UNIQUE KEY uq_ums_profiles_phone_number (phone_number_key)`)
+	return err
+}
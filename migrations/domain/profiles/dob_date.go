@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/shared/civil"
+	"github.com/rs/zerolog/log"
+)
+
+// dobTables are the tables with a dob column, with the columns of the
+// profile and the version of their rows. Profiles have no version.
+var dobTables = []struct {
+	name      string
+	profileID string
+	version   string
+}{
+	{"ums_profiles", "id", "''"},
+	{"ums_profile_versions", "profile_id", "version"},
+}
+
+// This migration changes the dob columns of profiles and profile versions
+// from text to DATE, and indexes the dates of birth of profiles. Dates that
+// are not valid YYYY-MM-DD dates are cleared, and are reported as CSV on
+// stdout with the dates of birth in the future, which are kept:
+//
+//	go run ./migrations/domain/profiles > dates-of-birth.csv
+//
+// It can be run again safely. Apply it after the phones migration.
+func main() {
+	config := configs.Get()
+
+	mysqlConn := infras.ProvideMySQLConn(config)
+
+	report := csv.NewWriter(os.Stdout)
+	report.Write([]string{"profile_id", "version", "dob", "reason"})
+
+	today := civil.Today()
+	cleared, future := 0, 0
+	for _, table := range dobTables {
+		var columnType string
+		err := mysqlConn.Write.Get(&columnType, `
+			SELECT data_type
+			FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ? AND column_name = 'dob'`, table.name)
+		if err != nil {
+			log.Error().Err(err).Str("table", table.name).Msg("Error checking dob column")
+			return
+		}
+
+		var rows []struct {
+			ID      string `db:"id"`
+			Version string `db:"version"`
+			DoB     string `db:"dob"`
+		}
+		if columnType == "date" {
+			err = mysqlConn.Write.Select(&rows, "SELECT "+table.profileID+" AS id, "+table.version+" AS version, DATE_FORMAT(dob, '%Y-%m-%d') AS dob FROM "+table.name+" WHERE dob > CURDATE()")
+		} else {
+			err = mysqlConn.Write.Select(&rows, "SELECT "+table.profileID+" AS id, "+table.version+" AS version, dob FROM "+table.name+" WHERE dob IS NOT NULL")
+		}
+		if err != nil {
+			log.Error().Err(err).Str("table", table.name).Msg("Error selecting dates of birth")
+			return
+		}
+
+		for _, row := range rows {
+			dob, err := civil.Parse(row.DoB)
+			if err != nil {
+				_, err = mysqlConn.Write.Exec("UPDATE "+table.name+" SET dob = NULL WHERE dob = ?", row.DoB)
+				if err != nil {
+					log.Error().Err(err).Str("table", table.name).Str("profileId", row.ID).Msg("Error clearing date of birth")
+					return
+				}
+				report.Write([]string{row.ID, row.Version, row.DoB, "cleared: not a valid YYYY-MM-DD date"})
+				cleared++
+				continue
+			}
+			if dob.After(today) {
+				report.Write([]string{row.ID, row.Version, row.DoB, "kept: in the future"})
+				future++
+			}
+		}
+
+		if columnType != "date" {
+			_, err = mysqlConn.Write.Exec("ALTER TABLE " + table.name + " MODIFY dob DATE NULL")
+			if err != nil {
+				log.Error().Err(err).Str("table", table.name).Msg("Error changing dob column to DATE")
+				return
+			}
+		}
+	}
+
+	var indexed bool
+	err := mysqlConn.Write.Get(&indexed, `
+		SELECT EXISTS(
+			SELECT index_name
+			FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = 'ums_profiles' AND index_name = 'idx_ums_profiles_dob'
+		)`)
+	if err != nil {
+		log.Error().Err(err).Msg("Error checking dob index")
+		return
+	}
+	if !indexed {
+		_, err = mysqlConn.Write.Exec("CREATE INDEX idx_ums_profiles_dob ON ums_profiles (dob)")
+		if err != nil {
+			log.Error().Err(err).Msg("Error creating dob index")
+			return
+		}
+	}
+
+	report.Flush()
+	if err := report.Error(); err != nil {
+		log.Error().Err(err).Msg("Error writing report")
+		return
+	}
+
+	_, err = mysqlConn.Write.Exec("INSERT IGNORE INTO ums_schema_migrations (version, applied_at) VALUES (18, NOW())")
+	if err != nil {
+		log.Error().Err(err).Msg("Error recording schema version")
+		return
+	}
+
+	log.Info().
+		Int("cleared", cleared).
+		Int("future", future).
+		Msg("Changed dates of birth to DATE.")
+}
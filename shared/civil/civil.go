@@ -0,0 +1,139 @@
+// Package civil provides a calendar date type, for dates such as dates of
+// birth that have no time of day or time zone.
+package civil
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Layout is the format of dates in JSON and in the database.
+const Layout = "2006-01-02"
+
+var ErrInvalidDate = errors.New("date must be in YYYY-MM-DD format")
+
+// Date is a calendar date. It is stored in DATE columns and written in JSON
+// as YYYY-MM-DD.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// Parse parses a date in YYYY-MM-DD format, refusing dates that do not exist
+// such as 2023-02-30.
+func Parse(s string) (Date, error) {
+	t, err := time.Parse(Layout, s)
+	if err != nil {
+		return Date{}, ErrInvalidDate
+	}
+	return Of(t), nil
+}
+
+// Of returns the date of t in its location.
+func Of(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Today returns the current local date.
+func Today() Date {
+	return Of(time.Now())
+}
+
+// String returns d in YYYY-MM-DD format.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Before reports whether d is before other.
+func (d Date) Before(other Date) bool {
+	if d.Year != other.Year {
+		return d.Year < other.Year
+	}
+	if d.Month != other.Month {
+		return d.Month < other.Month
+	}
+	return d.Day < other.Day
+}
+
+// After reports whether d is after other.
+func (d Date) After(other Date) bool {
+	return other.Before(d)
+}
+
+// AddYears returns d moved by years. February 29 becomes February 28 in
+// years that are not leap years.
+func (d Date) AddYears(years int) Date {
+	moved := Date{Year: d.Year + years, Month: d.Month, Day: d.Day}
+	if moved.Month == time.February && moved.Day == 29 && !isLeap(moved.Year) {
+		moved.Day = 28
+	}
+	return moved
+}
+
+// Age returns the number of whole years from d, a date of birth, to on.
+// People born on February 29 have their birthday on March 1 in years that are
+// not leap years.
+func (d Date) Age(on Date) int {
+	age := on.Year - d.Year
+	if on.Month < d.Month || (on.Month == d.Month && on.Day < d.Day) {
+		age--
+	}
+	return age
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidDate
+	}
+	date, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// Scan implements sql.Scanner. DATE columns are read as time.Time with
+// parseTime, or else as text.
+func (d *Date) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case time.Time:
+		*d = Of(src)
+		return nil
+	case []byte:
+		return d.scanString(string(src))
+	case string:
+		return d.scanString(src)
+	}
+	return fmt.Errorf("cannot scan %T into civil.Date", src)
+}
+
+func (d *Date) scanString(s string) error {
+	date, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,76 @@
+package civil
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	d, err := Parse("2000-02-29")
+	require.NoError(t, err)
+	assert.Equal(t, Date{2000, time.February, 29}, d)
+	assert.Equal(t, "2000-02-29", d.String())
+
+	for _, invalid := range []string{"", "2001-02-29", "2000-13-01", "2000-1-1", "01/01/2000", "2000-01-01T00:00:00Z"} {
+		_, err := Parse(invalid)
+		assert.Equal(t, ErrInvalidDate, err, invalid)
+	}
+}
+
+func TestAge(t *testing.T) {
+	birth := Date{2000, time.June, 15}
+	assert.Equal(t, 23, birth.Age(Date{2024, time.June, 14}))
+	assert.Equal(t, 24, birth.Age(Date{2024, time.June, 15}))
+	assert.Equal(t, 0, birth.Age(birth))
+
+	leapling := Date{2004, time.February, 29}
+	assert.Equal(t, 18, leapling.Age(Date{2023, time.February, 28}))
+	assert.Equal(t, 19, leapling.Age(Date{2023, time.March, 1}))
+	assert.Equal(t, 20, leapling.Age(Date{2024, time.February, 29}))
+}
+
+func TestAddYears(t *testing.T) {
+	assert.Equal(t, Date{2023, time.February, 28}, Date{2024, time.February, 29}.AddYears(-1))
+	assert.Equal(t, Date{2020, time.February, 29}, Date{2024, time.February, 29}.AddYears(-4))
+	assert.Equal(t, Date{1990, time.June, 15}, Date{2024, time.June, 15}.AddYears(-34))
+
+	// Someone born on the day AddYears(-n) returns is n years old.
+	today := Date{2024, time.February, 29}
+	assert.Equal(t, 1, today.AddYears(-1).Age(today))
+	assert.Equal(t, 0, Date{2023, time.March, 1}.Age(today))
+}
+
+func TestBefore(t *testing.T) {
+	assert.True(t, Date{2023, time.December, 31}.Before(Date{2024, time.January, 1}))
+	assert.True(t, Date{2024, time.January, 1}.After(Date{2023, time.December, 31}))
+	assert.False(t, Date{2024, time.January, 1}.Before(Date{2024, time.January, 1}))
+}
+
+func TestJSONAndSQL(t *testing.T) {
+	var v struct {
+		DoB *Date `json:"dob"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"dob":"1999-12-31"}`), &v))
+	assert.Equal(t, &Date{1999, time.December, 31}, v.DoB)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"dob":"1999-12-31"}`, string(data))
+
+	assert.Equal(t, ErrInvalidDate, json.Unmarshal([]byte(`{"dob":""}`), &v))
+	assert.Equal(t, ErrInvalidDate, json.Unmarshal([]byte(`{"dob":19991231}`), &v))
+
+	var d Date
+	require.NoError(t, d.Scan(time.Date(1999, time.December, 31, 0, 0, 0, 0, time.FixedZone("WIB", 7*3600))))
+	assert.Equal(t, Date{1999, time.December, 31}, d)
+	require.NoError(t, d.Scan([]byte("2000-01-02")))
+	assert.Equal(t, Date{2000, time.January, 2}, d)
+
+	value, err := d.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "2000-01-02", value)
+}
@@ -4,59 +4,91 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 )
 
+// AuthMethod is how the principal of a request was authenticated.
+type AuthMethod string
+
 const (
-	userIDKey   = "user_id"
-	usernameKey = "username"
-	roleKey     = "role"
-	tokenKey    = "token"
+	// AuthMethodJWT is a JWT issued by the login endpoint.
+	AuthMethodJWT AuthMethod = "jwt"
+	// AuthMethodOAuthClient is an OAuth access token issued to a client
+	// without a user.
+	AuthMethodOAuthClient AuthMethod = "oauth_client"
+	// AuthMethodOAuthUser is an OAuth access token issued to a user.
+	AuthMethodOAuthUser AuthMethod = "oauth_user"
 )
 
-func GetUserIDFromContext(r *http.Request) (string, error) {
-	userID, ok := r.Context().Value(userIDKey).(string)
-	if !ok {
-		return "", errors.New("user ID not found in context")
-	}
-	return userID, nil
-}
+// ErrNoPrincipal is returned when a request carries no authenticated
+// principal, which means the authentication middleware is missing.
+var ErrNoPrincipal = errors.New("principal not found in context")
 
-func GetUsernameFromContext(r *http.Request) (string, error) {
-	username, ok := r.Context().Value(usernameKey).(string)
-	if !ok {
-		return "", errors.New("username not found in context")
-	}
-	return username, nil
-}
+// principalKey is the context key of the Principal. It is unexported so that
+// only this package can set and read the principal.
+type principalKey struct{}
 
-func GetRoleFromContext(r *http.Request) (string, error) {
-	role, ok := r.Context().Value(roleKey).(string)
-	if !ok {
-		return "", errors.New("role not found in context")
-	}
-	return role, nil
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// ID is the user ID. It is empty for OAuth clients acting on their own.
+	ID       string
+	Username string
+	Roles    []string
+	// Permissions are the scopes granted to the token.
+	Permissions []string
+	AuthMethod  AuthMethod
+	// TokenID identifies the token the request was authenticated with
+	// without revealing it.
+	TokenID string
+	// SessionID is the login session of a JWT, if any.
+	SessionID string
+	// ClientID is the OAuth client the token was issued to, if any.
+	ClientID string
 }
 
-func GetTokenFromContext(r *http.Request) (string, error) {
-	token, ok := r.Context().Value(tokenKey).(string)
-	if !ok {
-		return "", errors.New("token not found in context")
+// HasRole reports whether the principal has role, ignoring case.
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
 	}
-	return token, nil
+	return false
 }
 
-func WithUserID(ctx context.Context, userID string) context.Context {
-	return context.WithValue(ctx, userIDKey, userID)
+// HasPermissions reports whether the principal was granted all permissions.
+func (p *Principal) HasPermissions(permissions ...string) bool {
+	for _, permission := range permissions {
+		granted := false
+		for _, p := range p.Permissions {
+			if p == permission {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
 }
 
-func WithUsername(ctx context.Context, username string) context.Context {
-	return context.WithValue(ctx, usernameKey, username)
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
 }
 
-func WithRole(ctx context.Context, role string) context.Context {
-	return context.WithValue(ctx, roleKey, role)
+// PrincipalFrom returns the principal of ctx, if any.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
 }
 
-func WithToken(ctx context.Context, token string) context.Context {
-	return context.WithValue(ctx, tokenKey, token)
+// GetPrincipal returns the principal of the request, or ErrNoPrincipal.
+func GetPrincipal(r *http.Request) (*Principal, error) {
+	principal, ok := PrincipalFrom(r.Context())
+	if !ok {
+		return nil, ErrNoPrincipal
+	}
+	return principal, nil
 }
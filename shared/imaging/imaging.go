@@ -0,0 +1,139 @@
+// Package imaging decodes uploaded images and scales them down, using only
+// the standard library decoders for JPEG, PNG and GIF.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	// Registers the decoders of the supported formats.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// jpegQuality is the quality images are encoded with.
+const jpegQuality = 85
+
+// ErrTooManyPixels is returned by Decode for images larger than allowed,
+// before decoding them.
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// Decode decodes a JPEG, PNG or GIF image, refusing images of more than
+// maxPixels pixels so that small files cannot expand into huge images.
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, format, ErrTooManyPixels
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return img, format, nil
+}
+
+// Fit scales img down, keeping its aspect ratio, so that neither side is
+// longer than size. Images that already fit are returned unchanged.
+func Fit(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+	return resize(img, bounds, width, height)
+}
+
+// Thumbnail crops the center square of img and scales it to size x size.
+// Images smaller than size are not scaled up.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := image.Rect(x, y, x+side, y+side)
+
+	return resize(img, square, min(side, size), min(side, size))
+}
+
+// JPEG encodes img as a JPEG, flattening transparent areas onto white.
+func JPEG(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize scales the area src of img to width x height with a box filter:
+// every pixel is the average of the source pixels it covers, which keeps
+// downscaled images smooth.
+func resize(img image.Image, src image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for dy := 0; dy < height; dy++ {
+		y0 := src.Min.Y + dy*src.Dy()/height
+		y1 := max(y0+1, src.Min.Y+(dy+1)*src.Dy()/height)
+
+		for dx := 0; dx < width; dx++ {
+			x0 := src.Min.X + dx*src.Dx()/width
+			x1 := max(x0+1, src.Min.X+(dx+1)*src.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, pa := img.At(x, y).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			// The channels are premultiplied 16-bit values; RGBA holds
+			// premultiplied 8-bit ones.
+			dst.SetRGBA(dx, dy, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	data := encodePNG(t, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	img, format, err := Decode(data, 800)
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+
+	_, _, err = Decode(data, 799)
+	assert.Equal(t, ErrTooManyPixels, err)
+
+	_, _, err = Decode([]byte("not an image"), 800)
+	assert.Error(t, err)
+}
+
+func TestFit(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	assert.Equal(t, image.Rect(0, 0, 200, 50), Fit(img, 200).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 25, 100), Fit(image.NewRGBA(image.Rect(0, 0, 100, 400)), 100).Bounds())
+	assert.Equal(t, img, Fit(img, 400))
+}
+
+func TestThumbnail(t *testing.T) {
+	// A wide image, red on the left and right quarters and blue in the
+	// middle, whose center square is all blue.
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for x := 0; x < 200; x++ {
+		for y := 0; y < 100; y++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 50 && x < 150 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	thumbnail := Thumbnail(img, 10)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), thumbnail.Bounds())
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, color.RGBAModel.Convert(thumbnail.At(0, 0)))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, color.RGBAModel.Convert(thumbnail.At(9, 9)))
+
+	assert.Equal(t, image.Rect(0, 0, 100, 100), Thumbnail(img, 256).Bounds())
+}
+
+func TestJPEG(t *testing.T) {
+	data, err := JPEG(image.NewNRGBA(image.Rect(0, 0, 10, 10)))
+	require.NoError(t, err)
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+
+	// Transparent pixels become white.
+	r, g, b, _ := img.At(5, 5).RGBA()
+	assert.True(t, r > 0xf000 && g > 0xf000 && b > 0xf000)
+}
@@ -0,0 +1,129 @@
+package jwks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Errors returned when validating claims.
+var (
+	ErrTokenExpired      = errors.New("jwks: token is expired")
+	ErrTokenNotValidYet  = errors.New("jwks: token is not valid yet")
+	ErrTokenUsedEarly    = errors.New("jwks: token was issued in the future")
+	ErrInvalidIssuer     = errors.New("jwks: token has an invalid issuer")
+	ErrInvalidAudience   = errors.New("jwks: token has an invalid audience")
+	ErrMissingClaim      = errors.New("jwks: token is missing a required claim")
+	ErrUnexpectedSubject = errors.New("jwks: token subject does not match user")
+	ErrUnexpectedUse     = errors.New("jwks: token is not meant for this use")
+)
+
+// Token uses other than access tokens, set in the token_use claim.
+const (
+	// TokenUseMFAChallenge is a token proving the password of a user with MFA
+	// enabled, to be exchanged for an access token with a second factor.
+	TokenUseMFAChallenge = "mfa_challenge"
+)
+
+// Authentication method references (RFC 8176) of the amr claim.
+const (
+	AuthMethodPassword = "pwd"
+	AuthMethodOTP      = "otp"
+	AuthMethodMFA      = "mfa"
+)
+
+// Claims are the claims of the access tokens issued to users. The user ID is
+// carried both as the standard sub claim and as user_id, which tokens had
+// before the standard claims were introduced.
+type Claims struct {
+	jwt.RegisteredClaims
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Scope    string `json:"scope,omitempty"`
+	// AuthMethods are how the user authenticated, such as pwd and otp.
+	AuthMethods []string `json:"amr,omitempty"`
+	// TokenUse is empty for access tokens.
+	TokenUse string `json:"token_use,omitempty"`
+	// SessionID is the login session the token belongs to, which can be
+	// revoked before the token expires.
+	SessionID string `json:"sid,omitempty"`
+}
+
+// Validation are the rules claims are validated against. Issuer and Audience
+// are only checked when set. Leeway is the tolerated clock skew between the
+// issuer and the verifier. TokenUse must match the token_use claim, so that
+// tokens meant for other uses are never accepted as access tokens.
+type Validation struct {
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+	TokenUse string
+}
+
+// NewValidation returns the validation rules configured in env var.
+func NewValidation(config *configs.Config) Validation {
+	jwtConfig := config.App.JWT
+	return Validation{
+		Issuer:   jwtConfig.Issuer,
+		Audience: jwtConfig.Audience,
+		Leeway:   time.Duration(jwtConfig.ClockSkewSeconds) * time.Second,
+	}
+}
+
+// Validate checks the time-based, issuer, audience and token use claims and
+// requires the sub, jti and exp claims.
+func (c *Claims) Validate(v Validation, now time.Time) error {
+	if c.Subject == "" || c.ID == "" || c.ExpiresAt == nil {
+		return ErrMissingClaim
+	}
+
+	if c.Subject != c.UserID {
+		return ErrUnexpectedSubject
+	}
+
+	if c.TokenUse != v.TokenUse {
+		return ErrUnexpectedUse
+	}
+
+	if !c.VerifyExpiresAt(now.Add(-v.Leeway), true) {
+		return ErrTokenExpired
+	}
+
+	if !c.VerifyNotBefore(now.Add(v.Leeway), false) {
+		return ErrTokenNotValidYet
+	}
+
+	if !c.VerifyIssuedAt(now.Add(v.Leeway), false) {
+		return ErrTokenUsedEarly
+	}
+
+	if v.Issuer != "" && !c.VerifyIssuer(v.Issuer, true) {
+		return ErrInvalidIssuer
+	}
+
+	if v.Audience != "" && !c.VerifyAudience(v.Audience, true) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// ParseClaims verifies the signature of a token with the key set and validates
+// its claims.
+func (s *KeySet) ParseClaims(tokenString string, v Validation) (*Claims, error) {
+	claims := &Claims{}
+	// Claims are validated below, with the configured leeway.
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	if _, err := parser.ParseWithClaims(tokenString, claims, s.Keyfunc); err != nil {
+		return nil, err
+	}
+
+	if err := claims.Validate(v, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,59 @@
+package jwks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimsValidate(t *testing.T) {
+	now := time.Now()
+	validation := Validation{Issuer: "https://ums.example", Audience: "ums", Leeway: 30 * time.Second}
+
+	newClaims := func() *Claims {
+		return &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "https://ums.example",
+				Subject:   "user-id",
+				Audience:  jwt.ClaimStrings{"ums"},
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+				NotBefore: jwt.NewNumericDate(now),
+				IssuedAt:  jwt.NewNumericDate(now),
+				ID:        "token-id",
+			},
+			UserID: "user-id",
+		}
+	}
+
+	assert.NoError(t, newClaims().Validate(validation, now))
+
+	tests := []struct {
+		name   string
+		modify func(c *Claims)
+		err    error
+	}{
+		{"expired beyond leeway", func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }, ErrTokenExpired},
+		{"not valid yet beyond leeway", func(c *Claims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Minute)) }, ErrTokenNotValidYet},
+		{"issued in the future beyond leeway", func(c *Claims) { c.IssuedAt = jwt.NewNumericDate(now.Add(time.Minute)) }, ErrTokenUsedEarly},
+		{"other issuer", func(c *Claims) { c.Issuer = "https://other.example" }, ErrInvalidIssuer},
+		{"other audience", func(c *Claims) { c.Audience = jwt.ClaimStrings{"other"} }, ErrInvalidAudience},
+		{"missing jti", func(c *Claims) { c.ID = "" }, ErrMissingClaim},
+		{"subject mismatch", func(c *Claims) { c.UserID = "other-id" }, ErrUnexpectedSubject},
+		{"other token use", func(c *Claims) { c.TokenUse = TokenUseMFAChallenge }, ErrUnexpectedUse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := newClaims()
+			tt.modify(claims)
+			assert.Equal(t, tt.err, claims.Validate(validation, now))
+		})
+	}
+
+	// Clock skew within the leeway is tolerated.
+	claims := newClaims()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(-10 * time.Second))
+	claims.NotBefore = jwt.NewNumericDate(now.Add(10 * time.Second))
+	assert.NoError(t, claims.Validate(validation, now))
+}
@@ -0,0 +1,242 @@
+package jwks
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// keyFileExtension is the extension of the PEM files loaded as keys. The file
+// name without the extension is the key ID.
+const keyFileExtension = ".pem"
+
+var (
+	// ErrUnknownKey is returned when a token was signed by a key that is not
+	// in the key set.
+	ErrUnknownKey = errors.New("jwks: unknown signing key")
+	// ErrAlgorithmMismatch is returned when the algorithm of a token does not
+	// match the algorithm of the key it claims to be signed with.
+	ErrAlgorithmMismatch = errors.New("jwks: signing algorithm does not match key")
+)
+
+// Key is a JWT signing or verification key.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// signingKey is nil for verify-only keys.
+	signingKey interface{}
+	verifyKey  interface{}
+}
+
+// KeySet holds the keys JWTs are signed and verified with. Tokens are signed
+// with the active key and verified with the key named by their kid header,
+// so retired keys keep verifying the tokens they signed until these expire.
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// ProvideKeySet is the provider for KeySet. Keys are loaded from the PEM files
+// of APP.JWT.KEYS_DIR. Without a keys directory, tokens are signed with HS256
+// using APP.JWT_ACCESS_KEY, which is only meant for development.
+func ProvideKeySet(config *configs.Config) *KeySet {
+	jwtConfig := config.App.JWT
+	if jwtConfig.KeysDir == "" {
+		log.Warn().Msg("No JWT keys directory configured, signing JWTs with HS256.")
+		return NewHMACKeySet([]byte(config.App.JWTAccessKey))
+	}
+
+	keySet, err := Load(jwtConfig.KeysDir, jwtConfig.ActiveKeyID)
+	if err != nil {
+		log.Fatal().Err(err).Str("dir", jwtConfig.KeysDir).Msg("Failed to load JWT keys")
+	}
+
+	log.Info().Str("kid", keySet.active.ID).Str("alg", keySet.active.Method.Alg()).Int("keys", len(keySet.keys)).Msg("JWT keys loaded.")
+	return keySet
+}
+
+// NewHMACKeySet creates a key set with a single HS256 secret and no key ID.
+func NewHMACKeySet(secret []byte) *KeySet {
+	key := &Key{
+		Method:     jwt.SigningMethodHS256,
+		signingKey: secret,
+		verifyKey:  secret,
+	}
+	return &KeySet{
+		active: key,
+		keys:   map[string]*Key{"": key},
+	}
+}
+
+// Load loads the RSA and Ed25519 keys of the PEM files in dir. Private keys
+// can sign and verify, public keys can only verify. The active key must be a
+// private key.
+func Load(dir, activeKeyID string) (*KeySet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &KeySet{keys: make(map[string]*Key)}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(file), keyFileExtension)
+		key, err := ParseKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %s: %w", kid, err)
+		}
+		keySet.keys[kid] = key
+	}
+
+	active, ok := keySet.keys[activeKeyID]
+	if !ok {
+		return nil, fmt.Errorf("jwks: active key %q not found", activeKeyID)
+	}
+	if active.signingKey == nil {
+		return nil, fmt.Errorf("jwks: active key %q is not a private key", activeKeyID)
+	}
+	keySet.active = active
+
+	return keySet, nil
+}
+
+// ParseKey parses a PEM-encoded RSA or Ed25519 private or public key. RSA keys
+// are used with RS256 and Ed25519 keys with EdDSA.
+func ParseKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: kid}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.signingKey = signer
+		parsed = signer.Public()
+	}
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+		key.verifyKey = public
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.verifyKey = public
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", public)
+	}
+
+	return key, nil
+}
+
+// Sign signs claims with the active key, setting the kid header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	if s.active.ID != "" {
+		token.Header["kid"] = s.active.ID
+	}
+	return token.SignedString(s.active.signingKey)
+}
+
+// Keyfunc returns the key to verify a token with, as named by its kid header.
+// It is meant to be passed to the jwt parse functions.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrAlgorithmMismatch
+	}
+
+	return key.verifyKey, nil
+}
+
+// JSONWebKey is a public key in the JSON Web Key format (RFC 7517).
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA public key parameters.
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+	// Ed25519 public key parameters (RFC 8037).
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet is a set of public keys in the JSON Web Key Set format.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns the public keys of the key set, sorted by key ID. HMAC secrets
+// are never published.
+func (s *KeySet) JWKS() JSONWebKeySet {
+	keySet := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range s.keys {
+		jwk := JSONWebKey{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+
+		keySet.Keys = append(keySet.Keys, jwk)
+	}
+
+	sort.Slice(keySet.Keys, func(i, j int) bool {
+		return keySet.Keys[i].KeyID < keySet.Keys[j].KeyID
+	})
+	return keySet
+}
@@ -0,0 +1,83 @@
+package jwks
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, kid+keyFileExtension), data, 0600))
+}
+
+func TestKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, dir, "old", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	writePEM(t, dir, "new", "PRIVATE KEY", der)
+
+	oldKeySet, err := Load(dir, "old")
+	require.NoError(t, err)
+	oldToken, err := oldKeySet.Sign(jwt.MapClaims{"sub": "user"})
+	require.NoError(t, err)
+
+	// Retire the old key by publishing only its public key.
+	der, err = x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, dir, "old", "PUBLIC KEY", der)
+
+	keySet, err := Load(dir, "new")
+	require.NoError(t, err)
+	newToken, err := keySet.Sign(jwt.MapClaims{"sub": "user"})
+	require.NoError(t, err)
+
+	for _, tokenString := range []string{oldToken, newToken} {
+		token, err := jwt.Parse(tokenString, keySet.Keyfunc)
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+	}
+
+	token, err := jwt.Parse(newToken, keySet.Keyfunc)
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.Header["kid"])
+	assert.Equal(t, "EdDSA", token.Header["alg"])
+
+	_, err = Load(dir, "old")
+	assert.Error(t, err, "a public key cannot be the active key")
+
+	jwks := keySet.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "new", jwks.Keys[0].KeyID)
+	assert.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	assert.Equal(t, "old", jwks.Keys[1].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", jwks.Keys[1].Exponent)
+}
+
+func TestKeyfuncRejectsAlgorithmMismatch(t *testing.T) {
+	keySet := NewHMACKeySet([]byte("secret"))
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = jwt.Parse(tokenString, keySet.Keyfunc)
+	assert.Error(t, err)
+	assert.Empty(t, keySet.JWKS().Keys)
+}
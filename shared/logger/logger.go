@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"context"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/evermos/boilerplate-go/configs"
@@ -10,6 +12,21 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// Supported log formats.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// Log fields attached to request-scoped loggers.
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldRole      = "role"
+)
+
+type requestIDKey struct{}
+
 // InitLogger initializes the logger
 func InitLogger() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -22,6 +39,58 @@ func InitLogger() {
 	log.Trace().Msg("Zerolog initialized.")
 }
 
+// SetLogFormat switches the log output to the format specified in env var.
+// Supported formats are "console" (the default) and "json".
+func SetLogFormat(config *configs.Config) {
+	switch strings.ToLower(config.Server.LogFormat) {
+	case FormatJSON:
+		log.Logger = zerolog.New(os.Stdout).With().Timestamp().Logger()
+		log.Trace().Str("logformat", FormatJSON).Msg("Desired log format detected.")
+	default:
+		log.Trace().Str("logformat", FormatConsole).Msg("Using default log format.")
+	}
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID and a logger
+// that includes it in every entry.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	l := log.With().Str(FieldRequestID, requestID).Logger()
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return l.WithContext(ctx)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// AddUser attaches the authenticated user to the request-scoped logger in ctx
+// so that every subsequent entry of the request carries it. It does nothing
+// when ctx has no request-scoped logger.
+func AddUser(ctx context.Context, userID, role string) {
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		return
+	}
+	l.UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str(FieldUserID, userID).Str(FieldRole, role)
+	})
+}
+
+// FromContext returns the request-scoped logger stored in ctx, falling back to
+// the global logger when there is none.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	if ctx == nil {
+		return &log.Logger
+	}
+	l := zerolog.Ctx(ctx)
+	if l.GetLevel() == zerolog.Disabled {
+		return &log.Logger
+	}
+	return l
+}
+
 // ErrorWithStack logs and error and its stack trace with custom formatting.
 func ErrorWithStack(err error) {
 	log.Error().Msgf("%+v", errors.WithStack(err))
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileMailer writes emails as .eml files to a directory instead of sending
+// them, for development.
+type FileMailer struct {
+	dir string
+}
+
+// NewFileMailer creates a mailer writing emails to dir.
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir}
+}
+
+// Send writes message to a new file named after the time it was sent.
+func (m *FileMailer) Send(ctx context.Context, message Message) error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.New().String())
+	return ioutil.WriteFile(filepath.Join(m.dir, name), message.Bytes(), 0o600)
+}
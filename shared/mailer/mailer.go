@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/rs/zerolog/log"
+)
+
+// Mail drivers.
+const (
+	DriverFile   = "file"
+	DriverMemory = "memory"
+	DriverSMTP   = "smtp"
+)
+
+// defaultDir is where the file mailer writes messages when no directory is
+// configured.
+const defaultDir = "mails"
+
+// Message is a plain text email.
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// ProvideMailer returns the mailer selected by the driver configured in env
+// var, sending from the configured address.
+func ProvideMailer(config *configs.Config) Mailer {
+	mailConfig := config.App.Mail
+
+	var mailer Mailer
+	switch strings.ToLower(mailConfig.Driver) {
+	case DriverSMTP:
+		mailer = NewSMTPMailer(mailConfig.SMTP.Host, mailConfig.SMTP.Port, mailConfig.SMTP.Username, mailConfig.SMTP.Password)
+	case DriverMemory:
+		mailer = NewMemoryMailer()
+	case DriverFile, "":
+		dir := mailConfig.Dir
+		if dir == "" {
+			dir = defaultDir
+		}
+		mailer = NewFileMailer(dir)
+	default:
+		log.Fatal().Str("driver", mailConfig.Driver).Msg("Unsupported mail driver")
+	}
+
+	log.Info().Str("driver", mailConfig.Driver).Msg("Mailer initialized.")
+	return WithSender(mailer, mailConfig.From)
+}
+
+// WithSender returns a mailer setting the sender of messages without one to
+// from.
+func WithSender(mailer Mailer, from string) Mailer {
+	return senderMailer{mailer: mailer, from: from}
+}
+
+type senderMailer struct {
+	mailer Mailer
+	from   string
+}
+
+func (m senderMailer) Send(ctx context.Context, message Message) error {
+	if message.From == "" {
+		message.From = m.from
+	}
+	return m.mailer.Send(ctx, message)
+}
+
+// Bytes renders the message in RFC 5322 format.
+func (m Message) Bytes() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.Replace(m.Body, "\n", "\r\n", -1))
+	return buf.Bytes()
+}
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBytes(t *testing.T) {
+	message := Message{
+		From:    "noreply@example.com",
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Verify your email",
+		Body:    "Hello\nWorld",
+	}
+
+	raw := string(message.Bytes())
+	assert.Contains(t, raw, "From: noreply@example.com\r\n")
+	assert.Contains(t, raw, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, raw, "Subject: Verify your email\r\n")
+	assert.Contains(t, raw, "\r\n\r\nHello\r\nWorld")
+}
+
+func TestWithSender(t *testing.T) {
+	memory := NewMemoryMailer()
+	mailer := WithSender(memory, "noreply@example.com")
+
+	require.NoError(t, mailer.Send(context.Background(), Message{To: []string{"a@example.com"}}))
+	require.NoError(t, mailer.Send(context.Background(), Message{From: "admin@example.com", To: []string{"a@example.com"}}))
+
+	messages := memory.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "noreply@example.com", messages[0].From)
+	assert.Equal(t, "admin@example.com", messages[1].From)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mails")
+	mailer := NewFileMailer(dir)
+
+	require.NoError(t, mailer.Send(context.Background(), Message{
+		From:    "noreply@example.com",
+		To:      []string{"a@example.com"},
+		Subject: "Hello",
+		Body:    "Body",
+	}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "Subject: Hello")
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ums"
+
+// Login results recorded by ObserveLogin.
+const (
+	LoginSuccess      = "success"
+	LoginFailure      = "failure"
+	LoginMFAChallenge = "mfa_challenge"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests by route, method and status.",
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	loginAttemptsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "login_attempts_total",
+			Help:      "Total number of login attempts by result and reason.",
+		},
+		[]string{"result", "reason"},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		loginAttemptsTotal,
+	)
+}
+
+// Handler returns the HTTP handler exposing all registered metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records a served HTTP request. The route should be the
+// router pattern (e.g. /v1/users/{uuid}) rather than the raw path to keep the
+// label cardinality bounded.
+func ObserveHTTPRequest(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequestsTotal.WithLabelValues(route, method, code).Inc()
+	httpRequestDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+}
+
+// ObserveLogin records the result of a login attempt.
+func ObserveLogin(result, reason string) {
+	loginAttemptsTotal.WithLabelValues(result, reason).Inc()
+}
+
+// RegisterDBStats exposes the connection pool statistics of a database.
+func RegisterDBStats(name string, db *sqlx.DB) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db.DB, name))
+}
+
+// QueueDepther is implemented by queues that can report their backlog.
+type QueueDepther interface {
+	QueueDepth() int
+}
+
+// RegisterQueueDepth exposes the number of pending messages of a queue.
+func RegisterQueueDepth(name string, queue QueueDepther) {
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   "pubsub",
+			Name:        "queue_depth",
+			Help:        "Number of messages waiting to be dispatched.",
+			ConstLabels: prometheus.Labels{"queue": name},
+		},
+		func() float64 {
+			return float64(queue.QueueDepth())
+		},
+	))
+}
@@ -1,6 +1,8 @@
 package oauth
 
 import (
+	"time"
+
 	"github.com/jmoiron/sqlx"
 )
 
@@ -9,6 +11,7 @@ type GrantType string
 const (
 	ClientCredentials GrantType = "client_credentials"
 	Password          GrantType = "password"
+	RefreshToken      GrantType = "refresh_token"
 )
 
 type Token struct {
@@ -24,8 +27,13 @@ func New(db *sqlx.DB, config Config) *Token {
 }
 
 type Config struct {
-	Expiration  int64
-	ClientScope []string
+	Expiration        int64
+	RefreshExpiration int64
+	ClientScope       []string
+	// RequireMFAForAdmin refuses the password and refresh token grants of
+	// admins, who must enroll in MFA, as APP.MFA.REQUIRE_FOR_ADMIN does on
+	// login.
+	RequireMFAForAdmin bool
 }
 
 // Create is function to store NewToken into database
@@ -43,6 +51,21 @@ func (t *Token) ParseWithAccessToken(accessToken string) (OauthAccessToken, erro
 	return NewParser(t.tokenRepository).Parse(accessToken)
 }
 
+// ResolveUser returns the user an access token was issued to, who must still
+// be active.
+func (t *Token) ResolveUser(userID string) (User, error) {
+	user, err := t.tokenRepository.resolveUserByID(userID)
+	if err != nil {
+		return User{}, err
+	}
+
+	if !user.IsActive(time.Now()) {
+		return User{}, NewError(ErrCodeInvalidGrant, ErrorAccountInactive)
+	}
+
+	return user, nil
+}
+
 // ClientScopeAllowed is function that is used to limit the client
 // set * to allowed all client example in confing, ex : ClientScope: ["*"] or keep it empty
 // set clientId to limit scope, ex : ClientScope: ["client_web"]
@@ -65,3 +88,92 @@ func (t *Token) ClientScopeAllowed(clientID string) bool {
 
 	return false
 }
+
+// AuthenticateClient verifies the client credentials of a request made to a
+// protected endpoint such as revocation or introspection.
+func (t *Token) AuthenticateClient(credential Credential) (OauthClient, error) {
+	client, err := t.tokenRepository.resolveClientByClientID(credential.ClientID)
+	if err != nil {
+		return OauthClient{}, err
+	}
+
+	if !client.VerifyClient(credential) {
+		return OauthClient{}, NewError(ErrCodeInvalidClient, ErrorInvalidClient)
+	}
+
+	return client, nil
+}
+
+// Revoke revokes an access or refresh token issued to the client, as defined
+// by RFC 7009. The hint, if any, selects which kind of token is tried first.
+// Unknown tokens and tokens of other clients are ignored.
+func (t *Token) Revoke(client OauthClient, token string, hint TokenTypeHint) error {
+	revokers := []func(token, clientID string) error{
+		t.tokenRepository.deleteAccessToken,
+		t.tokenRepository.deleteRefreshTokenOfClient,
+	}
+	if hint == HintRefreshToken {
+		revokers[0], revokers[1] = revokers[1], revokers[0]
+	}
+
+	for _, revoke := range revokers {
+		if err := revoke(token, client.ClientID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Introspect returns the state of an access or refresh token, as defined by
+// RFC 7662. Expired, revoked and unknown tokens are reported as inactive.
+func (t *Token) Introspect(token string, hint TokenTypeHint) (IntrospectionResponse, error) {
+	introspectors := []func(token string) (IntrospectionResponse, error){
+		t.introspectAccessToken,
+		t.introspectRefreshToken,
+	}
+	if hint == HintRefreshToken {
+		introspectors[0], introspectors[1] = introspectors[1], introspectors[0]
+	}
+
+	for _, introspect := range introspectors {
+		response, err := introspect(token)
+		if err != nil || response.Active {
+			return response, err
+		}
+	}
+
+	return IntrospectionResponse{Active: false}, nil
+}
+
+func (t *Token) introspectAccessToken(token string) (IntrospectionResponse, error) {
+	accessToken, found, err := t.tokenRepository.findAccessToken(token)
+	if err != nil || !found || !accessToken.VerifyExpireIn() {
+		return IntrospectionResponse{}, err
+	}
+
+	return IntrospectionResponse{
+		Active:    true,
+		Scope:     accessToken.Scope.String,
+		ClientID:  accessToken.ClientID,
+		TokenType: string(Bearer),
+		ExpiresAt: accessToken.Expires.Unix(),
+		Subject:   accessToken.UserID.String,
+	}, nil
+}
+
+func (t *Token) introspectRefreshToken(token string) (IntrospectionResponse, error) {
+	refreshToken, found, err := t.tokenRepository.findRefreshToken(token)
+	if err != nil || !found || !refreshToken.VerifyExpireIn() {
+		return IntrospectionResponse{}, err
+	}
+
+	return IntrospectionResponse{
+		Active:    true,
+		Scope:     refreshToken.Scope.String,
+		ClientID:  refreshToken.ClientID,
+		TokenType: string(HintRefreshToken),
+		ExpiresAt: refreshToken.Expires.Unix(),
+		Subject:   refreshToken.UserID.String,
+	}, nil
+}
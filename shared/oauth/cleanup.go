@@ -0,0 +1,92 @@
+package oauth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// cleanupBatchSize is the maximum number of rows deleted from each token table
+// per statement, so that a purge never holds locks for long.
+const cleanupBatchSize = 1000
+
+// CleanupJob periodically purges expired access tokens and refresh tokens.
+type CleanupJob struct {
+	tokenStore TokenStore
+	interval   time.Duration
+	stop       chan struct{}
+	done       chan struct{}
+	once       sync.Once
+}
+
+// NewCleanupJob creates a cleanup job running every interval.
+func NewCleanupJob(db *sqlx.DB, interval time.Duration) *CleanupJob {
+	return &CleanupJob{
+		tokenStore: NewTokenStore(db),
+		interval:   interval,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start runs the job in the background until Stop is called.
+func (j *CleanupJob) Start() {
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				j.Run()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the job, waiting for a purge in progress to complete or for ctx
+// to be done. It must only be called after Start.
+func (j *CleanupJob) Stop(ctx context.Context) error {
+	j.once.Do(func() { close(j.stop) })
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run purges expired tokens in batches until none are left.
+func (j *CleanupJob) Run() {
+	var total int64
+	for {
+		select {
+		case <-j.stop:
+			return
+		default:
+		}
+
+		deleted, err := j.tokenStore.purgeExpiredTokens(time.Now(), cleanupBatchSize)
+		total += deleted
+		if err != nil {
+			log.Error().Err(err).Int64("deleted", total).Msg("Failed to purge expired OAuth tokens")
+			return
+		}
+
+		if deleted < cleanupBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Info().Int64("deleted", total).Msg("Purged expired OAuth tokens.")
+	}
+}
@@ -1,9 +1,5 @@
 package oauth
 
-import (
-	"errors"
-)
-
 type ClientCredentialsAuth struct {
 	tokenStore TokenStore
 	config     Config
@@ -16,17 +12,29 @@ func (c *ClientCredentialsAuth) Create(credential Credential) (oauthAccessToken
 	}
 
 	if !client.VerifyClient(credential) {
-		err = errors.New(ErrorInvalidClient)
+		err = NewError(ErrCodeInvalidClient, ErrorInvalidClient)
+		return
+	}
+
+	if !client.AllowsGrantType(credential.GrantType) {
+		err = NewError(ErrCodeUnauthorizedClient, ErrorUnauthorizedGrant)
+		return
+	}
+
+	// Clients acting on their own have no role, so they only get the admin
+	// scopes when flagged as admin.
+	grantedScope, err := negotiateScope(credential.Scope, client.ClientCredentialsScopes())
+	if err != nil {
 		return
 	}
 
 	accessToken, err := generateAccessToken()
 	if err != nil {
-		err = errors.New(ErrorGenerateAccessToken)
+		err = NewError(ErrCodeServerError, ErrorGenerateAccessToken)
 		return
 	}
 
-	oauthAccessToken = new(OauthAccessToken).Generate(accessToken, credential.ClientID, nil, true, c.config)
+	oauthAccessToken = new(OauthAccessToken).Generate(accessToken, credential.ClientID, nil, grantedScope, c.config)
 	err = c.tokenStore.createAccessToken(oauthAccessToken)
 	if err != nil {
 		return
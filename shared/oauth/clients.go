@@ -0,0 +1,163 @@
+package oauth
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/shared/failure"
+	"github.com/go-sql-driver/mysql"
+	"github.com/guregu/null"
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlErrDuplicateEntry is the MySQL error number of a unique key violation.
+const mysqlErrDuplicateEntry = 1062
+
+var validClientID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// Clients manages the registered OAuth clients. Client secrets are generated
+// here and only their hashes are stored, so a secret can only be seen in the
+// response of the call that generated it.
+type Clients struct {
+	tokenStore TokenStore
+}
+
+// NewClients creates a client manager backed by db.
+func NewClients(db *sqlx.DB) *Clients {
+	return &Clients{
+		tokenStore: NewTokenStore(db),
+	}
+}
+
+// ClientRequest is the request to register a new client.
+type ClientRequest struct {
+	ClientID    string   `json:"clientId"`
+	RedirectURI string   `json:"redirectUri"`
+	GrantTypes  []string `json:"grantTypes"`
+	Scopes      []string `json:"scopes"`
+	// Admin lets the client be granted the admin scopes with the
+	// client_credentials grant.
+	Admin bool `json:"admin"`
+}
+
+// ClientSecretResponse carries a newly generated client secret. It is the only
+// time the secret is returned.
+type ClientSecretResponse struct {
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	RedirectURI  string   `json:"redirectUri,omitempty"`
+	GrantTypes   []string `json:"grantTypes,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Admin        bool     `json:"admin,omitempty"`
+}
+
+// Validate checks the client ID format, the requested grant types and the
+// requested scopes. Only clients using the client_credentials grant may be
+// flagged as admin.
+func (r ClientRequest) Validate() error {
+	if !validClientID.MatchString(r.ClientID) {
+		return failure.BadRequestFromString("clientId must be 1 to 32 letters, digits, underscores or hyphens")
+	}
+
+	if len(r.GrantTypes) == 0 {
+		return failure.BadRequestFromString("grantTypes is required")
+	}
+
+	clientCredentials := false
+	for _, grantType := range r.GrantTypes {
+		switch GrantType(grantType) {
+		case ClientCredentials:
+			clientCredentials = true
+		case Password, RefreshToken:
+		default:
+			return failure.BadRequestFromString("unsupported grant type " + grantType)
+		}
+	}
+
+	if r.Admin && !clientCredentials {
+		return failure.BadRequestFromString("admin only applies to clients using the client_credentials grant")
+	}
+
+	for _, scope := range r.Scopes {
+		if !IsValidScope(scope) {
+			return failure.BadRequestFromString("unsupported scope " + scope)
+		}
+	}
+
+	return nil
+}
+
+// Create registers a new client with a generated secret.
+func (c *Clients) Create(request ClientRequest) (ClientSecretResponse, error) {
+	if err := request.Validate(); err != nil {
+		return ClientSecretResponse{}, err
+	}
+
+	secret, hashedSecret, err := newClientSecret()
+	if err != nil {
+		return ClientSecretResponse{}, err
+	}
+
+	err = c.tokenStore.createClient(OauthClient{
+		ClientID:     request.ClientID,
+		ClientSecret: hashedSecret,
+		RedirectURI:  request.RedirectURI,
+		GrantTypes:   strings.Join(request.GrantTypes, " "),
+		Scope:        null.NewString(FormatScope(request.Scopes), len(request.Scopes) > 0),
+		Admin:        request.Admin,
+	})
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry {
+			return ClientSecretResponse{}, failure.Conflict("create", "oauth client", "client already exists")
+		}
+		return ClientSecretResponse{}, err
+	}
+
+	return ClientSecretResponse{
+		ClientID:     request.ClientID,
+		ClientSecret: secret,
+		RedirectURI:  request.RedirectURI,
+		GrantTypes:   request.GrantTypes,
+		Scopes:       request.Scopes,
+		Admin:        request.Admin,
+	}, nil
+}
+
+// RotateSecret replaces the secret of a client with a newly generated one. The
+// old secret stops working immediately.
+func (c *Clients) RotateSecret(clientID string) (ClientSecretResponse, error) {
+	secret, hashedSecret, err := newClientSecret()
+	if err != nil {
+		return ClientSecretResponse{}, err
+	}
+
+	found, err := c.tokenStore.updateClientSecret(clientID, hashedSecret)
+	if err != nil {
+		return ClientSecretResponse{}, err
+	}
+
+	if !found {
+		return ClientSecretResponse{}, failure.NotFound("oauth client")
+	}
+
+	return ClientSecretResponse{
+		ClientID:     clientID,
+		ClientSecret: secret,
+	}, nil
+}
+
+func newClientSecret() (secret, hashedSecret string, err error) {
+	secret, err = generateClientSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	hashedSecret, err = HashClientSecret(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, hashedSecret, nil
+}
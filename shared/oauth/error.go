@@ -1,5 +1,7 @@
 package oauth
 
+import "net/http"
+
 const (
 	ErrorEmptyCredential     string = "Credential can't be empty"
 	ErrorClientNotFound      string = "Client does not exist"
@@ -8,4 +10,59 @@ const (
 	ErrorInvalidToken        string = "Invalid Token"
 	ErrorTokenTypeMismatch   string = "Token type mismatch"
 	ErrorGenerateAccessToken string = "Error generating access token"
+	ErrorTokenExpired        string = "Token expired"
+	ErrorUnsupportedGrant    string = "Grant type is not supported"
+	ErrorUnauthorizedGrant   string = "Client is not allowed to use this grant type"
+	ErrorMissingParameter    string = "Missing required parameter"
+	ErrorInvalidRefreshToken string = "Invalid refresh token"
+	ErrorInvalidScope        string = "Requested scope is invalid or exceeds the granted scope"
+	ErrorInsufficientScope   string = "Token does not have the required scope"
+	ErrorAccountInactive     string = "User account is not active"
+	ErrorMFAEnabled          string = "User has multi-factor authentication enabled, log in at /v1/auth/login instead"
+	ErrorMFAEnrollment       string = "Admins must enroll in multi-factor authentication, log in at /v1/auth/login to enroll"
+)
+
+// Error codes defined by RFC 6749 section 5.2.
+const (
+	ErrCodeInvalidRequest       = "invalid_request"
+	ErrCodeInvalidClient        = "invalid_client"
+	ErrCodeInvalidGrant         = "invalid_grant"
+	ErrCodeUnauthorizedClient   = "unauthorized_client"
+	ErrCodeUnsupportedGrantType = "unsupported_grant_type"
+	ErrCodeInvalidScope         = "invalid_scope"
+	ErrCodeInsufficientScope    = "insufficient_scope"
+	ErrCodeServerError          = "server_error"
 )
+
+// Error is an OAuth 2.0 error response.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// NewError returns a new OAuth 2.0 error.
+func NewError(code, description string) *Error {
+	return &Error{
+		Code:        code,
+		Description: description,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+// StatusCode returns the HTTP status code to respond with for the error.
+func (e *Error) StatusCode() int {
+	switch e.Code {
+	case ErrCodeInvalidClient:
+		return http.StatusUnauthorized
+	case ErrCodeServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
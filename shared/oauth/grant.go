@@ -20,6 +20,12 @@ func (g *Grant) Create(credential Credential) (OauthAccessToken, error) {
 	authMap := make(map[GrantType]AuthorizationMethod)
 	authMap[ClientCredentials] = &ClientCredentialsAuth{tokenStore: g.TokenStore, config: g.Config}
 	authMap[Password] = &PasswordAuth{tokenStore: g.TokenStore, config: g.Config}
+	authMap[RefreshToken] = &RefreshTokenAuth{tokenStore: g.TokenStore, config: g.Config}
 
-	return authMap[credential.GrantType].Create(credential)
+	method, ok := authMap[credential.GrantType]
+	if !ok {
+		return OauthAccessToken{}, NewError(ErrCodeUnsupportedGrantType, ErrorUnsupportedGrant)
+	}
+
+	return method.Create(credential)
 }
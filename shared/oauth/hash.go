@@ -0,0 +1,46 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// clientSecretBytes is the number of random bytes of a generated client
+// secret.
+const clientSecretBytes = 24
+
+// HashToken returns the hex-encoded SHA-256 digest of an access or refresh
+// token, which is what the token store keeps. Tokens are random and long, so
+// a fast unsalted digest is enough to make a leaked table useless.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashClientSecret returns the bcrypt hash of a client secret.
+func HashClientSecret(secret string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// VerifyClientSecret checks a client secret against its bcrypt hash in
+// constant time.
+func VerifyClientSecret(hashedSecret, secret string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hashedSecret), []byte(secret)) == nil
+}
+
+// generateClientSecret returns a new random client secret.
+func generateClientSecret() (string, error) {
+	b := make([]byte, clientSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,26 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashToken(t *testing.T) {
+	hashed := HashToken("00000c708db9bdf1a70d5988a8f321a82970ceb6")
+
+	assert.Len(t, hashed, 64)
+	assert.Equal(t, hashed, HashToken("00000c708db9bdf1a70d5988a8f321a82970ceb6"))
+	assert.NotEqual(t, hashed, HashToken("00000c708db9bdf1a70d5988a8f321a82970ceb7"))
+}
+
+func TestVerifyClientSecret(t *testing.T) {
+	secret, hashedSecret, err := newClientSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, secret, hashedSecret)
+
+	client := OauthClient{ClientID: "client_web", ClientSecret: hashedSecret}
+	assert.True(t, client.VerifyClient(Credential{ClientID: "client_web", ClientSecret: secret}))
+	assert.False(t, client.VerifyClient(Credential{ClientID: "client_web", ClientSecret: secret + "x"}))
+	assert.False(t, client.VerifyClient(Credential{ClientID: "client_app", ClientSecret: secret}))
+}
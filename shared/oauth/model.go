@@ -1,11 +1,12 @@
 package oauth
 
 import (
-	"golang.org/x/crypto/bcrypt"
-	"strconv"
+	"strings"
 	"time"
 
+	"github.com/evermos/boilerplate-go/shared/civil"
 	"github.com/guregu/null"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type TokenType string
@@ -14,12 +15,6 @@ const (
 	Bearer TokenType = "Bearer"
 )
 
-var scope = struct {
-	User string
-}{
-	"user",
-}
-
 // Credential is
 type Credential struct {
 	GrantType    GrantType
@@ -27,6 +22,8 @@ type Credential struct {
 	ClientSecret string
 	Username     string
 	Password     string
+	RefreshToken string
+	Scope        string
 }
 
 type OauthAccessToken struct {
@@ -35,15 +32,18 @@ type OauthAccessToken struct {
 	UserID      null.String `json:"userId" db:"user_id"`
 	Expires     time.Time   `json:"expires" db:"expires"`
 	Scope       null.String `json:"scope" db:"scope"`
+	// RefreshToken is the refresh token issued along with the access token,
+	// if any. It is stored separately in oauth_refresh_tokens.
+	RefreshToken string `json:"-" db:"-"`
 }
 
-func (o *OauthAccessToken) Generate(accessToken string, clientID string, userID *int, withScope bool, config Config) OauthAccessToken {
+func (o *OauthAccessToken) Generate(accessToken string, clientID string, userID *string, scope string, config Config) OauthAccessToken {
 	if userID != nil {
-		o.UserID = null.StringFrom(strconv.Itoa(*userID))
+		o.UserID = null.StringFrom(*userID)
 	}
 
-	if withScope {
-		o.Scope = null.StringFrom(scope.User)
+	if scope != "" {
+		o.Scope = null.StringFrom(scope)
 	}
 
 	o.ClientID = clientID
@@ -62,27 +62,80 @@ func (o *OauthAccessToken) VerifyExpireIn() bool {
 	return true
 }
 
+// VerifyUserLoggedIn reports whether the token was issued to a user, by a
+// password or refresh grant, rather than to a client acting on its own.
 func (o *OauthAccessToken) VerifyUserLoggedIn() bool {
-	if o.UserID.Valid && !o.Scope.Valid {
-		return true
-	}
-	return false
+	return o.UserID.Valid
 }
 
 func (o *OauthAccessToken) toCreateTokenResponse() *TokenResponse {
+	expiresIn := int64(time.Until(o.Expires).Round(time.Second).Seconds())
+	if expiresIn < 0 {
+		expiresIn = 0
+	}
+
 	return &TokenResponse{
-		AccessToken: o.AccessToken,
-		ExpiresIn:   o.Expires,
-		TokenType:   string(Bearer),
-		Scope:       scope.User,
+		AccessToken:  o.AccessToken,
+		ExpiresIn:    expiresIn,
+		TokenType:    string(Bearer),
+		RefreshToken: o.RefreshToken,
+		Scope:        o.Scope.String,
 	}
 }
 
+// TokenTypeHint is the optional hint about the type of a token submitted for
+// revocation or introspection (RFC 7009 section 2.1).
+type TokenTypeHint string
+
+const (
+	HintAccessToken  TokenTypeHint = "access_token"
+	HintRefreshToken TokenTypeHint = "refresh_token"
+)
+
+type OauthRefreshToken struct {
+	RefreshToken string      `json:"refreshToken" db:"refresh_token"`
+	ClientID     string      `json:"clientId" db:"client_id"`
+	UserID       null.String `json:"userId" db:"user_id"`
+	Expires      time.Time   `json:"expires" db:"expires"`
+	Scope        null.String `json:"scope" db:"scope"`
+}
+
+func (o *OauthRefreshToken) Generate(refreshToken string, accessToken OauthAccessToken, config Config) OauthRefreshToken {
+	o.RefreshToken = refreshToken
+	o.ClientID = accessToken.ClientID
+	o.UserID = accessToken.UserID
+	o.Scope = accessToken.Scope
+	o.Expires = time.Now().Add(time.Second * time.Duration(config.RefreshExpiration))
+
+	return *o
+}
+
+func (o *OauthRefreshToken) VerifyExpireIn() bool {
+	return time.Now().Before(o.Expires)
+}
+
+// IntrospectionResponse is a token introspection response as defined by
+// RFC 7662 section 2.2. Only Active is set for inactive tokens.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+}
+
 type OauthClient struct {
-	ClientID     string `json:"clientId" db:"client_id"`
-	ClientSecret string `json:"clientSecret" db:"client_secret"`
+	ClientID string `json:"clientId" db:"client_id"`
+	// ClientSecret is the bcrypt hash of the client secret.
+	ClientSecret string `json:"-" db:"client_secret"`
 	RedirectURI  string `json:"redirectUri" db:"redirect_uri"`
 	GrantTypes   string `json:"grantTypes" db:"grant_types"`
+	// Scope is the space-separated list of scopes the client may be granted.
+	Scope null.String `json:"scope" db:"scope"`
+	// Admin is whether the client may be granted the admin scopes when acting
+	// on its own, with the client_credentials grant.
+	Admin bool `json:"admin" db:"admin"`
 }
 
 func (o *OauthClient) VerifyClient(credential Credential) bool {
@@ -90,24 +143,89 @@ func (o *OauthClient) VerifyClient(credential Credential) bool {
 		return false
 	}
 
-	if o.ClientSecret != credential.ClientSecret {
-		return false
+	return VerifyClientSecret(o.ClientSecret, credential.ClientSecret)
+}
+
+// AllowsGrantType checks the grant type against the space-separated list of
+// grant types the client is registered for.
+func (o *OauthClient) AllowsGrantType(grantType GrantType) bool {
+	for _, allowed := range strings.Fields(o.GrantTypes) {
+		if allowed == string(grantType) {
+			return true
+		}
 	}
+	return false
+}
 
-	return true
+// AllowedScopes returns the scopes the client may be granted.
+func (o *OauthClient) AllowedScopes() []string {
+	return ParseScope(o.Scope.String)
+}
+
+// ClientCredentialsScopes returns the scopes the client may be granted when
+// acting on its own: its allowed scopes, without the admin scopes unless the
+// client is flagged as admin.
+func (o *OauthClient) ClientCredentialsScopes() []string {
+	if o.Admin {
+		return o.AllowedScopes()
+	}
+
+	scopes := []string{}
+	for _, scope := range o.AllowedScopes() {
+		if !containsScope(adminScopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
 }
 
+// TokenResponse is a successful access token response as defined by RFC 6749
+// section 5.1. ExpiresIn is the lifetime of the token in seconds.
 type TokenResponse struct {
-	AccessToken string
-	ExpiresIn   time.Time
-	TokenType   string
-	Scope       string
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 }
 
+// accountStatusActive is the account status of users allowed to log in.
+const accountStatusActive = "active"
+
 type User struct {
-	ID       int    `json:"id" db:"id"`
+	ID       string `json:"id" db:"id"`
 	Username string `json:"username" db:"username"`
 	Password string `json:"password" db:"password"`
+	Role     string `json:"role" db:"role"`
+	// AccountStatus is "active" for users allowed to log in.
+	AccountStatus string `json:"accountStatus" db:"account_status"`
+	// ValidUntil is the last day the account is active, in YYYY-MM-DD
+	// format.
+	ValidUntil *string `json:"validUntil" db:"valid_until"`
+	// MFAEnabled is whether the user logs in with a second factor.
+	MFAEnabled bool `json:"mfaEnabled" db:"mfa_enabled"`
+}
+
+// IsActive reports whether the user is allowed to log in at now.
+func (u *User) IsActive(now time.Time) bool {
+	if u.AccountStatus != accountStatusActive {
+		return false
+	}
+	return u.ValidUntil == nil || *u.ValidUntil >= now.Format(civil.Layout)
+}
+
+// CheckMFA returns an invalid_grant error if the user must log in with a
+// second factor, which the password and refresh token grants cannot check:
+// users with MFA enabled, and admins when requireForAdmin is set. They log in
+// at /v1/auth/login instead.
+func (u *User) CheckMFA(requireForAdmin bool) error {
+	switch {
+	case u.MFAEnabled:
+		return NewError(ErrCodeInvalidGrant, ErrorMFAEnabled)
+	case requireForAdmin && strings.EqualFold(u.Role, "admin"):
+		return NewError(ErrCodeInvalidGrant, ErrorMFAEnrollment)
+	}
+	return nil
 }
 
 func (u *User) ValidCredential(credential Credential) bool {
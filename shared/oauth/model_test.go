@@ -0,0 +1,41 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyUserLoggedIn(t *testing.T) {
+	userToken := OauthAccessToken{UserID: null.StringFrom("9b2f7c1e"), Scope: null.StringFrom(ScopeProfileRead)}
+	assert.True(t, userToken.VerifyUserLoggedIn())
+
+	clientToken := OauthAccessToken{Scope: null.StringFrom(ScopeUsersRead)}
+	assert.False(t, clientToken.VerifyUserLoggedIn())
+}
+
+func TestCheckMFA(t *testing.T) {
+	// Users with MFA enabled cannot use the password or refresh token grants.
+	user := User{Role: "user", MFAEnabled: true}
+	assert.EqualError(t, user.CheckMFA(false), "invalid_grant: "+ErrorMFAEnabled)
+
+	// Neither can admins required to enroll, until they have.
+	admin := User{Role: "admin"}
+	assert.EqualError(t, admin.CheckMFA(true), "invalid_grant: "+ErrorMFAEnrollment)
+	assert.NoError(t, admin.CheckMFA(false))
+
+	admin.MFAEnabled = true
+	assert.EqualError(t, admin.CheckMFA(true), "invalid_grant: "+ErrorMFAEnabled)
+
+	user.MFAEnabled = false
+	assert.NoError(t, user.CheckMFA(true))
+}
+
+func TestClientCredentialsScopes(t *testing.T) {
+	client := OauthClient{Scope: null.StringFrom("profile:read users:read users:write clients:write")}
+	assert.Equal(t, []string{ScopeProfileRead}, client.ClientCredentialsScopes())
+
+	client.Admin = true
+	assert.Equal(t, []string{ScopeProfileRead, ScopeUsersRead, ScopeUsersWrite, ScopeClientsWrite}, client.ClientCredentialsScopes())
+}
@@ -1,8 +1,6 @@
 package oauth
 
-import (
-	"errors"
-)
+import "time"
 
 type PasswordAuth struct {
 	tokenStore TokenStore
@@ -16,32 +14,64 @@ func (c *PasswordAuth) Create(credential Credential) (oauthAccessToken OauthAcce
 	}
 
 	if !client.VerifyClient(credential) {
-		err = errors.New(ErrorInvalidClient)
+		err = NewError(ErrCodeInvalidClient, ErrorInvalidClient)
 		return
 	}
 
-	user, err := c.tokenStore.resolveByTelephoneOrEmail(credential.Username)
+	if !client.AllowsGrantType(credential.GrantType) {
+		err = NewError(ErrCodeUnauthorizedClient, ErrorUnauthorizedGrant)
+		return
+	}
+
+	if credential.Username == "" || credential.Password == "" {
+		err = NewError(ErrCodeInvalidRequest, ErrorMissingParameter)
+		return
+	}
+
+	user, err := c.tokenStore.resolveUserByUsername(credential.Username)
 	if err != nil {
 		return
 	}
 
 	if !user.ValidCredential(credential) {
-		err = errors.New(ErrorInvalidPassword)
+		err = NewError(ErrCodeInvalidGrant, ErrorInvalidPassword)
+		return
+	}
+
+	if !user.IsActive(time.Now()) {
+		err = NewError(ErrCodeInvalidGrant, ErrorAccountInactive)
+		return
+	}
+
+	err = user.CheckMFA(c.config.RequireMFAForAdmin)
+	if err != nil {
+		return
+	}
+
+	// Users are granted the scopes of their role that the client may be
+	// granted.
+	allowedScopes := intersectScopes(client.AllowedScopes(), ScopesForRole(user.Role))
+	grantedScope, err := negotiateScope(credential.Scope, allowedScopes)
+	if err != nil {
 		return
 	}
 
 	accessToken, err := generateAccessToken()
 	if err != nil {
-		err = errors.New(ErrorGenerateAccessToken)
+		err = NewError(ErrCodeServerError, ErrorGenerateAccessToken)
 		return
 	}
 
-	oauthAccessToken = new(OauthAccessToken).Generate(accessToken, credential.ClientID, &user.ID, false, c.config)
+	oauthAccessToken = new(OauthAccessToken).Generate(accessToken, credential.ClientID, &user.ID, grantedScope, c.config)
 
 	err = c.tokenStore.createAccessToken(oauthAccessToken)
 	if err != nil {
 		return
 	}
 
+	if client.AllowsGrantType(RefreshToken) {
+		return issueRefreshToken(c.tokenStore, oauthAccessToken, oauthAccessToken.Scope, c.config)
+	}
+
 	return
 }
@@ -0,0 +1,123 @@
+package oauth
+
+import (
+	"time"
+
+	"github.com/guregu/null"
+)
+
+// RefreshTokenAuth exchanges a refresh token for a new access token. Refresh
+// tokens are rotated: the presented token is consumed and a new one is issued
+// along with the access token.
+type RefreshTokenAuth struct {
+	tokenStore TokenStore
+	config     Config
+}
+
+func (c *RefreshTokenAuth) Create(credential Credential) (oauthAccessToken OauthAccessToken, err error) {
+	client, err := c.tokenStore.resolveClientByClientID(credential.ClientID)
+	if err != nil {
+		return
+	}
+
+	if !client.VerifyClient(credential) {
+		err = NewError(ErrCodeInvalidClient, ErrorInvalidClient)
+		return
+	}
+
+	if !client.AllowsGrantType(credential.GrantType) {
+		err = NewError(ErrCodeUnauthorizedClient, ErrorUnauthorizedGrant)
+		return
+	}
+
+	if credential.RefreshToken == "" {
+		err = NewError(ErrCodeInvalidRequest, ErrorMissingParameter)
+		return
+	}
+
+	refreshToken, found, err := c.tokenStore.findRefreshToken(credential.RefreshToken)
+	if err != nil {
+		return
+	}
+
+	if !found || refreshToken.ClientID != client.ClientID || !refreshToken.VerifyExpireIn() {
+		err = NewError(ErrCodeInvalidGrant, ErrorInvalidRefreshToken)
+		return
+	}
+
+	// Tokens of users are only refreshed while the account is active and
+	// does not require MFA, as the password grant checks on login, so that
+	// tokens issued before enrolling in MFA stop working.
+	if refreshToken.UserID.Valid {
+		var user User
+		user, err = c.tokenStore.resolveUserByID(refreshToken.UserID.String)
+		if err != nil {
+			return
+		}
+
+		if !user.IsActive(time.Now()) {
+			err = NewError(ErrCodeInvalidGrant, ErrorAccountInactive)
+			return
+		}
+
+		err = user.CheckMFA(c.config.RequireMFAForAdmin)
+		if err != nil {
+			return
+		}
+	}
+
+	// The new access token may be narrower than the original grant, but not
+	// broader (RFC 6749 section 6).
+	grantedScope, err := negotiateScope(credential.Scope, ParseScope(refreshToken.Scope.String))
+	if err != nil {
+		return
+	}
+
+	// A refresh token can only be used once, so a concurrent request that
+	// consumed it first wins.
+	consumed, err := c.tokenStore.consumeRefreshToken(refreshToken.RefreshToken, client.ClientID)
+	if err != nil {
+		return
+	}
+
+	if !consumed {
+		err = NewError(ErrCodeInvalidGrant, ErrorInvalidRefreshToken)
+		return
+	}
+
+	accessToken, err := generateAccessToken()
+	if err != nil {
+		err = NewError(ErrCodeServerError, ErrorGenerateAccessToken)
+		return
+	}
+
+	oauthAccessToken = new(OauthAccessToken).Generate(accessToken, client.ClientID, nil, grantedScope, c.config)
+	oauthAccessToken.UserID = refreshToken.UserID
+
+	err = c.tokenStore.createAccessToken(oauthAccessToken)
+	if err != nil {
+		return
+	}
+
+	// The rotated refresh token keeps the scope of the original grant.
+	return issueRefreshToken(c.tokenStore, oauthAccessToken, refreshToken.Scope, c.config)
+}
+
+// issueRefreshToken creates a refresh token with scope for the access token
+// and attaches it to the access token.
+func issueRefreshToken(tokenStore TokenStore, oauthAccessToken OauthAccessToken, scope null.String, config Config) (OauthAccessToken, error) {
+	token, err := generateAccessToken()
+	if err != nil {
+		return OauthAccessToken{}, NewError(ErrCodeServerError, ErrorGenerateAccessToken)
+	}
+
+	refreshToken := new(OauthRefreshToken).Generate(token, oauthAccessToken, config)
+	refreshToken.Scope = scope
+	err = tokenStore.createRefreshToken(refreshToken)
+	if err != nil {
+		return OauthAccessToken{}, err
+	}
+
+	oauthAccessToken.RefreshToken = refreshToken.RefreshToken
+	return oauthAccessToken, nil
+}
@@ -0,0 +1,103 @@
+package oauth
+
+import (
+	"strings"
+)
+
+// Scopes granted to OAuth tokens and JWTs. A route requiring a scope is only
+// accessible with a token that was granted it.
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeUsersRead    = "users:read"
+	ScopeUsersWrite   = "users:write"
+	ScopeClientsWrite = "clients:write"
+	ScopeMFAEnroll    = "mfa:enroll"
+)
+
+// Scopes lists every scope, in the order they are documented.
+var Scopes = []string{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeClientsWrite,
+	ScopeMFAEnroll,
+}
+
+// adminScopes are the scopes of the admin routes, which clients acting on
+// their own are only granted when flagged as admin.
+var adminScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeClientsWrite}
+
+// roleScopes are the scopes a user of each role may be granted.
+var roleScopes = map[string][]string{
+	"admin":   Scopes,
+	"trainee": {ScopeProfileRead, ScopeProfileWrite, ScopeMFAEnroll},
+}
+
+// ScopesForRole returns the scopes a user of role may be granted. Unknown
+// roles get no scopes.
+func ScopesForRole(role string) []string {
+	return roleScopes[strings.ToLower(role)]
+}
+
+// ParseScope splits a space-delimited scope string (RFC 6749 section 3.3).
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// FormatScope joins scopes into a space-delimited scope string.
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// IsValidScope reports whether s is a known scope.
+func IsValidScope(s string) bool {
+	return containsScope(Scopes, s)
+}
+
+// HasScopes reports whether granted contains all of required.
+func HasScopes(granted []string, required ...string) bool {
+	for _, s := range required {
+		if !containsScope(granted, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// negotiateScope returns the scope to grant for a requested scope string.
+// Without a request, all allowed scopes are granted. Requesting a scope
+// that is not allowed fails with invalid_scope.
+func negotiateScope(requested string, allowed []string) (string, error) {
+	requestedScopes := ParseScope(requested)
+	if len(requestedScopes) == 0 {
+		return FormatScope(allowed), nil
+	}
+
+	if !HasScopes(allowed, requestedScopes...) {
+		return "", NewError(ErrCodeInvalidScope, ErrorInvalidScope)
+	}
+
+	return FormatScope(requestedScopes), nil
+}
+
+// intersectScopes returns the scopes of a that are also in b, in the order of a.
+func intersectScopes(a, b []string) []string {
+	var scopes []string
+	for _, s := range a {
+		if containsScope(b, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func containsScope(scopes []string, s string) bool {
+	for _, scope := range scopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
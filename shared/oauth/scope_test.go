@@ -0,0 +1,30 @@
+package oauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateScope(t *testing.T) {
+	allowed := []string{ScopeProfileRead, ScopeProfileWrite}
+
+	granted, err := negotiateScope("", allowed)
+	assert.NoError(t, err)
+	assert.Equal(t, "profile:read profile:write", granted)
+
+	granted, err = negotiateScope("profile:read", allowed)
+	assert.NoError(t, err)
+	assert.Equal(t, "profile:read", granted)
+
+	_, err = negotiateScope("profile:read users:read", allowed)
+	assert.Equal(t, ErrCodeInvalidScope, err.(*Error).Code)
+}
+
+func TestIntersectScopes(t *testing.T) {
+	client := []string{ScopeProfileRead, ScopeUsersRead}
+
+	assert.Equal(t, []string{ScopeProfileRead, ScopeUsersRead}, intersectScopes(client, ScopesForRole("admin")))
+	assert.Equal(t, []string{ScopeProfileRead}, intersectScopes(client, ScopesForRole("trainee")))
+	assert.Empty(t, intersectScopes(client, ScopesForRole("unknown")))
+}
@@ -3,6 +3,7 @@ package oauth
 import (
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -35,21 +36,78 @@ const (
 		FROM
 			oauth_access_tokens`
 
+	queryInsertRefreshToken = `INSERT INTO oauth_refresh_tokens (
+			refresh_token,
+			client_id,
+			user_id,
+			expires,
+			scope
+		) VALUES (
+			:refresh_token,
+			:client_id,
+			:user_id,
+			:expires,
+			:scope
+		)`
+
+	querySelectRefreshToken = `SELECT
+			refresh_token,
+			client_id,
+			user_id,
+			expires,
+			scope
+		FROM
+			oauth_refresh_tokens`
+
+	queryDeleteAccessToken = `DELETE FROM oauth_access_tokens WHERE access_token = ? AND client_id = ?`
+
+	queryDeleteRefreshToken = `DELETE FROM oauth_refresh_tokens WHERE refresh_token = ? AND client_id = ?`
+
+	queryDeleteExpiredAccessTokens = `DELETE FROM oauth_access_tokens WHERE expires < ? LIMIT ?`
+
+	queryDeleteExpiredRefreshTokens = `DELETE FROM oauth_refresh_tokens WHERE expires < ? LIMIT ?`
+
 	querySelectClients = `SELECT
 			client_id,
 			client_secret,
 			redirect_uri,
-			grant_types
+			grant_types,
+			scope,
+			admin
 		FROM 
 			oauth_clients`
 
+	queryInsertClient = `INSERT INTO oauth_clients (
+			client_id,
+			client_secret,
+			redirect_uri,
+			grant_types,
+			scope,
+			admin
+		) VALUES (
+			:client_id,
+			:client_secret,
+			:redirect_uri,
+			:grant_types,
+			:scope,
+			:admin
+		)`
+
+	queryUpdateClientSecret = `UPDATE oauth_clients SET client_secret = ? WHERE client_id = ?`
+
 	querySelectUser = `
 			SELECT
 				id,
 				username,
-				password
+				password,
+				role,
+				account_status,
+				DATE_FORMAT(valid_until, '%Y-%m-%d') AS valid_until,
+				EXISTS(
+					SELECT user_id FROM ums_user_mfa WHERE user_id = ums_users.id AND enabled
+				) AS mfa_enabled
 			FROM
-				user`
+				ums_users`
 )
 
 func NewTokenStore(db *sqlx.DB) TokenStore {
@@ -65,6 +123,9 @@ func (a *TokenStore) createAccessToken(accessToken OauthAccessToken) error {
 	}
 	defer stmt.Close()
 
+	// Only the digest of the token is stored, so that a leak of the table does
+	// not leak usable bearer tokens.
+	accessToken.AccessToken = HashToken(accessToken.AccessToken)
 	_, err = stmt.Exec(accessToken)
 	if err != nil {
 		return err
@@ -74,15 +135,16 @@ func (a *TokenStore) createAccessToken(accessToken OauthAccessToken) error {
 }
 
 func (a *TokenStore) resolveAccessTokenByAccessToken(accessToken string) (oauthAccessToken OauthAccessToken, err error) {
-	err = a.db.Get(&oauthAccessToken, querySelectAccessToken+" WHERE access_token = ?", accessToken)
+	err = a.db.Get(&oauthAccessToken, querySelectAccessToken+" WHERE access_token = ?", HashToken(accessToken))
 	switch {
 	case err == sql.ErrNoRows:
-		err = errors.New(ErrorClientNotFound)
+		err = errors.New(ErrorInvalidToken)
 		return
 	case err != nil:
 		return
 	}
 
+	oauthAccessToken.AccessToken = accessToken
 	return
 }
 
@@ -101,7 +163,7 @@ func (a *TokenStore) resolveClientByClientID(clientID string) (client OauthClien
 	err = a.db.Get(&client, querySelectClients+" WHERE client_id = ?", clientID)
 	switch {
 	case err == sql.ErrNoRows:
-		err = errors.New(ErrorClientNotFound)
+		err = NewError(ErrCodeInvalidClient, ErrorClientNotFound)
 		return
 	case err != nil:
 		return
@@ -110,16 +172,144 @@ func (a *TokenStore) resolveClientByClientID(clientID string) (client OauthClien
 	return
 }
 
-func (a *TokenStore) resolveByTelephoneOrEmail(username string) (User, error) {
+func (a *TokenStore) resolveUserByUsername(username string) (User, error) {
 	var user User
 
-	err := a.db.Get(&user, querySelectUser+" WHERE telephone = ? OR  email = ?", username, username)
+	err := a.db.Get(&user, querySelectUser+" WHERE username = ?", username)
 	switch {
 	case err == sql.ErrNoRows:
-		return User{}, errors.New(ErrorClientNotFound)
+		return User{}, NewError(ErrCodeInvalidGrant, ErrorInvalidPassword)
 	case err != nil:
 		return User{}, err
 	}
 
 	return user, nil
 }
+
+func (a *TokenStore) resolveUserByID(userID string) (User, error) {
+	var user User
+
+	err := a.db.Get(&user, querySelectUser+" WHERE id = ?", userID)
+	switch {
+	case err == sql.ErrNoRows:
+		return User{}, NewError(ErrCodeInvalidGrant, ErrorAccountInactive)
+	case err != nil:
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// findAccessToken looks an access token up, reporting whether it exists.
+func (a *TokenStore) findAccessToken(accessToken string) (oauthAccessToken OauthAccessToken, found bool, err error) {
+	err = a.db.Get(&oauthAccessToken, querySelectAccessToken+" WHERE access_token = ?", HashToken(accessToken))
+	switch {
+	case err == sql.ErrNoRows:
+		return OauthAccessToken{}, false, nil
+	case err != nil:
+		return OauthAccessToken{}, false, err
+	}
+
+	oauthAccessToken.AccessToken = accessToken
+	return oauthAccessToken, true, nil
+}
+
+func (a *TokenStore) createRefreshToken(refreshToken OauthRefreshToken) error {
+	stmt, err := a.db.PrepareNamed(queryInsertRefreshToken)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	refreshToken.RefreshToken = HashToken(refreshToken.RefreshToken)
+	_, err = stmt.Exec(refreshToken)
+	return err
+}
+
+// findRefreshToken looks a refresh token up, reporting whether it exists.
+func (a *TokenStore) findRefreshToken(refreshToken string) (oauthRefreshToken OauthRefreshToken, found bool, err error) {
+	err = a.db.Get(&oauthRefreshToken, querySelectRefreshToken+" WHERE refresh_token = ?", HashToken(refreshToken))
+	switch {
+	case err == sql.ErrNoRows:
+		return OauthRefreshToken{}, false, nil
+	case err != nil:
+		return OauthRefreshToken{}, false, err
+	}
+
+	oauthRefreshToken.RefreshToken = refreshToken
+	return oauthRefreshToken, true, nil
+}
+
+func (a *TokenStore) deleteAccessToken(accessToken, clientID string) error {
+	_, err := a.db.Exec(queryDeleteAccessToken, HashToken(accessToken), clientID)
+	return err
+}
+
+func (a *TokenStore) deleteRefreshTokenOfClient(refreshToken, clientID string) error {
+	_, err := a.db.Exec(queryDeleteRefreshToken, HashToken(refreshToken), clientID)
+	return err
+}
+
+// consumeRefreshToken deletes a refresh token, reporting whether this call
+// deleted it. Only one of several concurrent refreshes can consume a token.
+func (a *TokenStore) consumeRefreshToken(refreshToken, clientID string) (bool, error) {
+	result, err := a.db.Exec(queryDeleteRefreshToken, HashToken(refreshToken), clientID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// purgeExpiredTokens deletes up to batchSize expired access tokens and
+// refresh tokens, returning the number of deleted rows.
+func (a *TokenStore) purgeExpiredTokens(now time.Time, batchSize int) (int64, error) {
+	var total int64
+	for _, query := range []string{queryDeleteExpiredAccessTokens, queryDeleteExpiredRefreshTokens} {
+		result, err := a.db.Exec(query, now, batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+	}
+
+	return total, nil
+}
+
+// createClient stores a client whose secret has already been hashed.
+func (a *TokenStore) createClient(client OauthClient) error {
+	stmt, err := a.db.PrepareNamed(queryInsertClient)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(client)
+	return err
+}
+
+// updateClientSecret replaces the hashed secret of a client, reporting whether
+// the client exists.
+func (a *TokenStore) updateClientSecret(clientID, hashedSecret string) (bool, error) {
+	result, err := a.db.Exec(queryUpdateClientSecret, hashedSecret, clientID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,145 @@
+// Package phone normalizes phone numbers to E.164, the format SMS gateways
+// expect: a plus sign, the country calling code and the national number, such
+// as +6281234567890.
+//
+// It knows the numbering plans of a few regions, which numbers are validated
+// against: national numbers can only be written in these, and international
+// numbers of these are checked. Numbers of other countries are only checked
+// for the length E.164 allows.
+package phone
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalid       = errors.New("invalid phone number")
+	ErrUnknownRegion = errors.New("unknown phone region")
+)
+
+// maxDigits is the most digits E.164 numbers have, calling code included, and
+// minDigits the fewest of the numbers accepted.
+const (
+	maxDigits = 15
+	minDigits = 7
+)
+
+// region is the numbering plan of a region: its calling code, the prefix
+// dialed before national numbers and international numbers, and the pattern
+// of its national numbers without trunk prefix.
+type region struct {
+	callingCode         string
+	trunkPrefix         string
+	internationalPrefix *regexp.Regexp
+	number              *regexp.Regexp
+}
+
+func (r region) isValid(national string) bool {
+	return r.number.MatchString(national)
+}
+
+// trimTrunkPrefix removes the trunk prefix of a national number, if any.
+func (r region) trimTrunkPrefix(national string) string {
+	if r.trunkPrefix != "" && strings.HasPrefix(national, r.trunkPrefix) {
+		return national[len(r.trunkPrefix):]
+	}
+	return national
+}
+
+func newRegion(callingCode, trunkPrefix, internationalPrefix, number string) region {
+	return region{
+		callingCode:         callingCode,
+		trunkPrefix:         trunkPrefix,
+		internationalPrefix: regexp.MustCompile(`^(?:` + internationalPrefix + `)`),
+		number:              regexp.MustCompile(`^(?:` + number + `)$`),
+	}
+}
+
+// nanp is the North American Numbering Plan shared by the US and Canada.
+var nanp = newRegion("1", "1", "011", `[2-9]\d{2}[2-9]\d{6}`)
+
+// regions are the numbering plans known, by ISO 3166 region code. Patterns
+// cover geographic and mobile numbers, which are the ones people have.
+var regions = map[string]region{
+	// Mobile numbers start with 8, landlines with a 2 to 3 digit area code.
+	"ID": newRegion("62", "0", `00[1789]|01017`, `8[1-9]\d{7,10}|[2-79]\d{6,10}`),
+	"AU": newRegion("61", "0", `0011`, `[2-478]\d{8}`),
+	"CA": nanp,
+	"GB": newRegion("44", "0", `00`, `[1-9]\d{8,9}`),
+	"MY": newRegion("60", "0", `00`, `1\d{8,9}|[3-9]\d{7,8}`),
+	"NL": newRegion("31", "0", `00`, `[1-9]\d{8}`),
+	"SG": newRegion("65", "", `00[1-9]|01[89]`, `[3689]\d{7}`),
+	"US": nanp,
+}
+
+// separators are the characters allowed between the digits of a number.
+var separators = strings.NewReplacer(" ", "", "\u00a0", "", "-", "", ".", "", "(", "", ")", "", "/", "")
+
+// IsKnownRegion reports whether the numbering plan of a region is known.
+func IsKnownRegion(regionCode string) bool {
+	_, ok := regions[strings.ToUpper(regionCode)]
+	return ok
+}
+
+// Normalize returns the E.164 form of a phone number. Numbers starting with +
+// or the international prefix of defaultRegion are international, others
+// are national numbers of defaultRegion, which may also be written with its
+// calling code but without the +, as in 6281234567890. It returns ErrInvalid
+// if the number is not valid.
+func Normalize(number, defaultRegion string) (string, error) {
+	home, ok := regions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return "", ErrUnknownRegion
+	}
+
+	digits := separators.Replace(strings.TrimSpace(number))
+	international := strings.HasPrefix(digits, "+")
+	if international {
+		digits = digits[1:]
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", ErrInvalid
+	}
+
+	if !international {
+		if prefix := home.internationalPrefix.FindString(digits); prefix != "" {
+			digits = digits[len(prefix):]
+			international = true
+		}
+	}
+	if international {
+		return normalizeInternational(digits)
+	}
+
+	national := home.trimTrunkPrefix(digits)
+	if home.isValid(national) {
+		return "+" + home.callingCode + national, nil
+	}
+	if strings.HasPrefix(digits, home.callingCode) && home.isValid(digits[len(home.callingCode):]) {
+		return "+" + digits, nil
+	}
+	return "", ErrInvalid
+}
+
+// normalizeInternational validates an international number, given as the
+// digits after the +.
+func normalizeInternational(digits string) (string, error) {
+	if len(digits) < minDigits || len(digits) > maxDigits || digits[0] == '0' {
+		return "", ErrInvalid
+	}
+
+	for _, r := range regions {
+		if !strings.HasPrefix(digits, r.callingCode) {
+			continue
+		}
+		// The trunk prefix is sometimes kept, as in +62 (0)812 3456 7890.
+		national := r.trimTrunkPrefix(digits[len(r.callingCode):])
+		if !r.isValid(national) {
+			return "", ErrInvalid
+		}
+		return "+" + r.callingCode + national, nil
+	}
+	return "+" + digits, nil
+}
@@ -0,0 +1,66 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	valid := []struct {
+		number   string
+		region   string
+		expected string
+	}{
+		{"0812-3456-7890", "ID", "+6281234567890"},
+		{"0812 3456 789", "ID", "+628123456789"},
+		{"+62 812 3456 7890", "ID", "+6281234567890"},
+		{"+62 (0)812 3456 7890", "ID", "+6281234567890"},
+		{"6281234567890", "ID", "+6281234567890"},
+		{"81234567890", "ID", "+6281234567890"},
+		{"(021) 1234567", "ID", "+62211234567"},
+		{"0274 123456", "ID", "+62274123456"},
+		{"001 1 650 253 0000", "ID", "+16502530000"},
+		{"+1 (650) 253-0000", "ID", "+16502530000"},
+		{"(650) 253-0000", "us", "+16502530000"},
+		{"1-650-253-0000", "US", "+16502530000"},
+		{"011 62 812 3456 7890", "US", "+6281234567890"},
+		{"+65 9123 4567", "ID", "+6591234567"},
+		{"9123 4567", "SG", "+6591234567"},
+		{"012-345 6789", "MY", "+60123456789"},
+		{"020 7946 0018", "GB", "+442079460018"},
+		{"+63 917 123 4567", "ID", "+639171234567"},
+	}
+	for _, test := range valid {
+		normalized, err := Normalize(test.number, test.region)
+		if assert.NoError(t, err, "%q in %s", test.number, test.region) {
+			assert.Equal(t, test.expected, normalized, "%q in %s", test.number, test.region)
+		}
+	}
+
+	invalid := []struct {
+		number string
+		region string
+	}{
+		{"", "ID"},
+		{"+", "ID"},
+		{"12345", "ID"},
+		{"0800 123 456", "ID"},
+		{"0812-3456-7890-1234", "ID"},
+		{"0812 3456 abc", "ID"},
+		{"+62 12 3456", "ID"},
+		{"+62 812 3456 7890 1234", "ID"},
+		{"+0 812 3456 7890", "ID"},
+		{"+1 650 053 0000", "ID"},
+		{"+6412345678901234", "ID"},
+		{"555-0100", "US"},
+		{"1234 5678", "SG"},
+	}
+	for _, test := range invalid {
+		_, err := Normalize(test.number, test.region)
+		assert.Equal(t, ErrInvalid, err, "%q in %s", test.number, test.region)
+	}
+
+	_, err := Normalize("0812 3456 7890", "XX")
+	assert.Equal(t, ErrUnknownRegion, err)
+}
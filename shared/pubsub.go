@@ -2,6 +2,8 @@ package shared
 
 import (
 	"time"
+
+	"github.com/evermos/boilerplate-go/shared/metrics"
 )
 
 type message struct {
@@ -93,6 +95,9 @@ type PubSub struct {
 
 type pubsubConfig struct {
 	MessageBuffer int
+	// QueueName is the queue label of the queue depth gauge, which is only
+	// registered when it is set.
+	QueueName string
 }
 
 func defaultPubsubConfig() pubsubConfig {
@@ -107,6 +112,14 @@ func SetMessageBuffer(maxBuffer int) func(*pubsubConfig) {
 	}
 }
 
+// SetQueueDepthMetric exposes the queue depth of the PubSub as the
+// ums_pubsub_queue_depth gauge labeled with queue, which must be unique.
+func SetQueueDepthMetric(queue string) func(*pubsubConfig) {
+	return func(pc *pubsubConfig) {
+		pc.QueueName = queue
+	}
+}
+
 type TopicRunner struct {
 	Process        Process
 	consumerConfig consumerConfig
@@ -140,12 +153,16 @@ func New(maxFlight int, opts ...func(*pubsubConfig)) PubSub {
 		opt(&config)
 	}
 
-	return PubSub{
+	p := PubSub{
 		message:     make(chan message, config.MessageBuffer),
 		messagePool: make(chan chan message),
 		max:         maxFlight,
 		topics:      make(map[string]TopicRunner),
 	}
+	if config.QueueName != "" {
+		metrics.RegisterQueueDepth(config.QueueName, p)
+	}
+	return p
 }
 
 func (p PubSub) Publish(topic string, payload []byte) {
@@ -155,6 +172,11 @@ func (p PubSub) Publish(topic string, payload []byte) {
 	}
 }
 
+// QueueDepth returns the number of published messages waiting to be dispatched.
+func (p PubSub) QueueDepth() int {
+	return len(p.message)
+}
+
 func (p PubSub) SubscriberRegistry(topicListener string, pr Process, opts ...func(*consumerConfig)) {
 	cfg := defaultConsumerConfig()
 
@@ -2,11 +2,15 @@ package shared_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/evermos/boilerplate-go/shared"
+	"github.com/evermos/boilerplate-go/shared/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPubSub(t *testing.T) {
@@ -80,4 +84,16 @@ func TestPubSub(t *testing.T) {
 		time.Sleep(3 * time.Second)
 		assert.Equal(t, 1000, counter)
 	})
+
+	t.Run("Queue Depth Metric", func(t *testing.T) {
+		pubsub := shared.New(1, shared.SetMessageBuffer(10), shared.SetQueueDepthMetric("test"))
+		pubsub.Publish("test", []byte("a"))
+		pubsub.Publish("test", []byte("b"))
+
+		rec := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body, err := ioutil.ReadAll(rec.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `ums_pubsub_queue_depth{queue="test"} 2`)
+	})
 }
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second}
+
+	t.Run("Rejects Requests Over The Burst", func(t *testing.T) {
+		now := time.Now()
+		limiter := NewMemoryLimiter()
+		limiter.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			result, err := limiter.Allow(context.Background(), "key", limit)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 1-i, result.Remaining)
+		}
+
+		result, err := limiter.Allow(context.Background(), "key", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+		result, err = limiter.Allow(context.Background(), "other", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("Refills Over Time", func(t *testing.T) {
+		now := time.Now()
+		limiter := NewMemoryLimiter()
+		limiter.now = func() time.Time { return now }
+
+		limiter.Allow(context.Background(), "key", limit)
+		limiter.Allow(context.Background(), "key", limit)
+		result, _ := limiter.Allow(context.Background(), "key", limit)
+		assert.False(t, result.Allowed)
+
+		now = now.Add(500 * time.Millisecond)
+		result, _ = limiter.Allow(context.Background(), "key", limit)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit describes how many requests are allowed within a period. Burst is the
+// maximum number of requests that can be made at once and defaults to
+// Requests when not set.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	Allowed bool
+	// Limit is the maximum number of requests in the current window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// ResetAfter is the time until the quota is fully replenished.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the next request is allowed. It is zero
+	// when the request was allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter checks whether a request identified by key is allowed under limit.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
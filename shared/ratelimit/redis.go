@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/go-redis/redis"
+)
+
+const redisKeyPrefix = "ratelimit:"
+
+var errUnexpectedReply = errors.New("ratelimit: unexpected reply from redis")
+
+// slidingWindowScript records a request in a sorted set of request timestamps
+// if fewer than the limit were made within the window. It uses the Redis
+// clock so that all replicas agree on the window.
+var slidingWindowScript = redis.NewScript(`
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, now .. '-' .. member)
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local oldest = now
+local first = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if first[2] then
+	oldest = tonumber(first[2])
+end
+
+return {allowed, count, oldest + window - now}
+`)
+
+// RedisLimiter is a sliding window limiter keeping its state in Redis, so that
+// limits hold across all replicas sharing the same Redis.
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter creates a new Redis-backed sliding window limiter.
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+	}
+}
+
+// Allow records a request for key if fewer than limit.Requests requests were
+// made within the last limit.Period.
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	member, err := randomMember()
+	if err != nil {
+		return Result{}, err
+	}
+
+	client := infras.RedisWithTracing(ctx, l.client)
+	values, err := slidingWindowScript.Run(
+		client,
+		[]string{redisKeyPrefix + key},
+		limit.Period.Milliseconds(),
+		limit.Requests,
+		member,
+	).Result()
+	if err != nil {
+		return Result{}, err
+	}
+
+	reply, ok := values.([]interface{})
+	if !ok || len(reply) != 3 {
+		return Result{}, errUnexpectedReply
+	}
+	allowedFlag, okAllowed := reply[0].(int64)
+	count, okCount := reply[1].(int64)
+	resetMillis, okReset := reply[2].(int64)
+	if !okAllowed || !okCount || !okReset {
+		return Result{}, errUnexpectedReply
+	}
+
+	allowed := allowedFlag == 1
+	resetAfter := time.Duration(resetMillis) * time.Millisecond
+
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit.Requests,
+		Remaining:  limit.Requests - int(count),
+		ResetAfter: resetAfter,
+	}
+	if !allowed {
+		result.RetryAfter = resetAfter
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+
+	return result, nil
+}
+
+func randomMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
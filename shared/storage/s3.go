@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service          = "s3"
+	s3SigningAlgorithm = "AWS4-HMAC-SHA256"
+	s3DefaultRegion    = "us-east-1"
+	s3AmzDateLayout    = "20060102T150405Z"
+	s3DateLayout       = "20060102"
+	s3RequestTimeout   = 30 * time.Second
+)
+
+// S3Config configures an S3Storage.
+type S3Config struct {
+	AccessKeyID string
+	Bucket      string
+	// Endpoint is the URL of the S3 compatible service, such as
+	// https://s3.ap-southeast-1.amazonaws.com or http://localhost:9000 for
+	// MinIO.
+	Endpoint string
+	// PublicURL is the URL objects are served from, such as a CDN. Defaults
+	// to the bucket on the endpoint, which must then allow public reads.
+	PublicURL       string
+	Region          string
+	SecretAccessKey string
+}
+
+// S3Storage keeps objects in a bucket of an S3 compatible object storage. It
+// talks to the service with path-style requests signed with AWS Signature
+// Version 4, which AWS S3 and compatible services such as MinIO support.
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Storage creates a storage keeping objects in the configured bucket.
+func NewS3Storage(config S3Config) *S3Storage {
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+	if config.Region == "" {
+		config.Region = s3DefaultRegion
+	}
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+
+	return &S3Storage{
+		config: config,
+		client: &http.Client{Timeout: s3RequestTimeout},
+		now:    time.Now,
+	}
+}
+
+// Put uploads body as the object of key, replacing it if it exists.
+func (s *S3Storage) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	_, err := s.do(ctx, http.MethodPut, key, body, header)
+	return err
+}
+
+// Get downloads the object of key.
+func (s *S3Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	return s.do(ctx, http.MethodGet, key, nil, http.Header{})
+}
+
+// Delete removes the object of key. Deleting a missing object is not an error.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	_, err := s.do(ctx, http.MethodDelete, key, nil, http.Header{})
+	return err
+}
+
+// URL returns the URL the object of key is served from.
+func (s *S3Storage) URL(key string) string {
+	return s.config.PublicURL + "/" + escapePath(key)
+}
+
+// do sends a signed request for the object of key and returns the response
+// body.
+func (s *S3Storage) do(ctx context.Context, method, key string, body []byte, header http.Header) ([]byte, error) {
+	endpoint := s.config.Endpoint + "/" + escapePath(s.config.Bucket+"/"+key)
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+	req.Header.Set("X-Amz-Date", s.now().UTC().Format(s3AmzDateLayout))
+	s.sign(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// sign adds the Authorization header of AWS Signature Version 4 to req,
+// signing the host and every header set on it. The X-Amz-Date and
+// X-Amz-Content-Sha256 headers must already be set.
+func (s *S3Storage) sign(req *http.Request) {
+	amzDate := req.Header.Get("X-Amz-Date")
+	scope := strings.Join([]string{amzDate[:len(s3DateLayout)], s.config.Region, s3Service, "aws4_request"}, "/")
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		s3SigningAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), amzDate[:len(s3DateLayout)])
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapePath escapes every segment of a slash separated path the way
+// Signature Version 4 expects: everything but unreserved characters.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func escape(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
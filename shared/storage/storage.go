@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/rs/zerolog/log"
+)
+
+// Storage drivers.
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// defaultDir is where the local storage keeps objects when no directory is
+// configured.
+const defaultDir = "uploads"
+
+// ErrNotFound is returned when getting an object that does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidKey is returned for keys that are empty or escape the storage,
+// such as ones containing "..".
+var ErrInvalidKey = errors.New("invalid object key")
+
+// Storage stores objects, such as uploaded photos, under slash separated keys
+// and serves them from public URLs.
+type Storage interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// ProvideStorage returns the storage of public files, such as profile
+// photos, selected by the driver configured in env var.
+func ProvideStorage(config *configs.Config) Storage {
+	storageConfig := config.App.Storage
+	if storageConfig.Local.Dir == "" {
+		storageConfig.Local.Dir = defaultDir
+	}
+	if storageConfig.Local.BaseURL == "" {
+		storageConfig.Local.BaseURL = strings.TrimSuffix(config.App.URL, "/") + "/files"
+	}
+	return New(storageConfig)
+}
+
+// New returns the storage selected by the driver of config.
+func New(config configs.StorageConfig) Storage {
+	var storage Storage
+	switch strings.ToLower(config.Driver) {
+	case DriverS3:
+		storage = NewS3Storage(S3Config{
+			AccessKeyID:     config.S3.AccessKeyID,
+			Bucket:          config.S3.Bucket,
+			Endpoint:        config.S3.Endpoint,
+			PublicURL:       config.S3.PublicURL,
+			Region:          config.S3.Region,
+			SecretAccessKey: config.S3.SecretAccessKey,
+		})
+	case DriverLocal, "":
+		storage = NewLocalStorage(config.Local.Dir, config.Local.BaseURL)
+	default:
+		log.Fatal().Str("driver", config.Driver).Msg("Unsupported storage driver")
+	}
+
+	log.Info().Str("driver", config.Driver).Msg("Storage initialized.")
+	return storage
+}
+
+// validKey reports whether key is a relative slash separated path that stays
+// inside the storage.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Supported span exporters.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const instrumentationName = "github.com/evermos/boilerplate-go"
+
+var provider *sdktrace.TracerProvider
+
+// Init sets up the global tracer provider and W3C trace context propagation
+// as specified in env var. When tracing is disabled spans are still created
+// through the no-op provider, so callers don't need to check.
+func Init(config *configs.Config) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	tracingConfig := config.Server.Tracing
+	if !tracingConfig.Enable {
+		log.Info().Msg("Tracing is disabled.")
+		return nil
+	}
+
+	exporter, err := newExporter(config)
+	if err != nil {
+		return err
+	}
+
+	ratio := tracingConfig.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(config.App.Name),
+			semconv.ServiceVersionKey.String(config.App.Revision),
+			semconv.DeploymentEnvironmentKey.String(config.Server.Env),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Info().
+		Str("exporter", tracingConfig.Exporter).
+		Float64("sampleRatio", ratio).
+		Msg("Tracing enabled.")
+	return nil
+}
+
+func newExporter(config *configs.Config) (sdktrace.SpanExporter, error) {
+	tracingConfig := config.Server.Tracing
+	switch strings.ToLower(tracingConfig.Exporter) {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP, "":
+		opts := []otlptracehttp.Option{}
+		if tracingConfig.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(tracingConfig.Endpoint))
+		}
+		if tracingConfig.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q", tracingConfig.Exporter)
+	}
+}
+
+// Shutdown flushes pending spans and stops the tracer provider.
+func Shutdown(ctx context.Context) error {
+	if provider == nil {
+		return nil
+	}
+	return provider.Shutdown(ctx)
+}
+
+// StartSpan starts a span as a child of the span in ctx, if any.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// RecordError marks the span as failed when err is not nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+)
+
+const (
+	defaultHealthTimeout = 2 * time.Second
+	defaultHealthCache   = 1 * time.Second
+)
+
+// Dependency check statuses.
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// DependencyHealth is the result of checking a single dependency.
+type DependencyHealth struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessReport is the readiness breakdown returned by /health/ready.
+type ReadinessReport struct {
+	Ready        bool                        `json:"ready"`
+	State        string                      `json:"state"`
+	Dependencies map[string]DependencyHealth `json:"dependencies"`
+	CheckedAt    time.Time                   `json:"checkedAt"`
+}
+
+type dependencyCheck func(ctx context.Context) error
+
+// readinessCache keeps the latest dependency breakdown so that frequent
+// probes don't hammer the databases.
+type readinessCache struct {
+	mu        sync.Mutex
+	report    map[string]DependencyHealth
+	checkedAt time.Time
+}
+
+// LivenessCheck reports whether the process is alive. It does not check any
+// dependency, so that a failing database doesn't get the pod restarted.
+// @Summary Liveness Check
+// @Description Liveness Check Endpoint
+// @Tags service
+// @Produce json
+// @Success 200 {object} response.Base
+// @Router /health/live [get]
+func (h *HTTP) LivenessCheck(w http.ResponseWriter, r *http.Request) {
+	response.WithMessage(w, http.StatusOK, "OK")
+}
+
+// ReadinessCheck reports whether the server should receive traffic. It fails
+// while the server is shutting down or when any dependency is unavailable.
+// @Summary Readiness Check
+// @Description Readiness Check Endpoint with a breakdown per dependency
+// @Tags service
+// @Produce json
+// @Success 200 {object} response.Base{data=ReadinessReport}
+// @Failure 503 {object} response.Base{data=ReadinessReport}
+// @Router /health/ready [get]
+func (h *HTTP) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	dependencies, checkedAt := h.checkDependencies()
+
+	state := h.State()
+	ready := state == ServerStateReady
+	for _, dependency := range dependencies {
+		if dependency.Status != HealthStatusUp {
+			ready = false
+		}
+	}
+
+	report := ReadinessReport{
+		Ready:        ready,
+		State:        state.String(),
+		Dependencies: dependencies,
+		CheckedAt:    checkedAt,
+	}
+
+	if !ready {
+		response.WithJSON(w, http.StatusServiceUnavailable, report)
+		return
+	}
+	response.WithJSON(w, http.StatusOK, report)
+}
+
+func (h *HTTP) checkDependencies() (map[string]DependencyHealth, time.Time) {
+	cacheFor := time.Duration(h.Config.Server.Health.CacheMillis) * time.Millisecond
+	if cacheFor <= 0 {
+		cacheFor = defaultHealthCache
+	}
+
+	h.readiness.mu.Lock()
+	defer h.readiness.mu.Unlock()
+
+	if h.readiness.report != nil && time.Since(h.readiness.checkedAt) < cacheFor {
+		return h.readiness.report, h.readiness.checkedAt
+	}
+
+	// The results are shared between probes, so they must not be bound to the
+	// lifetime of the request that happened to trigger the check.
+	h.readiness.report = h.runChecks(context.Background())
+	h.readiness.checkedAt = time.Now()
+	return h.readiness.report, h.readiness.checkedAt
+}
+
+func (h *HTTP) dependencyChecks() map[string]dependencyCheck {
+	checks := map[string]dependencyCheck{
+		"mysql_read": func(ctx context.Context) error {
+			return h.DB.Read.PingContext(ctx)
+		},
+		"mysql_write": func(ctx context.Context) error {
+			return h.DB.Write.PingContext(ctx)
+		},
+		"schema": func(ctx context.Context) error {
+			missing, err := h.DB.MissingSchemaVersions(ctx, infras.SchemaVersion)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("schema versions %v of expected version %d are missing", missing, infras.SchemaVersion)
+			}
+			return nil
+		},
+	}
+
+	if h.Redis != nil {
+		checks["redis"] = func(ctx context.Context) error {
+			return h.Redis.WithContext(ctx).Ping().Err()
+		}
+	}
+
+	return checks
+}
+
+// runChecks runs all dependency checks concurrently, each bounded by the
+// configured timeout.
+func (h *HTTP) runChecks(ctx context.Context) map[string]DependencyHealth {
+	timeout := time.Duration(h.Config.Server.Health.TimeoutMillis) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	checks := h.dependencyChecks()
+	results := make(map[string]DependencyHealth, len(checks))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check dependencyCheck) {
+			defer wg.Done()
+			result := runCheck(ctx, timeout, check)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func runCheck(ctx context.Context, timeout time.Duration, check dependencyCheck) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := DependencyHealth{
+		Status:    HealthStatusUp,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -1,23 +1,32 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/evermos/boilerplate-go/configs"
 	"github.com/evermos/boilerplate-go/docs"
 	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/internal/workers"
 	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/metrics"
+	"github.com/evermos/boilerplate-go/shared/tracing"
+	httpMiddleware "github.com/evermos/boilerplate-go/transport/http/middleware"
 	"github.com/evermos/boilerplate-go/transport/http/response"
 	"github.com/evermos/boilerplate-go/transport/http/router"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/cors"
+	"github.com/go-redis/redis"
 	"github.com/rs/zerolog/log"
 	httpSwagger "github.com/swaggo/http-swagger"
 	// "github.com/swaggo/swag/example/basic/docs"
@@ -38,43 +47,116 @@ const (
 	ServerStateInCleanupPeriod
 )
 
+// String returns the name of the state as reported by the readiness probe.
+func (s ServerState) String() string {
+	switch s {
+	case ServerStateReady:
+		return "ready"
+	case ServerStateInGracePeriod:
+		return "grace_period"
+	case ServerStateInCleanupPeriod:
+		return "cleanup_period"
+	default:
+		return "starting"
+	}
+}
+
+// ShutdownHook is run during shutdown once in-flight requests have been
+// drained, before the database and cache connections are closed. It is meant
+// for stopping background workers.
+type ShutdownHook func(ctx context.Context) error
+
 // HTTP is the HTTP server.
 type HTTP struct {
-	Config *configs.Config
-	DB     *infras.MySQLConn
-	Router router.Router
-	State  ServerState
-	mux    *chi.Mux
+	Config    *configs.Config
+	DB        *infras.MySQLConn
+	Redis     *redis.Client
+	Router    router.Router
+	Workers   workers.Workers
+	mux       *chi.Mux
+	server    *http.Server
+	state     int32
+	hooks     []ShutdownHook
+	stopped   chan struct{}
+	shutdown  int32
+	readiness readinessCache
 }
 
 // ProvideHTTP is the provider for HTTP.
-func ProvideHTTP(db *infras.MySQLConn, config *configs.Config, router router.Router) *HTTP {
+func ProvideHTTP(db *infras.MySQLConn, redis *redis.Client, config *configs.Config, router router.Router, workers workers.Workers) *HTTP {
 	return &HTTP{
-		DB:     db,
-		Config: config,
-		Router: router,
+		DB:      db,
+		Redis:   redis,
+		Config:  config,
+		Router:  router,
+		Workers: workers,
 	}
 }
 
-// SetupAndServe sets up the server and gets it up and running.
+// State returns the current state of the server.
+func (h *HTTP) State() ServerState {
+	return ServerState(atomic.LoadInt32(&h.state))
+}
+
+func (h *HTTP) setState(state ServerState) {
+	atomic.StoreInt32(&h.state, int32(state))
+}
+
+// OnShutdown registers a hook to be run during shutdown. Hooks are run in the
+// order they were registered.
+func (h *HTTP) OnShutdown(hook ShutdownHook) {
+	h.hooks = append(h.hooks, hook)
+}
+
+// SetupAndServe sets up the server and gets it up and running. It returns once
+// the server has been shut down and all connections have been closed.
 func (h *HTTP) SetupAndServe() {
 	h.mux = chi.NewRouter()
 	h.setupMiddleware()
 	h.setupSwaggerDocs()
 	h.setupRoutes()
-	h.setupGracefulShutdown()
-	h.State = ServerStateReady
 
 	h.logServerInfo()
 
+	listener, err := net.Listen("tcp", ":"+h.Config.Server.Port)
+	if err != nil {
+		log.Fatal().Err(err).Str("port", h.Config.Server.Port).Msg("Failed listening on port")
+	}
+
+	h.setupGracefulShutdown()
+	h.startWorkers()
+
 	log.Info().Str("port", h.Config.Server.Port).Msg("Starting up HTTP server.")
 
-	err := http.ListenAndServe(":"+h.Config.Server.Port, h.mux)
-	if err != nil {
+	if err := h.Serve(listener); err != nil {
 		logger.ErrorWithStack(err)
 	}
 }
 
+// Serve accepts connections on the listener until the server is shut down.
+// When the server is shut down through Shutdown, Serve waits for the shutdown
+// to complete and returns nil.
+func (h *HTTP) Serve(listener net.Listener) error {
+	timeoutConfig := h.Config.Server.Timeout
+	h.server = &http.Server{
+		Handler:           h.mux,
+		ReadTimeout:       time.Duration(timeoutConfig.ReadSeconds) * time.Second,
+		ReadHeaderTimeout: time.Duration(timeoutConfig.ReadHeaderSeconds) * time.Second,
+		WriteTimeout:      time.Duration(timeoutConfig.WriteSeconds) * time.Second,
+		IdleTimeout:       time.Duration(timeoutConfig.IdleSeconds) * time.Second,
+	}
+	h.stopped = make(chan struct{})
+	h.setState(ServerStateReady)
+
+	err := h.server.Serve(listener)
+	if err != http.ErrServerClosed {
+		return err
+	}
+
+	<-h.stopped
+	return nil
+}
+
 func (h *HTTP) setupSwaggerDocs() {
 	if h.Config.Server.Env == "development" {
 		docs.SwaggerInfo.Title = h.Config.App.Name
@@ -87,9 +169,38 @@ func (h *HTTP) setupSwaggerDocs() {
 
 func (h *HTTP) setupRoutes() {
 	h.mux.Get("/health", h.HealthCheck)
+	h.mux.Get("/health/live", h.LivenessCheck)
+	h.mux.Get("/health/ready", h.ReadinessCheck)
+	h.setupMetrics()
 	h.Router.SetupRoutes(h.mux)
 }
 
+func (h *HTTP) setupMetrics() {
+	metricsConfig := h.Config.Server.Metrics
+	if !metricsConfig.Enable {
+		return
+	}
+
+	metrics.RegisterDBStats("read", h.DB.Read)
+	metrics.RegisterDBStats("write", h.DB.Write)
+
+	path := metricsConfig.Path
+	if path == "" {
+		path = "/metrics"
+	}
+	h.mux.Method(http.MethodGet, path, metrics.Handler())
+	log.Info().Str("path", path).Msg("Metrics endpoint enabled.")
+}
+
+// startWorkers starts the background workers and registers them to be
+// stopped on shutdown.
+func (h *HTTP) startWorkers() {
+	for _, worker := range h.Workers {
+		worker.Start()
+		h.OnShutdown(worker.Stop)
+	}
+}
+
 func (h *HTTP) setupGracefulShutdown() {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
@@ -98,24 +209,84 @@ func (h *HTTP) setupGracefulShutdown() {
 
 func (h *HTTP) respondToSigterm(done chan os.Signal) {
 	<-done
-	defer os.Exit(0)
+	log.Info().Msg("Received SIGTERM.")
+
+	if err := h.Shutdown(context.Background()); err != nil {
+		logger.ErrorWithStack(err)
+	}
+}
+
+// Shutdown gracefully shuts the server down. It first fails the readiness
+// probe for the grace period so that load balancers stop routing traffic to
+// this instance, then drains in-flight requests within the cleanup period,
+// runs the shutdown hooks and finally closes the MySQL and Redis connections.
+// Only the first call shuts the server down, later ones return an error.
+func (h *HTTP) Shutdown(ctx context.Context) error {
+	if h.server == nil {
+		return errors.New("shutdown: server is not running")
+	}
+	if !atomic.CompareAndSwapInt32(&h.shutdown, 0, 1) {
+		return errors.New("shutdown: server is already shutting down")
+	}
+	defer close(h.stopped)
 
 	shutdownConfig := h.Config.Server.Shutdown
 
-	log.Info().Msg("Received SIGTERM.")
 	log.Info().Int64("seconds", shutdownConfig.GracePeriodSeconds).Msg("Entering grace period.")
-	h.State = ServerStateInGracePeriod
-	time.Sleep(time.Duration(shutdownConfig.GracePeriodSeconds) * time.Second)
+	h.setState(ServerStateInGracePeriod)
+	select {
+	case <-time.After(time.Duration(shutdownConfig.GracePeriodSeconds) * time.Second):
+	case <-ctx.Done():
+	}
 
 	log.Info().Int64("seconds", shutdownConfig.CleanupPeriodSeconds).Msg("Entering cleanup period.")
-	h.State = ServerStateInCleanupPeriod
-	time.Sleep(time.Duration(shutdownConfig.CleanupPeriodSeconds) * time.Second)
+	h.setState(ServerStateInCleanupPeriod)
+
+	drainCtx, cancel := context.WithTimeout(ctx, time.Duration(shutdownConfig.CleanupPeriodSeconds)*time.Second)
+	defer cancel()
+
+	var errs []string
+	if err := h.server.Shutdown(drainCtx); err != nil {
+		log.Warn().Err(err).Msg("In-flight requests were not drained in time, closing connections.")
+		h.server.Close()
+		errs = append(errs, err.Error())
+	}
+
+	for _, hook := range h.hooks {
+		if err := hook(drainCtx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if err := h.DB.Close(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if h.Redis != nil {
+		if err := h.Redis.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if err := tracing.Shutdown(drainCtx); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("shutdown: %s", strings.Join(errs, "; "))
+	}
 
 	log.Info().Msg("Cleaning up completed. Shutting down now.")
+	return nil
 }
 
 func (h *HTTP) setupMiddleware() {
-	h.mux.Use(middleware.Logger)
+	h.mux.Use(httpMiddleware.RequestID)
+	h.mux.Use(httpMiddleware.RequestLogger)
+	h.mux.Use(httpMiddleware.Tracing)
+	if h.Config.Server.Metrics.Enable {
+		h.mux.Use(httpMiddleware.Metrics)
+	}
 	h.mux.Use(middleware.Recoverer)
 	h.mux.Use(h.serverStateMiddleware)
 	h.setupCORS()
@@ -142,7 +313,7 @@ func (h *HTTP) logCORSConfigInfo() {
 
 func (h *HTTP) serverStateMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch h.State {
+		switch h.State() {
 		case ServerStateReady:
 			// Server is ready to serve, don't do anything.
 			next.ServeHTTP(w, r)
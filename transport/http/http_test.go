@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	"github.com/evermos/boilerplate-go/infras"
+	"github.com/evermos/boilerplate-go/transport/http/router"
+	"github.com/go-chi/chi"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	// use MySQL driver
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Run("Drains In-Flight Requests And Closes Connections", func(t *testing.T) {
+		config := &configs.Config{}
+		config.Server.Shutdown.CleanupPeriodSeconds = 5
+
+		// sql.Open doesn't connect, so no database server is needed to check
+		// that the pool gets closed.
+		db, err := sqlx.Open("mysql", "user:password@tcp(127.0.0.1:1)/test")
+		require.NoError(t, err)
+
+		h := ProvideHTTP(&infras.MySQLConn{Read: db, Write: db}, nil, config, router.Router{}, nil)
+		requestStarted := make(chan struct{})
+		h.mux = chi.NewRouter()
+		h.mux.Use(h.serverStateMiddleware)
+		h.mux.Get("/slow", func(w http.ResponseWriter, r *http.Request) {
+			close(requestStarted)
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte("done"))
+		})
+
+		var hookState ServerState
+		h.OnShutdown(func(ctx context.Context) error {
+			hookState = h.State()
+			return nil
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		url := "http://" + listener.Addr().String()
+
+		served := make(chan error, 1)
+		go func() {
+			served <- h.Serve(listener)
+		}()
+
+		type result struct {
+			status int
+			body   string
+			err    error
+		}
+		inFlight := make(chan result, 1)
+		go func() {
+			resp, err := http.Get(url + "/slow")
+			if err != nil {
+				inFlight <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			inFlight <- result{status: resp.StatusCode, body: string(body), err: err}
+		}()
+
+		<-requestStarted
+		assert.Equal(t, ServerStateReady, h.State())
+
+		err = h.Shutdown(context.Background())
+		assert.NoError(t, err)
+		assert.EqualError(t, h.Shutdown(context.Background()), "shutdown: server is already shutting down")
+
+		res := <-inFlight
+		require.NoError(t, res.err)
+		assert.Equal(t, http.StatusOK, res.status)
+		assert.Equal(t, "done", res.body)
+
+		select {
+		case err := <-served:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Serve did not return after shutdown")
+		}
+
+		assert.Equal(t, ServerStateInCleanupPeriod, hookState)
+		assert.EqualError(t, db.Ping(), "sql: database is closed")
+
+		_, err = http.Get(url + "/slow")
+		assert.Error(t, err)
+	})
+
+	t.Run("Not Running", func(t *testing.T) {
+		h := ProvideHTTP(nil, nil, &configs.Config{}, router.Router{}, nil)
+		assert.Error(t, h.Shutdown(context.Background()))
+	})
+}
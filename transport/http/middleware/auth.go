@@ -2,27 +2,42 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/evermos/boilerplate-go/configs"
 	"github.com/evermos/boilerplate-go/infras"
+	context_helpers "github.com/evermos/boilerplate-go/shared/context"
+	"github.com/evermos/boilerplate-go/shared/jwks"
+	"github.com/evermos/boilerplate-go/shared/logger"
 	"github.com/evermos/boilerplate-go/shared/oauth"
 	"github.com/evermos/boilerplate-go/transport/http/response"
 )
 
+// SessionChecker reports whether the login session of a JWT is still active.
+type SessionChecker interface {
+	TouchSession(ctx context.Context, userID, sessionID string) (bool, error)
+}
+
 type Authentication struct {
-	db *infras.MySQLConn
+	db         *infras.MySQLConn
+	keySet     *jwks.KeySet
+	sessions   SessionChecker
+	validation jwks.Validation
 }
 
 const (
 	HeaderAuthorization = "Authorization"
 )
 
-func ProvideAuthentication(db *infras.MySQLConn) *Authentication {
+func ProvideAuthentication(db *infras.MySQLConn, keySet *jwks.KeySet, sessions SessionChecker, config *configs.Config) *Authentication {
 	return &Authentication{
-		db: db,
+		db:         db,
+		keySet:     keySet,
+		sessions:   sessions,
+		validation: jwks.NewValidation(config),
 	}
 }
 
@@ -38,11 +53,11 @@ func (a *Authentication) ClientCredential(next http.Handler) http.Handler {
 		}
 
 		if !parseToken.VerifyExpireIn() {
-			response.WithMessage(w, http.StatusUnauthorized, err.Error())
+			response.WithMessage(w, http.StatusUnauthorized, oauth.ErrorTokenExpired)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withPrincipal(r, oauthPrincipal(parseToken)))
 	})
 }
 
@@ -61,11 +76,11 @@ func (a *Authentication) ClientCredentialWithQueryParameter(next http.Handler) h
 		}
 
 		if !parseToken.VerifyExpireIn() {
-			response.WithMessage(w, http.StatusUnauthorized, err.Error())
+			response.WithMessage(w, http.StatusUnauthorized, oauth.ErrorTokenExpired)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withPrincipal(r, oauthPrincipal(parseToken)))
 	})
 }
 
@@ -81,7 +96,7 @@ func (a *Authentication) Password(next http.Handler) http.Handler {
 		}
 
 		if !parseToken.VerifyExpireIn() {
-			response.WithMessage(w, http.StatusUnauthorized, err.Error())
+			response.WithMessage(w, http.StatusUnauthorized, oauth.ErrorTokenExpired)
 			return
 		}
 
@@ -90,6 +105,72 @@ func (a *Authentication) Password(next http.Handler) http.Handler {
 			return
 		}
 
+		next.ServeHTTP(w, withPrincipal(r, oauthPrincipal(parseToken)))
+	})
+}
+
+// VerifyToken authenticates requests with either a JWT from the login
+// endpoint or an OAuth access token from /oauth/token, told apart by their
+// format, so that routes guarded by RequireScope accept both. OAuth tokens
+// issued to a user act with the role of the user, who must still be active.
+func (a *Authentication) VerifyToken(next http.Handler) http.Handler {
+	verifyJWT := a.VerifyJWT(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get(HeaderAuthorization)
+		if isJWT(strings.TrimPrefix(authHeader, "Bearer ")) {
+			verifyJWT.ServeHTTP(w, r)
+			return
+		}
+
+		token := oauth.New(a.db.Read, oauth.Config{})
+		parseToken, err := token.ParseWithAccessToken(authHeader)
+		if err != nil {
+			response.WithMessage(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if !parseToken.VerifyExpireIn() {
+			response.WithMessage(w, http.StatusUnauthorized, oauth.ErrorTokenExpired)
+			return
+		}
+
+		principal := oauthPrincipal(parseToken)
+		if parseToken.VerifyUserLoggedIn() {
+			user, err := token.ResolveUser(parseToken.UserID.String)
+			if err != nil {
+				response.WithMessage(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			principal.Username = user.Username
+			principal.Roles = []string{user.Role}
+		}
+
+		next.ServeHTTP(w, withPrincipal(r, principal))
+	})
+}
+
+// isJWT reports whether a bearer token is a JWT, made of three dot separated
+// parts, rather than an opaque OAuth access token.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// RequireUser only lets requests through that act for a user, rejecting OAuth
+// clients acting on their own with 403. It must be used after the middleware
+// authenticating the token.
+func (a *Authentication) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := context_helpers.GetPrincipal(r)
+		if err != nil {
+			response.WithMessage(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		if principal.ID == "" {
+			response.WithMessage(w, http.StatusForbidden, "This endpoint requires a user token")
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -101,57 +182,46 @@ func (a *Authentication) VerifyJWT(next http.Handler) http.Handler {
 		authHeader := r.Header.Get(HeaderAuthorization)
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 
-		// Parse and validate the JWT token
-		config := configs.Get()
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Check the signing method
-			if token.Method.Alg() != jwt.SigningMethodHS256.Name {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(config.App.JWTAccessKey), nil
-		})
+		// Verify the signature with the key named by its kid and validate
+		// the claims
+		claims, err := a.keySet.ParseClaims(tokenString, a.validation)
 		if err != nil {
+			logger.FromContext(r.Context()).Debug().Err(err).Msg("Rejected JWT")
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
-		// Check if the token is valid
-		if _, ok := token.Claims.(jwt.MapClaims); !ok || !token.Valid {
-			http.Error(w, "Token invalid", http.StatusUnauthorized)
-			return
-		}
-
-		// Extract user information from token claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		if claims.Username == "" || claims.Role == "" {
 			http.Error(w, "No user information from JWT", http.StatusUnauthorized)
 			return
 		}
 
-		userID, ok := claims["user_id"].(string)
-		if !ok {
-			http.Error(w, "JWT user_id is not found", http.StatusUnauthorized)
+		// Reject tokens whose session was revoked
+		if claims.SessionID == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
-
-		username, ok := claims["username"].(string)
-		if !ok {
-			http.Error(w, "JWT username is not found", http.StatusUnauthorized)
+		active, err := a.sessions.TouchSession(r.Context(), claims.Subject, claims.SessionID)
+		if err != nil {
+			http.Error(w, "Failed to check session", http.StatusInternalServerError)
 			return
 		}
-
-		role, ok := claims["role"].(string)
-		if !ok {
-			http.Error(w, "JWT role is not found", http.StatusUnauthorized)
+		if !active {
+			logger.FromContext(r.Context()).Debug().Str("session_id", claims.SessionID).Msg("Rejected JWT of revoked session")
+			http.Error(w, "Session revoked", http.StatusUnauthorized)
 			return
 		}
 
-		// Add user information to the request context
-		ctx := context.WithValue(r.Context(), "user_id", userID)
-		ctx = context.WithValue(ctx, "username", username)
-		ctx = context.WithValue(ctx, "role", role)
-		ctx = context.WithValue(ctx, "token", tokenString)
-		r = r.WithContext(ctx)
+		principal := &context_helpers.Principal{
+			ID:          claims.Subject,
+			Username:    claims.Username,
+			Roles:       []string{claims.Role},
+			Permissions: oauth.ParseScope(claims.Scope),
+			AuthMethod:  context_helpers.AuthMethodJWT,
+			TokenID:     claims.ID,
+			SessionID:   claims.SessionID,
+		}
+		r = withPrincipal(r, principal)
 
 		// Call the next handler in the chain
 		next.ServeHTTP(w, r)
@@ -160,17 +230,84 @@ func (a *Authentication) VerifyJWT(next http.Handler) http.Handler {
 
 func (a *Authentication) IsAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		role, ok := r.Context().Value("role").(string)
-		if !ok {
+		principal, err := context_helpers.GetPrincipal(r)
+		if err != nil {
 			http.Error(w, "Role not found in context", http.StatusUnauthorized)
 			return
 		}
 
+		// OAuth clients acting on their own have no role: they are only
+		// authorized by their scopes, which RequireScope checks. They are
+		// only granted the admin scopes when flagged as admin.
+		if principal.AuthMethod == context_helpers.AuthMethodOAuthClient {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// check role if admin or not (case insencitive)
-		if strings.EqualFold(role, "admin") {
+		if principal.HasRole("admin") {
 			next.ServeHTTP(w, r)
 		} else {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 		}
 	})
 }
+
+// RequireScope only lets requests through whose token, an OAuth access token
+// or a JWT, was granted all of scopes. It must be used after the middleware
+// authenticating the token. Other requests get 403 insufficient_scope.
+func (a *Authentication) RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	challenge := fmt.Sprintf(`Bearer error="%s", scope="%s"`, oauth.ErrCodeInsufficientScope, oauth.FormatScope(scopes))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := context_helpers.GetPrincipal(r)
+			if err != nil {
+				response.WithMessage(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			if !principal.HasPermissions(scopes...) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(oauth.NewError(oauth.ErrCodeInsufficientScope, oauth.ErrorInsufficientScope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// oauthPrincipal returns the principal of a request authenticated with an
+// OAuth access token.
+func oauthPrincipal(accessToken oauth.OauthAccessToken) *context_helpers.Principal {
+	principal := &context_helpers.Principal{
+		ID:          accessToken.UserID.String,
+		Permissions: oauth.ParseScope(accessToken.Scope.String),
+		AuthMethod:  context_helpers.AuthMethodOAuthClient,
+		TokenID:     oauth.HashToken(accessToken.AccessToken),
+		ClientID:    accessToken.ClientID,
+	}
+	if accessToken.UserID.Valid {
+		principal.AuthMethod = context_helpers.AuthMethodOAuthUser
+	} else {
+		// Clients acting on their own are named by client ID in audit fields.
+		principal.Username = "client:" + accessToken.ClientID
+	}
+	return principal
+}
+
+// withPrincipal returns r with the principal in its context and adds the
+// principal to the request logger.
+func withPrincipal(r *http.Request, principal *context_helpers.Principal) *http.Request {
+	ctx := context_helpers.WithPrincipal(r.Context(), principal)
+
+	var role string
+	if len(principal.Roles) > 0 {
+		role = principal.Roles[0]
+	}
+	logger.AddUser(ctx, principal.ID, role)
+
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/evermos/boilerplate-go/shared/metrics"
+	"github.com/go-chi/chi/middleware"
+)
+
+// Metrics records the count, status and latency of each request, labelled by
+// the matched chi route pattern instead of the raw path.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		metrics.ObserveHTTPRequest(routePattern(r), r.Method, responseStatus(ww), time.Since(start))
+	})
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evermos/boilerplate-go/configs"
+	context_helpers "github.com/evermos/boilerplate-go/shared/context"
+	"github.com/evermos/boilerplate-go/shared/logger"
+	"github.com/evermos/boilerplate-go/shared/ratelimit"
+	"github.com/evermos/boilerplate-go/transport/http/response"
+	"github.com/go-redis/redis"
+	"github.com/rs/zerolog/log"
+)
+
+// Rate limit response headers.
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// Rate limit backends.
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+)
+
+// What requests of a rate limit policy are counted by.
+const (
+	RateLimitKeyByIP    = "ip"
+	RateLimitKeyByUser  = "user"
+	RateLimitKeyByRoute = "route"
+)
+
+// RateLimiter limits request rates per route group according to the policies
+// configured in env var.
+type RateLimiter struct {
+	config  *configs.Config
+	limiter ratelimit.Limiter
+}
+
+// ProvideRateLimiter is the provider for RateLimiter. The Redis backend
+// requires Redis to be configured.
+func ProvideRateLimiter(config *configs.Config, redis *redis.Client) *RateLimiter {
+	rateLimitConfig := config.Server.RateLimit
+	limiter := &RateLimiter{config: config}
+	if !rateLimitConfig.Enable {
+		log.Info().Msg("Rate limiting is disabled.")
+		return limiter
+	}
+
+	switch strings.ToLower(rateLimitConfig.Backend) {
+	case RateLimitBackendRedis:
+		if redis == nil {
+			log.Fatal().Msg("Rate limiting with the Redis backend requires Redis to be configured")
+		}
+		limiter.limiter = ratelimit.NewRedisLimiter(redis)
+	case RateLimitBackendMemory, "":
+		limiter.limiter = ratelimit.NewMemoryLimiter()
+	default:
+		log.Fatal().Str("backend", rateLimitConfig.Backend).Msg("Unsupported rate limit backend")
+	}
+
+	log.Info().Str("backend", rateLimitConfig.Backend).Msg("Rate limiting is enabled.")
+	return limiter
+}
+
+// Default limits requests with the default policy.
+func (l *RateLimiter) Default(next http.Handler) http.Handler {
+	return l.Limit("default", l.config.Server.RateLimit.Default)(next)
+}
+
+// Login limits requests with the login policy.
+func (l *RateLimiter) Login(next http.Handler) http.Handler {
+	return l.Limit("login", l.config.Server.RateLimit.Login)(next)
+}
+
+// LoginGroup limits requests of the unauthenticated route group name, such as
+// sign-up or MFA verification, with the login policy. Each group is counted
+// apart from the others and from logins, so that a login followed by its MFA
+// verification uses one request of each and sign-ups do not starve logins.
+func (l *RateLimiter) LoginGroup(name string) func(http.Handler) http.Handler {
+	return l.Limit("login:"+name, l.config.Server.RateLimit.Login)
+}
+
+// Register limits requests with the register policy.
+func (l *RateLimiter) Register(next http.Handler) http.Handler {
+	return l.Limit("register", l.config.Server.RateLimit.Register)(next)
+}
+
+// Limit returns a middleware limiting requests of the route group name with
+// policy. Policies keyed by user must be used after an authentication
+// middleware, and count requests of OAuth clients per client. Requests are
+// let through when the limiter backend fails.
+func (l *RateLimiter) Limit(name string, policy configs.RateLimitPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if l.limiter == nil || policy.Requests <= 0 || policy.PeriodSeconds <= 0 {
+			return next
+		}
+
+		limit := ratelimit.Limit{
+			Requests: policy.Requests,
+			Period:   time.Duration(policy.PeriodSeconds) * time.Second,
+			Burst:    policy.Burst,
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := name + ":" + l.subject(r, policy.KeyBy)
+			result, err := l.limiter.Allow(r.Context(), key, limit)
+			if err != nil {
+				logger.FromContext(r.Context()).Error().Err(err).Str("policy", name).Msg("Failed to check rate limit")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			w.Header().Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+			if !result.Allowed {
+				w.Header().Set(HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				response.WithMessage(w, http.StatusTooManyRequests, "Too many requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (l *RateLimiter) subject(r *http.Request, keyBy string) string {
+	switch strings.ToLower(keyBy) {
+	case RateLimitKeyByRoute:
+		return "all"
+	case RateLimitKeyByUser:
+		if principal, ok := context_helpers.PrincipalFrom(r.Context()); ok {
+			if principal.ID != "" {
+				return "user:" + principal.ID
+			}
+			if principal.ClientID != "" {
+				return "client:" + principal.ClientID
+			}
+		}
+	}
+	return "ip:" + l.ClientIP(r)
+}
+
+// ClientIP returns the IP of the client, trusting X-Forwarded-For and
+// X-Real-IP only when the server is configured to be behind a proxy.
+func (l *RateLimiter) ClientIP(r *http.Request) string {
+	if l.config.Server.RateLimit.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return realIP
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+)
+
+// unmatchedRoute labels requests that did not match any registered route.
+const unmatchedRoute = "unmatched"
+
+// routePattern returns the chi route pattern matched by a served request, e.g.
+// /v1/users/{uuid}, so that observability labels stay low-cardinality.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		return rctx.RoutePattern()
+	}
+	return unmatchedRoute
+}
+
+// responseStatus returns the status written by the handler, defaulting to 200
+// when the handler wrote a body without calling WriteHeader.
+func responseStatus(ww middleware.WrapResponseWriter) int {
+	if status := ww.Status(); status != 0 {
+		return status
+	}
+	return http.StatusOK
+}
@@ -7,8 +7,14 @@ import (
 
 // DomainHandlers is a struct that contains all domain-specific handlers.
 type DomainHandlers struct {
-	AuthHandler handlers.AuthHandler
-	UserHandler handlers.UserHandler
+	AuthHandler        handlers.AuthHandler
+	DocumentHandler    handlers.DocumentHandler
+	FilesHandler       handlers.FilesHandler
+	JWKSHandler        handlers.JWKSHandler
+	LocationHandler    handlers.LocationHandler
+	OAuthHandler       handlers.OAuthHandler
+	OAuthClientHandler handlers.OAuthClientHandler
+	UserHandler        handlers.UserHandler
 }
 
 // Router is the router struct containing handlers.
@@ -25,8 +31,14 @@ func ProvideRouter(domainHandlers DomainHandlers) Router {
 
 // SetupRoutes sets up all routing for this server.
 func (r *Router) SetupRoutes(mux *chi.Mux) {
+	r.DomainHandlers.FilesHandler.Router(mux)
+	r.DomainHandlers.JWKSHandler.Router(mux)
+	r.DomainHandlers.OAuthHandler.Router(mux)
 	mux.Route("/v1", func(rc chi.Router) {
 		r.DomainHandlers.AuthHandler.Router(rc)
+		r.DomainHandlers.DocumentHandler.Router(rc)
+		r.DomainHandlers.LocationHandler.Router(rc)
+		r.DomainHandlers.OAuthClientHandler.Router(rc)
 		r.DomainHandlers.UserHandler.Router(rc)
 	})
 }
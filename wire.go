@@ -7,8 +7,14 @@ import (
 	"github.com/evermos/boilerplate-go/configs"
 	"github.com/evermos/boilerplate-go/infras"
 	"github.com/evermos/boilerplate-go/internal/domain/auth"
+	"github.com/evermos/boilerplate-go/internal/domain/documents"
+	"github.com/evermos/boilerplate-go/internal/domain/locations"
 	"github.com/evermos/boilerplate-go/internal/domain/users"
 	"github.com/evermos/boilerplate-go/internal/handlers"
+	"github.com/evermos/boilerplate-go/internal/workers"
+	"github.com/evermos/boilerplate-go/shared/jwks"
+	"github.com/evermos/boilerplate-go/shared/mailer"
+	"github.com/evermos/boilerplate-go/shared/storage"
 	"github.com/evermos/boilerplate-go/transport/http"
 	"github.com/evermos/boilerplate-go/transport/http/middleware"
 	"github.com/evermos/boilerplate-go/transport/http/router"
@@ -23,6 +29,7 @@ var configurations = wire.NewSet(
 // Wiring for persistences.
 var persistences = wire.NewSet(
 	infras.ProvideMySQLConn,
+	infras.ProvideRedisClient,
 )
 
 // Wiring for domain.
@@ -42,26 +49,74 @@ var domainUser = wire.NewSet(
 	// UserRepository interface and implementation
 	users.ProvideUserRepositoryMySQL,
 	wire.Bind(new(users.UserRepository), new(*users.UserRepositoryMySQL)),
+	wire.Bind(new(users.LocationResolver), new(*locations.LocationServiceImpl)),
+)
+
+var domainDocument = wire.NewSet(
+	// DocumentService interface and implementation
+	documents.ProvideDocumentServiceImpl,
+	wire.Bind(new(documents.DocumentService), new(*documents.DocumentServiceImpl)),
+	// DocumentRepository interface and implementation
+	documents.ProvideDocumentRepositoryMySQL,
+	wire.Bind(new(documents.DocumentRepository), new(*documents.DocumentRepositoryMySQL)),
+	documents.ProvideDocumentStorage,
+)
+
+var domainLocation = wire.NewSet(
+	// LocationService interface and implementation
+	locations.ProvideLocationServiceImpl,
+	wire.Bind(new(locations.LocationService), new(*locations.LocationServiceImpl)),
+	// LocationRepository interface and implementation
+	locations.ProvideLocationRepositoryMySQL,
+	wire.Bind(new(locations.LocationRepository), new(*locations.LocationRepositoryMySQL)),
 )
 
 // Wiring for all domains.
 var domains = wire.NewSet(
 	domainAuth,
 	domainUser,
+	domainDocument,
+	domainLocation,
+)
+
+// Wiring for notifications.
+var notifications = wire.NewSet(
+	mailer.ProvideMailer,
+)
+
+// Wiring for object storage.
+var objectStorage = wire.NewSet(
+	storage.ProvideStorage,
 )
 
 var authMiddleware = wire.NewSet(
+	jwks.ProvideKeySet,
 	middleware.ProvideAuthentication,
+	wire.Bind(new(middleware.SessionChecker), new(*auth.AuthRepositoryMySQL)),
+	middleware.ProvideRateLimiter,
 )
 
 // Wiring for HTTP routing.
 var routing = wire.NewSet(
-	wire.Struct(new(router.DomainHandlers), "AuthHandler", "UserHandler"),
+	wire.Struct(new(router.DomainHandlers), "AuthHandler", "DocumentHandler", "FilesHandler", "JWKSHandler", "LocationHandler", "OAuthHandler", "OAuthClientHandler", "UserHandler"),
 	handlers.ProvideAuthHandler,
+	handlers.ProvideDocumentHandler,
+	handlers.ProvideFilesHandler,
+	handlers.ProvideJWKSHandler,
+	handlers.ProvideLocationHandler,
+	handlers.ProvideOAuthHandler,
+	handlers.ProvideOAuthClientHandler,
 	handlers.ProvideUserHandler,
 	router.ProvideRouter,
 )
 
+// Wiring for background workers.
+var backgroundWorkers = wire.NewSet(
+	workers.ProvideTokenCleanupJob,
+	workers.ProvideAccountExpiryJob,
+	workers.ProvideWorkers,
+)
+
 // Wiring for everything.
 func InitializeService() *http.HTTP {
 	wire.Build(
@@ -69,12 +124,18 @@ func InitializeService() *http.HTTP {
 		configurations,
 		// persistences
 		persistences,
+		// notifications
+		notifications,
+		// object storage
+		objectStorage,
 		// middleware
 		authMiddleware,
 		// domains
 		domains,
 		// routing
 		routing,
+		// background workers
+		backgroundWorkers,
 		// selected transport layer
 		http.ProvideHTTP)
 	return &http.HTTP{}